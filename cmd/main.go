@@ -6,6 +6,8 @@ import (
 	"log"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -16,12 +18,18 @@ import (
 
 	_ "github.com/yantology/simple-pos/docs"
 	"github.com/yantology/simple-pos/middleware"
+	"github.com/yantology/simple-pos/pkg/idempotency"
 	"github.com/yantology/simple-pos/pkg/jwt"
+	"github.com/yantology/simple-pos/pkg/money"
 	"github.com/yantology/simple-pos/pkg/resendutils"
 	"github.com/yantology/simple-pos/routes/auth"
 	"github.com/yantology/simple-pos/routes/category"
+	"github.com/yantology/simple-pos/routes/modifier"
 	"github.com/yantology/simple-pos/routes/order"
 	"github.com/yantology/simple-pos/routes/product"
+	"github.com/yantology/simple-pos/routes/promotion"
+	"github.com/yantology/simple-pos/routes/report"
+	"github.com/yantology/simple-pos/routes/setting"
 )
 
 // initMigrations initializes and runs database migrations
@@ -109,6 +117,18 @@ func main() {
 	// Initialize Auth middleware
 	authMiddleware := middleware.NewAuthMiddleware(jwtService, tokenConfig)
 
+	// Initialize Idempotency middleware
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotency.NewPostgresStore(db))
+
+	// Let binding rules such as gt=0 check money amounts in minor units, and
+	// reject amounts in a currency other than the store's
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterCustomTypeFunc(money.ValidateAmount, money.Money{})
+		if err := v.RegisterValidation(money.BindingTag, func(validator.FieldLevel) bool { return true }); err != nil {
+			log.Fatal("Failed to register money validation:", err)
+		}
+	}
+
 	// Initialize Gin router with CORS configuration
 	router := gin.Default()
 	router.Use(config.CorsConfig())
@@ -127,6 +147,8 @@ func main() {
 
 		authGroup := v1
 		authGroup.Use(authMiddleware.AuthRequired())
+		// Retries with the same Idempotency-Key get the original response
+		authGroup.Use(idempotencyMiddleware.Handle())
 
 		// Category routes (protected by auth middleware)
 		categoryPostgres := category.NewPostgresRepository(db)           // Corrected: NewPostgresRepository
@@ -136,8 +158,8 @@ func main() {
 		categoryHandler.RegisterRoutes(categoryGroup)
 
 		// Product routes (protected by auth middleware)
-		productPostgres := product.NewPostgresRepository(db)  // Corrected: NewPostgresRepository
-		productRepo := product.NewRepository(productPostgres) // Corrected: NewRepository
+		productPostgres := product.NewPostgresRepository(db, categoryRepo) // Corrected: NewPostgresRepository
+		productRepo := product.NewRepository(productPostgres)              // Corrected: NewRepository
 		productHandler := product.NewHandler(productRepo)
 		productGroup := authGroup.Group("/products")
 		productHandler.RegisterRoutes(productGroup)
@@ -145,10 +167,45 @@ func main() {
 		// Order routes (protected by auth middleware)
 		orderPostgres := order.NewPostgresRepository(db)     // Corrected: NewPostgresRepository
 		orderRepo := order.NewOrderRepository(orderPostgres) // Corrected: NewOrderRepository
-		orderHandler := order.NewOrderHandler(orderRepo)
+		orderHandler := order.NewOrderHandler(orderRepo, emailSender, order.NewEmailTemplate())
 		orderGroup := authGroup.Group("/orders")
 		orderHandler.RegisterRoutes(orderGroup)
-
+		syncGroup := authGroup.Group("/sync")
+		orderHandler.RegisterSyncRoutes(syncGroup)
+
+		// Promotion routes (protected by auth middleware)
+		promotionPostgres := promotion.NewPostgresRepository(db)
+		promotionRepo := promotion.NewRepository(promotionPostgres)
+		promotionHandler := promotion.NewHandler(promotionRepo)
+		promotionGroup := authGroup.Group("/promotions")
+		promotionHandler.RegisterRoutes(promotionGroup)
+
+		// Modifier list routes (protected by auth middleware)
+		modifierPostgres := modifier.NewPostgresRepository(db)
+		modifierRepo := modifier.NewRepository(modifierPostgres)
+		modifierHandler := modifier.NewHandler(modifierRepo)
+		modifierGroup := authGroup.Group("/modifier-lists")
+		modifierHandler.RegisterRoutes(modifierGroup)
+
+		// Store settings routes (protected by auth middleware)
+		settingPostgres := setting.NewPostgresRepository(db)
+		settingRepo := setting.NewRepository(settingPostgres)
+		settingHandler := setting.NewHandler(settingRepo)
+		settingGroup := authGroup.Group("/settings")
+		settingHandler.RegisterRoutes(settingGroup)
+
+		// Report routes (protected by auth middleware)
+		reportPostgres := report.NewPostgresRepository(db)
+		reportRepo := report.NewRepository(reportPostgres)
+		reportHandler := report.NewHandler(reportRepo)
+		reportGroup := authGroup.Group("/reports")
+		reportHandler.RegisterRoutes(reportGroup)
+
+		// Spreadsheet exports (protected by auth middleware)
+		exportGroup := authGroup.Group("/exports")
+		orderHandler.RegisterExportRoutes(exportGroup)
+		productHandler.RegisterExportRoutes(exportGroup)
+		categoryHandler.RegisterExportRoutes(exportGroup)
 	}
 
 	// Swagger documentation endpoint
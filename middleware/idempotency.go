@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/idempotency"
+)
+
+// IdempotencyMiddleware replays stored responses of mutating requests retried
+// with the same Idempotency-Key header
+type IdempotencyMiddleware struct {
+	store idempotency.Store
+}
+
+// NewIdempotencyMiddleware creates a new instance of idempotency middleware
+func NewIdempotencyMiddleware(store idempotency.Store) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{store: store}
+}
+
+// responseRecorder keeps a copy of the response body written by the handler
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Handle processes POST, PUT, PATCH and DELETE requests carrying an
+// Idempotency-Key header at most once per user and key. A retry with the same
+// body gets the original response; a retry with a different body gets 422.
+// It must run after AuthRequired so the key is scoped to the user. Bodies
+// larger than idempotency.MaxBodySize get 413.
+func (m *IdempotencyMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(idempotency.Header))
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > idempotency.MaxKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"message": fmt.Sprintf("Idempotency-Key must be at most %d characters", idempotency.MaxKeyLength),
+			})
+			c.Abort()
+			return
+		}
+
+		userIDVal, exists := c.Get("user_id")
+		if !exists {
+			c.Next()
+			return
+		}
+		userID, err := strconv.Atoi(userIDVal.(string))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Internal Server Error: User ID in context is not an integer"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, idempotency.MaxBodySize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"message": fmt.Sprintf("Request body must be at most %d MB", idempotency.MaxBodySize>>20),
+			})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		record, customErr := m.store.Claim(userID, key, hash)
+		if customErr != nil {
+			c.JSON(customErr.Code(), gin.H{"message": customErr.Message()})
+			c.Abort()
+			return
+		}
+		if record != nil {
+			switch {
+			case record.RequestHash != hash:
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"message": "Idempotency-Key was already used with a different request",
+				})
+			case record.InProgress():
+				c.JSON(http.StatusConflict, gin.H{
+					"message": "A request with this Idempotency-Key is still being processed",
+				})
+			default:
+				c.Header(idempotency.ReplayedHeader, "true")
+				c.Data(record.StatusCode, record.ContentType, record.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Server errors are not remembered so the client can retry them
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			if customErr := m.store.Release(userID, key); customErr != nil {
+				fmt.Printf("IdempotencyMiddleware: Error releasing key: %s\n", customErr.Message()) // Add log
+			}
+			return
+		}
+		if customErr := m.store.Complete(userID, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); customErr != nil {
+			fmt.Printf("IdempotencyMiddleware: Error storing response: %s\n", customErr.Message()) // Add log
+		}
+	}
+}
+
+// isMutating reports whether requests with this method change data
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+ALTER TABLE orders ADD COLUMN product JSONB NOT NULL DEFAULT '[]'::JSONB;
+
+-- Rebuild the JSONB snapshots from order_items before dropping the table
+UPDATE orders o
+SET product = items.product
+FROM (
+    SELECT
+        order_id,
+        jsonb_agg(
+            jsonb_build_object(
+                'id', COALESCE(product_id, 0),
+                'name', name,
+                'quantity', quantity,
+                'price', price,
+                'category', category,
+                'total_price', total_price
+            ) ORDER BY id
+        ) AS product
+    FROM order_items
+    GROUP BY order_id
+) items
+WHERE items.order_id = o.id;
+
+ALTER TABLE orders ALTER COLUMN product DROP DEFAULT;
+
+DROP TABLE IF EXISTS order_items;
@@ -0,0 +1,45 @@
+CREATE TABLE order_items (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    product_id INTEGER, -- Nullable so sales history survives product deletion
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(255) NOT NULL DEFAULT '',
+    price INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    total_price INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_items_order_id ON order_items(order_id);
+CREATE INDEX idx_order_items_product_id ON order_items(product_id);
+
+-- Backfill order_items from the JSONB snapshots stored on each order.
+-- Older rows may hold a single object instead of an array, and product ids
+-- that no longer exist (or were never numeric) are kept as NULL.
+INSERT INTO order_items (order_id, product_id, name, category, price, quantity, total_price, created_at)
+SELECT
+    o.id,
+    p.id,
+    COALESCE(elem.item->>'name', ''),
+    COALESCE(elem.item->>'category', ''),
+    ROUND(COALESCE((elem.item->>'price')::NUMERIC, 0))::INTEGER,
+    GREATEST(ROUND(COALESCE((elem.item->>'quantity')::NUMERIC, 1))::INTEGER, 1),
+    ROUND(COALESCE(
+        (elem.item->>'total_price')::NUMERIC,
+        COALESCE((elem.item->>'price')::NUMERIC, 0) * COALESCE((elem.item->>'quantity')::NUMERIC, 1)
+    ))::INTEGER,
+    o.created_at
+FROM orders o
+CROSS JOIN LATERAL jsonb_array_elements(
+    CASE jsonb_typeof(o.product) WHEN 'array' THEN o.product ELSE jsonb_build_array(o.product) END
+) WITH ORDINALITY AS elem(item, position)
+LEFT JOIN products p
+    ON elem.item->>'id' ~ '^[0-9]+$'
+    AND p.id = (elem.item->>'id')::INTEGER
+    AND p.user_id = o.user_id
+WHERE jsonb_typeof(elem.item) = 'object'
+ORDER BY o.id, elem.position;
+
+ALTER TABLE orders DROP COLUMN product;
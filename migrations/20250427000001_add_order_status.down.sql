@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_status_events;
+DROP INDEX IF EXISTS idx_orders_user_id_status;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
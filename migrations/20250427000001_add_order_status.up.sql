@@ -0,0 +1,22 @@
+-- Orders created before the lifecycle existed were already completed sales
+ALTER TABLE orders ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'paid';
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'open';
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('open', 'paid', 'voided', 'partially_refunded', 'refunded'));
+
+CREATE INDEX idx_orders_user_id_status ON orders(user_id, status);
+
+-- Audit trail of every status change: who did it, when and why
+CREATE TABLE order_status_events (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    from_status VARCHAR(32) NOT NULL,
+    to_status VARCHAR(32) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    performed_by INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (performed_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_order_status_events_order_id ON order_status_events(order_id);
@@ -0,0 +1,15 @@
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    method VARCHAR(32) NOT NULL CHECK (method IN ('cash', 'card', 'qris', 'e_wallet', 'store_credit')),
+    amount INTEGER NOT NULL CHECK (amount > 0), -- Amount applied to the order
+    tendered INTEGER NOT NULL CHECK (tendered >= amount), -- Amount handed over by the customer
+    change INTEGER NOT NULL DEFAULT 0 CHECK (change >= 0),
+    reference VARCHAR(255) NOT NULL DEFAULT '', -- Card approval code, QRIS reference, etc.
+    received_by INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (received_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_payments_order_id ON payments(order_id);
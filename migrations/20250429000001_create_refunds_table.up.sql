@@ -0,0 +1,32 @@
+ALTER TABLE orders ADD COLUMN refunded_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Orders refunded before line-level refunds existed were refunded in full
+UPDATE orders SET refunded_amount = ROUND(total)::INTEGER WHERE status = 'refunded';
+
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    method VARCHAR(32) NOT NULL CHECK (method IN ('cash', 'card', 'qris', 'e_wallet', 'store_credit')),
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    refunded_by INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (refunded_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+
+CREATE TABLE refund_items (
+    id SERIAL PRIMARY KEY,
+    refund_id INTEGER NOT NULL,
+    order_item_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    amount INTEGER NOT NULL,
+    FOREIGN KEY (refund_id) REFERENCES refunds(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refund_items_refund_id ON refund_items(refund_id);
+CREATE INDEX idx_refund_items_order_item_id ON refund_items(order_item_id);
@@ -0,0 +1,9 @@
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_amount_check;
+ALTER TABLE refunds ADD CONSTRAINT refunds_amount_check CHECK (amount > 0) NOT VALID;
+DROP TABLE IF EXISTS order_discounts;
+ALTER TABLE order_items DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS category_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_total;
+ALTER TABLE orders DROP COLUMN IF EXISTS subtotal;
+DROP TRIGGER IF EXISTS update_promotions_updated_at ON promotions;
+DROP TABLE IF EXISTS promotions;
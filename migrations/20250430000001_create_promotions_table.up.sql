@@ -0,0 +1,61 @@
+CREATE TABLE promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(32) NOT NULL CHECK (type IN ('percentage', 'fixed', 'buy_x_get_y')),
+    scope VARCHAR(32) NOT NULL CHECK (scope IN ('order', 'category', 'product')),
+    percent NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    amount INTEGER NOT NULL DEFAULT 0,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    get_quantity INTEGER NOT NULL DEFAULT 0,
+    min_spend INTEGER NOT NULL DEFAULT 0,
+    product_ids INTEGER[] NOT NULL DEFAULT '{}',
+    category_ids INTEGER[] NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    days_of_week SMALLINT[] NOT NULL DEFAULT '{}', -- 0 = Sunday ... 6 = Saturday, empty = every day
+    start_time TIME, -- Daily window such as happy hour, NULL = all day
+    end_time TIME,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    user_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_promotions_user_id_active ON promotions(user_id, is_active);
+
+CREATE TRIGGER update_promotions_updated_at
+    BEFORE UPDATE ON promotions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE orders ADD COLUMN subtotal INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN discount_total INTEGER NOT NULL DEFAULT 0;
+UPDATE orders SET subtotal = ROUND(total)::INTEGER;
+
+-- discount_amount holds the line's own discounts plus its share of order-level discounts
+ALTER TABLE order_items ADD COLUMN category_id INTEGER;
+ALTER TABLE order_items ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Every discount applied to an order. order_item_id is NULL for order-level discounts.
+CREATE TABLE order_discounts (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    order_item_id INTEGER,
+    promotion_id INTEGER,
+    source VARCHAR(16) NOT NULL CHECK (source IN ('promotion', 'manual')),
+    name VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE,
+    FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_discounts_order_id ON order_discounts(order_id);
+CREATE INDEX idx_order_discounts_promotion_id ON order_discounts(promotion_id);
+
+-- Fully discounted lines refund nothing
+ALTER TABLE refunds DROP CONSTRAINT IF EXISTS refunds_amount_check;
+ALTER TABLE refunds ADD CONSTRAINT refunds_amount_check CHECK (amount >= 0);
@@ -0,0 +1,12 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS service_charge;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_class;
+ALTER TABLE orders DROP COLUMN IF EXISTS service_charge_rate;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_inclusive;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE orders DROP COLUMN IF EXISTS rounding;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_total;
+ALTER TABLE orders DROP COLUMN IF EXISTS service_charge;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
+DROP TRIGGER IF EXISTS update_store_settings_updated_at ON store_settings;
+DROP TABLE IF EXISTS store_settings;
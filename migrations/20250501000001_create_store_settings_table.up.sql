@@ -0,0 +1,32 @@
+-- One row of store settings per user. Users without a row use the column defaults.
+CREATE TABLE store_settings (
+    user_id INTEGER PRIMARY KEY,
+    tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0 AND tax_rate <= 100), -- PPN in percent
+    tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE, -- Catalog prices already include tax
+    service_charge_rate NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (service_charge_rate >= 0 AND service_charge_rate <= 100),
+    rounding_mode VARCHAR(16) NOT NULL DEFAULT 'none' CHECK (rounding_mode IN ('none', 'nearest', 'up', 'down')),
+    rounding_unit INTEGER NOT NULL DEFAULT 0 CHECK (rounding_unit >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER update_store_settings_updated_at
+    BEFORE UPDATE ON store_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE products ADD COLUMN tax_class VARCHAR(16) NOT NULL DEFAULT 'standard' CHECK (tax_class IN ('standard', 'exempt'));
+
+-- The settings used when the order was priced are snapshotted on the order.
+-- total is the grand total: net + service charge + exclusive tax + rounding.
+ALTER TABLE orders ADD COLUMN service_charge INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_total INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN rounding INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_rate NUMERIC(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE orders ADD COLUMN service_charge_rate NUMERIC(5, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE order_items ADD COLUMN tax_class VARCHAR(16) NOT NULL DEFAULT 'standard';
+ALTER TABLE order_items ADD COLUMN service_charge INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
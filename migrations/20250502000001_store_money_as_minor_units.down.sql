@@ -0,0 +1,29 @@
+ALTER TABLE store_settings ALTER COLUMN rounding_unit TYPE INTEGER;
+
+ALTER TABLE promotions ALTER COLUMN min_spend TYPE INTEGER;
+ALTER TABLE promotions ALTER COLUMN amount TYPE INTEGER;
+
+ALTER TABLE refund_items ALTER COLUMN amount TYPE INTEGER;
+ALTER TABLE refunds ALTER COLUMN amount TYPE INTEGER;
+
+ALTER TABLE payments ALTER COLUMN change TYPE INTEGER;
+ALTER TABLE payments ALTER COLUMN tendered TYPE INTEGER;
+ALTER TABLE payments ALTER COLUMN amount TYPE INTEGER;
+
+ALTER TABLE order_discounts ALTER COLUMN amount TYPE INTEGER;
+
+ALTER TABLE order_items ALTER COLUMN tax_amount TYPE INTEGER;
+ALTER TABLE order_items ALTER COLUMN service_charge TYPE INTEGER;
+ALTER TABLE order_items ALTER COLUMN discount_amount TYPE INTEGER;
+ALTER TABLE order_items ALTER COLUMN total_price TYPE INTEGER;
+ALTER TABLE order_items ALTER COLUMN price TYPE INTEGER;
+
+ALTER TABLE orders ALTER COLUMN refunded_amount TYPE INTEGER;
+ALTER TABLE orders ALTER COLUMN rounding TYPE INTEGER;
+ALTER TABLE orders ALTER COLUMN tax_total TYPE INTEGER;
+ALTER TABLE orders ALTER COLUMN service_charge TYPE INTEGER;
+ALTER TABLE orders ALTER COLUMN discount_total TYPE INTEGER;
+ALTER TABLE orders ALTER COLUMN subtotal TYPE INTEGER;
+ALTER TABLE orders ALTER COLUMN total TYPE NUMERIC(12, 2);
+
+ALTER TABLE products ALTER COLUMN price TYPE NUMERIC(10, 2);
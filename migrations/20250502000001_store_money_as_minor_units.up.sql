@@ -0,0 +1,31 @@
+-- Money is stored as BIGINT minor units of the store currency (IDR has no
+-- minor digits, so one unit is one rupiah). Fractional rupiah are rounded.
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT USING ROUND(price)::BIGINT;
+
+ALTER TABLE orders ALTER COLUMN total TYPE BIGINT USING ROUND(total)::BIGINT;
+ALTER TABLE orders ALTER COLUMN subtotal TYPE BIGINT;
+ALTER TABLE orders ALTER COLUMN discount_total TYPE BIGINT;
+ALTER TABLE orders ALTER COLUMN service_charge TYPE BIGINT;
+ALTER TABLE orders ALTER COLUMN tax_total TYPE BIGINT;
+ALTER TABLE orders ALTER COLUMN rounding TYPE BIGINT;
+ALTER TABLE orders ALTER COLUMN refunded_amount TYPE BIGINT;
+
+ALTER TABLE order_items ALTER COLUMN price TYPE BIGINT;
+ALTER TABLE order_items ALTER COLUMN total_price TYPE BIGINT;
+ALTER TABLE order_items ALTER COLUMN discount_amount TYPE BIGINT;
+ALTER TABLE order_items ALTER COLUMN service_charge TYPE BIGINT;
+ALTER TABLE order_items ALTER COLUMN tax_amount TYPE BIGINT;
+
+ALTER TABLE order_discounts ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE payments ALTER COLUMN amount TYPE BIGINT;
+ALTER TABLE payments ALTER COLUMN tendered TYPE BIGINT;
+ALTER TABLE payments ALTER COLUMN change TYPE BIGINT;
+
+ALTER TABLE refunds ALTER COLUMN amount TYPE BIGINT;
+ALTER TABLE refund_items ALTER COLUMN amount TYPE BIGINT;
+
+ALTER TABLE promotions ALTER COLUMN amount TYPE BIGINT;
+ALTER TABLE promotions ALTER COLUMN min_spend TYPE BIGINT;
+
+ALTER TABLE store_settings ALTER COLUMN rounding_unit TYPE BIGINT;
@@ -0,0 +1,5 @@
+ALTER TABLE store_settings DROP COLUMN IF EXISTS receipt_footer;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS tax_id;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS store_phone;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS store_address;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS store_name;
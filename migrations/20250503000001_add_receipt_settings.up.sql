@@ -0,0 +1,6 @@
+-- Store details printed at the top and bottom of receipts
+ALTER TABLE store_settings ADD COLUMN store_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE store_settings ADD COLUMN store_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE store_settings ADD COLUMN store_phone VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE store_settings ADD COLUMN tax_id VARCHAR(32) NOT NULL DEFAULT ''; -- NPWP
+ALTER TABLE store_settings ADD COLUMN receipt_footer TEXT NOT NULL DEFAULT '';
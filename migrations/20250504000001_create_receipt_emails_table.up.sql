@@ -0,0 +1,12 @@
+-- Receipts emailed to customers
+CREATE TABLE receipt_emails (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    sent_by INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    FOREIGN KEY (sent_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_receipt_emails_order_id ON receipt_emails(order_id);
@@ -0,0 +1,5 @@
+UPDATE orders SET status = 'open' WHERE status = 'held';
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('open', 'paid', 'voided', 'partially_refunded', 'refunded'));
+ALTER TABLE orders DROP COLUMN IF EXISTS label;
@@ -0,0 +1,5 @@
+-- Held (parked) baskets carry a label such as "table 4" and are not sales until paid
+ALTER TABLE orders ADD COLUMN label VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('open', 'held', 'paid', 'voided', 'partially_refunded', 'refunded'));
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_orders_user_id_order_number;
+ALTER TABLE orders DROP COLUMN IF EXISTS order_number;
+ALTER TABLE orders DROP COLUMN IF EXISTS outlet_code;
+DROP TABLE IF EXISTS order_number_sequences;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS order_number_format;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS outlet_code;
@@ -0,0 +1,39 @@
+-- Human-readable order numbers such as OUT1-20261017-0042
+ALTER TABLE store_settings ADD COLUMN outlet_code VARCHAR(16) NOT NULL DEFAULT 'OUT1';
+ALTER TABLE store_settings ADD COLUMN order_number_format VARCHAR(48) NOT NULL DEFAULT '{outlet}-{date}-{seq:4}';
+
+-- Last sequence value handed out per outlet and business day. The row stays
+-- locked by the creating transaction, so concurrent orders wait for each other
+-- and a rolled back order gives its number back.
+CREATE TABLE order_number_sequences (
+    user_id INTEGER NOT NULL,
+    outlet_code VARCHAR(16) NOT NULL,
+    business_date DATE NOT NULL,
+    last_value INTEGER NOT NULL,
+    PRIMARY KEY (user_id, outlet_code, business_date),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+ALTER TABLE orders ADD COLUMN outlet_code VARCHAR(16) NOT NULL DEFAULT 'OUT1';
+ALTER TABLE orders ADD COLUMN order_number VARCHAR(64);
+
+-- Number existing orders with the default format, in creation order per day
+WITH numbered AS (
+    SELECT id, created_at::date AS business_date,
+           ROW_NUMBER() OVER (PARTITION BY user_id, created_at::date ORDER BY created_at, id) AS seq
+    FROM orders
+)
+UPDATE orders
+SET order_number = 'OUT1-' || TO_CHAR(numbered.business_date, 'YYYYMMDD') || '-' ||
+                   LPAD(numbered.seq::text, GREATEST(4, LENGTH(numbered.seq::text)), '0')
+FROM numbered
+WHERE orders.id = numbered.id;
+
+INSERT INTO order_number_sequences (user_id, outlet_code, business_date, last_value)
+SELECT user_id, 'OUT1', created_at::date, COUNT(*)
+FROM orders
+GROUP BY user_id, created_at::date;
+
+ALTER TABLE orders ALTER COLUMN order_number SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN outlet_code DROP DEFAULT;
+CREATE UNIQUE INDEX idx_orders_user_id_order_number ON orders(user_id, order_number);
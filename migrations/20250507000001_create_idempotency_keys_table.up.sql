@@ -0,0 +1,15 @@
+-- Responses of mutating requests sent with an Idempotency-Key header.
+-- status_code is NULL while the first request with the key is running.
+CREATE TABLE idempotency_keys (
+    user_id INTEGER NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, idempotency_key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_orders_user_id_client_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS client_id;
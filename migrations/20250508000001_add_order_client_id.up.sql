@@ -0,0 +1,3 @@
+-- UUID generated by the terminal for orders made offline and synced later
+ALTER TABLE orders ADD COLUMN client_id UUID;
+CREATE UNIQUE INDEX idx_orders_user_id_client_id ON orders(user_id, client_id) WHERE client_id IS NOT NULL;
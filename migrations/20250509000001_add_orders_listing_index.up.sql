@@ -0,0 +1,2 @@
+-- Keyset pagination of the order list: newest first, ID breaking ties
+CREATE INDEX idx_orders_user_id_created_at_id ON orders(user_id, created_at DESC, id DESC);
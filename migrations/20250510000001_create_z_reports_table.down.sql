@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS z_reports_immutable ON z_reports;
+DROP FUNCTION IF EXISTS prevent_z_report_update();
+DROP TABLE IF EXISTS z_reports;
+DROP INDEX IF EXISTS idx_refunds_created_at;
+DROP INDEX IF EXISTS idx_orders_user_id_paid_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS paid_at;
@@ -0,0 +1,43 @@
+-- When an order was paid in full. Sales reports count orders on that day.
+ALTER TABLE orders ADD COLUMN paid_at TIMESTAMP;
+UPDATE orders o
+SET paid_at = COALESCE(
+    (SELECT MIN(e.created_at) FROM order_status_events e WHERE e.order_id = o.id AND e.to_status = 'paid'),
+    o.created_at
+)
+WHERE o.status IN ('paid', 'partially_refunded', 'refunded');
+CREATE INDEX idx_orders_user_id_paid_at ON orders(user_id, paid_at);
+CREATE INDEX idx_refunds_created_at ON refunds(created_at);
+
+-- End-of-day Z reports. The figures are frozen when the day is closed.
+CREATE TABLE z_reports (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    number INTEGER NOT NULL,
+    business_date DATE NOT NULL,
+    report JSONB NOT NULL,
+    closed_by INTEGER NOT NULL,
+    closed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, business_date),
+    UNIQUE (user_id, number),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (closed_by) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Closed reports can neither change nor be deleted, which would let their
+-- number be issued again. Deletes cascading from a deleted user, which run
+-- inside the foreign key's own trigger, are let through.
+CREATE OR REPLACE FUNCTION prevent_z_report_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1 THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'z reports are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER z_reports_immutable
+    BEFORE UPDATE OR DELETE ON z_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_z_report_update();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_user_id_lower_name;
+DROP INDEX IF EXISTS idx_products_user_id_sku;
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
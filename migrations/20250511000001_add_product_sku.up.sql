@@ -0,0 +1,5 @@
+-- Store product codes, unique per user. Imports match products by SKU, or
+-- by name when a row has no SKU.
+ALTER TABLE products ADD COLUMN sku VARCHAR(64);
+CREATE UNIQUE INDEX idx_products_user_id_sku ON products(user_id, sku) WHERE sku IS NOT NULL;
+CREATE INDEX idx_products_user_id_lower_name ON products(user_id, LOWER(name));
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+DROP FUNCTION IF EXISTS prevent_stock_movement_update();
+DROP TABLE IF EXISTS stock_movements;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS allow_negative_stock;
+ALTER TABLE products DROP COLUMN IF EXISTS stock_on_hand;
+ALTER TABLE products DROP COLUMN IF EXISTS track_stock;
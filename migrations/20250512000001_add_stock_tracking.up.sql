@@ -0,0 +1,45 @@
+-- Per-product stock on hand. Only products with track_stock are counted.
+ALTER TABLE products ADD COLUMN track_stock BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE products ADD COLUMN stock_on_hand INTEGER NOT NULL DEFAULT 0;
+
+-- Whether tracked products may be sold beyond their stock on hand
+ALTER TABLE store_settings ADD COLUMN allow_negative_stock BOOLEAN NOT NULL DEFAULT false;
+
+-- Every change to a product's stock on hand. quantity is the signed change and
+-- balance_after the stock on hand once it was applied. Orders and refunds are
+-- referenced without foreign keys so the ledger outlives deleted open orders.
+CREATE TABLE stock_movements (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('sale', 'void', 'refund', 'adjustment', 'receiving', 'waste')),
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    balance_after INTEGER NOT NULL,
+    order_id INTEGER,
+    refund_id INTEGER,
+    reason TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_stock_movements_product_id_id ON stock_movements(product_id, id DESC);
+
+-- Movements can neither change nor be deleted. Deletes cascading from a
+-- deleted product or user, which run inside the foreign key's own trigger,
+-- are let through.
+CREATE OR REPLACE FUNCTION prevent_stock_movement_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1 THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'stock movements are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_stock_movement_update();
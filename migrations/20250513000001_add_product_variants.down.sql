@@ -0,0 +1,8 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS options;
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant;
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+ALTER TABLE stock_movements DROP COLUMN IF EXISTS variant_id;
+DROP TRIGGER IF EXISTS update_product_variants_updated_at ON product_variants;
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS product_options;
+DROP TABLE IF EXISTS product_option_groups;
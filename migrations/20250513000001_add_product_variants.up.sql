@@ -0,0 +1,59 @@
+-- Option groups such as "Size: S/M/L" or "Ice: less/normal". Between
+-- min_select and max_select options are chosen per order line; max_select 0
+-- allows every option.
+CREATE TABLE product_option_groups (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT false,
+    min_select INTEGER NOT NULL DEFAULT 0 CHECK (min_select >= 0),
+    max_select INTEGER NOT NULL DEFAULT 1 CHECK (max_select >= 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_product_option_groups_product_id ON product_option_groups(product_id);
+
+CREATE TABLE product_options (
+    id SERIAL PRIMARY KEY,
+    group_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    price_delta BIGINT NOT NULL DEFAULT 0,
+    is_available BOOLEAN NOT NULL DEFAULT true,
+    position INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (group_id) REFERENCES product_option_groups(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_product_options_group_id ON product_options(group_id);
+
+-- Sellable variants of a product, each with its own SKU, price and stock.
+-- Products with variants are sold by variant.
+CREATE TABLE product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    sku VARCHAR(64),
+    price BIGINT NOT NULL,
+    is_available BOOLEAN NOT NULL DEFAULT true,
+    track_stock BOOLEAN NOT NULL DEFAULT false,
+    stock_on_hand INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, name),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_product_variants_user_id_sku ON product_variants(user_id, sku) WHERE sku IS NOT NULL;
+
+CREATE TRIGGER update_product_variants_updated_at
+    BEFORE UPDATE ON product_variants
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Stock of products with variants is kept per variant
+ALTER TABLE stock_movements ADD COLUMN variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE;
+
+-- Order lines keep the variant and options they were sold with
+ALTER TABLE order_items ADD COLUMN variant_id INTEGER;
+ALTER TABLE order_items ADD COLUMN variant VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE order_items ADD COLUMN options JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,5 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS note;
+ALTER TABLE order_items DROP COLUMN IF EXISTS modifiers;
+DROP TABLE IF EXISTS modifiers;
+DROP TRIGGER IF EXISTS update_modifier_lists_updated_at ON modifier_lists;
+DROP TABLE IF EXISTS modifier_lists;
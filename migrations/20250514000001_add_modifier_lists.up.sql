@@ -0,0 +1,41 @@
+-- Modifier lists are reusable add-ons such as "Extras: extra shot, oat milk"
+-- or "Sugar: no sugar, less sugar", offered on the products and categories
+-- they are attached to. Between min_select and max_select modifiers are
+-- chosen per order line; max_select 0 allows every modifier.
+CREATE TABLE modifier_lists (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT false,
+    min_select INTEGER NOT NULL DEFAULT 0 CHECK (min_select >= 0),
+    max_select INTEGER NOT NULL DEFAULT 0 CHECK (max_select >= 0),
+    product_ids INTEGER[] NOT NULL DEFAULT '{}',
+    category_ids INTEGER[] NOT NULL DEFAULT '{}',
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, name),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_modifier_lists_product_ids ON modifier_lists USING GIN (product_ids);
+CREATE INDEX idx_modifier_lists_category_ids ON modifier_lists USING GIN (category_ids);
+
+CREATE TRIGGER update_modifier_lists_updated_at
+    BEFORE UPDATE ON modifier_lists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE modifiers (
+    id SERIAL PRIMARY KEY,
+    list_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    price_delta BIGINT NOT NULL DEFAULT 0,
+    is_available BOOLEAN NOT NULL DEFAULT true,
+    position INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (list_id) REFERENCES modifier_lists(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_modifiers_list_id ON modifiers(list_id);
+
+-- Order lines keep the modifiers they were sold with and the customer's note
+ALTER TABLE order_items ADD COLUMN modifiers JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE order_items ADD COLUMN note VARCHAR(200) NOT NULL DEFAULT '';
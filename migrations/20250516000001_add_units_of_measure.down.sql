@@ -0,0 +1,14 @@
+ALTER TABLE store_settings DROP COLUMN IF EXISTS scale_price_prefixes;
+ALTER TABLE store_settings DROP COLUMN IF EXISTS scale_weight_prefixes;
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit;
+
+-- Decimal quantities are rounded up so no sold or stocked amount disappears
+ALTER TABLE refund_items ALTER COLUMN quantity TYPE INTEGER USING CEIL(quantity);
+ALTER TABLE order_items ALTER COLUMN quantity TYPE INTEGER USING CEIL(quantity);
+ALTER TABLE stock_movements ALTER COLUMN balance_after TYPE INTEGER USING ROUND(balance_after);
+ALTER TABLE stock_movements ALTER COLUMN quantity TYPE INTEGER USING CASE WHEN quantity < 0 THEN FLOOR(quantity) ELSE CEIL(quantity) END;
+ALTER TABLE product_variants ALTER COLUMN stock_on_hand TYPE INTEGER USING ROUND(stock_on_hand);
+ALTER TABLE products ALTER COLUMN stock_on_hand TYPE INTEGER USING ROUND(stock_on_hand);
+
+ALTER TABLE products DROP COLUMN IF EXISTS price_rounding;
+ALTER TABLE products DROP COLUMN IF EXISTS unit;
@@ -0,0 +1,21 @@
+-- Products sold by weight, volume or length. unit is the unit price and stock
+-- are kept in; price_rounding rounds the line amount of decimal quantities to
+-- the currency's minor unit.
+ALTER TABLE products ADD COLUMN unit VARCHAR(8) NOT NULL DEFAULT 'each' CHECK (unit IN ('each', 'kg', 'g', 'l', 'm'));
+ALTER TABLE products ADD COLUMN price_rounding VARCHAR(16) NOT NULL DEFAULT 'nearest' CHECK (price_rounding IN ('nearest', 'up', 'down'));
+
+-- Quantities keep three decimals, such as 0.35 kg
+ALTER TABLE products ALTER COLUMN stock_on_hand TYPE NUMERIC(12, 3);
+ALTER TABLE product_variants ALTER COLUMN stock_on_hand TYPE NUMERIC(12, 3);
+ALTER TABLE stock_movements ALTER COLUMN quantity TYPE NUMERIC(12, 3);
+ALTER TABLE stock_movements ALTER COLUMN balance_after TYPE NUMERIC(12, 3);
+ALTER TABLE order_items ALTER COLUMN quantity TYPE NUMERIC(12, 3);
+ALTER TABLE refund_items ALTER COLUMN quantity TYPE NUMERIC(12, 3);
+
+-- The unit is snapshotted on the order line with its name and price
+ALTER TABLE order_items ADD COLUMN unit VARCHAR(8) NOT NULL DEFAULT 'each';
+
+-- In-store EAN-13 prefixes (20 to 29) under which the store's scales print
+-- the weight in grams or the price in minor units
+ALTER TABLE store_settings ADD COLUMN scale_weight_prefixes TEXT[] NOT NULL DEFAULT '{20,21,22,23,24}';
+ALTER TABLE store_settings ADD COLUMN scale_price_prefixes TEXT[] NOT NULL DEFAULT '{25,26,27,28,29}';
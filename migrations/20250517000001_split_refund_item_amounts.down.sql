@@ -0,0 +1,3 @@
+ALTER TABLE refund_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE refund_items DROP COLUMN IF EXISTS service_charge;
+ALTER TABLE refund_items DROP COLUMN IF EXISTS net_amount;
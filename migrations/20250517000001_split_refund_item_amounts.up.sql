@@ -0,0 +1,13 @@
+-- Refund lines keep the parts of what was given back, so reports can take the
+-- net line value out of net sales and the tax out of the tax collected
+ALTER TABLE refund_items ADD COLUMN net_amount INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE refund_items ADD COLUMN service_charge INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE refund_items ADD COLUMN tax_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Earlier refund lines were refunded pro rata of the quantity sold
+UPDATE refund_items ri
+SET net_amount = ROUND((oi.total_price - oi.discount_amount) * ri.quantity / oi.quantity)::INTEGER,
+    service_charge = ROUND(oi.service_charge * ri.quantity / oi.quantity)::INTEGER,
+    tax_amount = ROUND(oi.tax_amount * ri.quantity / oi.quantity)::INTEGER
+FROM order_items oi
+WHERE oi.id = ri.order_item_id AND oi.quantity > 0;
@@ -0,0 +1,102 @@
+// Package barcode validates and normalises the codes printed on products:
+// EAN-13, EAN-8 and UPC-A retail barcodes and the store's own internal codes.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxLength is the longest code accepted
+const MaxLength = 64
+
+// Kind is the symbology of a code
+type Kind string
+
+const (
+	EAN13    Kind = "ean13"
+	EAN8     Kind = "ean8"
+	UPCA     Kind = "upca"
+	Internal Kind = "internal"
+)
+
+// CheckDigit returns the GS1 check digit of a numeric code given without it:
+// digits are weighted 3 and 1 alternately from the right
+func CheckDigit(digits string) int {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		digit := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return (10 - sum%10) % 10
+}
+
+// Normalize validates a code and returns it in the form it is stored and
+// looked up by. Numeric codes of 8, 12 and 13 digits are EAN-8, UPC-A and
+// EAN-13 and must carry a valid check digit; UPC-A codes are returned as the
+// equivalent EAN-13 so scanners reporting either form find the same product.
+// Any other code of printable characters without spaces is an internal code.
+func Normalize(code string) (string, Kind, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", "", errors.New("code is empty")
+	}
+	if len(code) > MaxLength {
+		return "", "", fmt.Errorf("code is longer than %d characters", MaxLength)
+	}
+
+	if isDigits(code) {
+		var kind Kind
+		switch len(code) {
+		case 8:
+			kind = EAN8
+		case 12:
+			kind = UPCA
+		case 13:
+			kind = EAN13
+		}
+		if kind != "" {
+			if CheckDigit(code[:len(code)-1]) != int(code[len(code)-1]-'0') {
+				return "", "", fmt.Errorf("%s has an invalid %s check digit", code, label(kind))
+			}
+			if kind == UPCA {
+				code = "0" + code
+			}
+			return code, kind, nil
+		}
+	}
+
+	for _, r := range code {
+		if r <= ' ' || r > '~' {
+			return "", "", fmt.Errorf("%s contains characters other than letters, digits and symbols", code)
+		}
+	}
+	return code, Internal, nil
+}
+
+// label returns the usual written name of a kind, such as "EAN-13"
+func label(kind Kind) string {
+	switch kind {
+	case EAN13:
+		return "EAN-13"
+	case EAN8:
+		return "EAN-8"
+	case UPCA:
+		return "UPC-A"
+	}
+	return string(kind)
+}
+
+// isDigits reports whether code consists of ASCII digits only
+func isDigits(code string) bool {
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+	return code != ""
+}
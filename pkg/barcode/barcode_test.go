@@ -0,0 +1,176 @@
+package barcode_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/barcode"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+func TestCheckDigit(t *testing.T) {
+	assert.Equal(t, 1, barcode.CheckDigit("400638133393"))
+	assert.Equal(t, 2, barcode.CheckDigit("03600029145"))
+	assert.Equal(t, 4, barcode.CheckDigit("9638507"))
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		want     string
+		wantKind barcode.Kind
+		wantErr  bool
+	}{
+		{name: "EAN-13", code: "4006381333931", want: "4006381333931", wantKind: barcode.EAN13},
+		{name: "UPC-A stored as EAN-13", code: "036000291452", want: "0036000291452", wantKind: barcode.UPCA},
+		{name: "EAN-8", code: "96385074", want: "96385074", wantKind: barcode.EAN8},
+		{name: "surrounding spaces", code: " 4006381333931 ", want: "4006381333931", wantKind: barcode.EAN13},
+		{name: "internal code", code: "LAT-L-HOT", want: "LAT-L-HOT", wantKind: barcode.Internal},
+		{name: "numeric internal code", code: "100245", want: "100245", wantKind: barcode.Internal},
+		{name: "bad EAN-13 check digit", code: "4006381333932", wantErr: true},
+		{name: "bad UPC-A check digit", code: "036000291455", wantErr: true},
+		{name: "empty", code: "  ", wantErr: true},
+		{name: "inner space", code: "LAT L", wantErr: true},
+		{name: "non-ASCII", code: "caf\u00e9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, kind, err := barcode.Normalize(tt.code)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, code)
+			assert.Equal(t, tt.wantKind, kind)
+		})
+	}
+}
+
+// bits renders a pattern as 0 and 1 modules
+func bits(pattern barcode.Pattern) string {
+	out := make([]byte, len(pattern))
+	for i, bar := range pattern {
+		out[i] = '0'
+		if bar {
+			out[i] = '1'
+		}
+	}
+	return string(out)
+}
+
+func TestEncodeEAN13(t *testing.T) {
+	pattern, err := barcode.EncodeEAN13("4006381333931")
+	assert.NoError(t, err)
+	modules := bits(pattern)
+	assert.Len(t, modules, 95)
+	assert.Equal(t, "101", modules[:3], "start guard")
+	assert.Equal(t, "0001101", modules[3:10], "0 in odd parity after a leading 4")
+	assert.Equal(t, "0100111", modules[10:17], "0 in even parity after a leading 4")
+	assert.Equal(t, "01010", modules[45:50], "centre guard")
+	assert.Equal(t, "1100110", modules[85:92], "check digit 1 as a right-hand code")
+	assert.Equal(t, "101", modules[92:], "end guard")
+
+	upc, err := barcode.EncodeEAN13("036000291452")
+	assert.NoError(t, err)
+	assert.Len(t, upc, 95)
+
+	_, err = barcode.EncodeEAN13("4006381333932")
+	assert.Error(t, err)
+	_, err = barcode.EncodeEAN13("LAT-L-HOT")
+	assert.Error(t, err)
+}
+
+func TestEncodeCode128(t *testing.T) {
+	pattern, err := barcode.EncodeCode128("PJJ123C")
+	assert.NoError(t, err)
+	modules := bits(pattern)
+	assert.Len(t, modules, (1+7+1)*11+13)
+	assert.Equal(t, "11010010000", modules[:11], "start code B")
+	assert.Equal(t, "11101000110", modules[88:99], "check symbol 55")
+	assert.Equal(t, "1100011101011", modules[99:], "stop")
+
+	digits, err := barcode.EncodeCode128("123456")
+	assert.NoError(t, err)
+	assert.Len(t, digits, (1+3+1)*11+13, "even digit runs use code set C")
+	assert.Equal(t, "11010011100", bits(digits)[:11], "start code C")
+
+	_, err = barcode.EncodeCode128("caf\u00e9")
+	assert.Error(t, err)
+	_, err = barcode.EncodeCode128("")
+	assert.Error(t, err)
+}
+
+func TestScaleLayoutParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout barcode.ScaleLayout
+		code   string
+		want   barcode.Scale
+		wantOK bool
+	}{
+		{name: "weight", layout: barcode.DefaultScaleLayout, code: "2112345003504", want: barcode.Scale{Kind: barcode.ScaleWeight, ItemCode: "12345", Value: 350}, wantOK: true},
+		{name: "price", layout: barcode.DefaultScaleLayout, code: "2512345227502", want: barcode.Scale{Kind: barcode.ScalePrice, ItemCode: "12345", Value: 22750}, wantOK: true},
+		{name: "custom prefixes", layout: barcode.ScaleLayout{PricePrefixes: []string{"22"}}, code: "2212345012503", want: barcode.Scale{Kind: barcode.ScalePrice, ItemCode: "12345", Value: 1250}, wantOK: true},
+		{name: "not an in-store prefix", layout: barcode.DefaultScaleLayout, code: "3012345003506"},
+		{name: "prefix not configured", layout: barcode.ScaleLayout{WeightPrefixes: []string{"20"}}, code: "2112345003504"},
+		{name: "bad check digit", layout: barcode.DefaultScaleLayout, code: "2112345003505"},
+		{name: "too short", layout: barcode.DefaultScaleLayout, code: "211234500350"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scale, ok := tt.layout.Parse(tt.code)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, scale)
+		})
+	}
+}
+
+func TestScaleLayoutValidate(t *testing.T) {
+	assert.NoError(t, barcode.DefaultScaleLayout.Validate())
+	assert.NoError(t, barcode.ScaleLayout{WeightPrefixes: []string{"20"}}.Validate())
+	assert.Error(t, barcode.ScaleLayout{WeightPrefixes: []string{"30"}}.Validate())
+	assert.Error(t, barcode.ScaleLayout{WeightPrefixes: []string{"2"}}.Validate())
+	assert.Error(t, barcode.ScaleLayout{WeightPrefixes: []string{"21"}, PricePrefixes: []string{"21"}}.Validate())
+}
+
+func TestScaleQuantity(t *testing.T) {
+	weight := barcode.Scale{Kind: barcode.ScaleWeight, ItemCode: "12345", Value: 350}
+	price := barcode.Scale{Kind: barcode.ScalePrice, ItemCode: "12345", Value: 22750}
+
+	tests := []struct {
+		name      string
+		scale     barcode.Scale
+		unit      quantity.Unit
+		unitPrice int64
+		want      quantity.Quantity
+		wantErr   bool
+	}{
+		{name: "grams sold by kg", scale: weight, unit: quantity.Kilogram, want: 350},
+		{name: "grams sold by g", scale: weight, unit: quantity.Gram, want: quantity.FromInt(350)},
+		{name: "weight of an item", scale: weight, unit: quantity.Each, wantErr: true},
+		{name: "weight of a litre", scale: weight, unit: quantity.Litre, wantErr: true},
+		{name: "no weight", scale: barcode.Scale{Kind: barcode.ScaleWeight, ItemCode: "12345"}, unit: quantity.Kilogram, wantErr: true},
+		{name: "price per kg", scale: price, unit: quantity.Kilogram, unitPrice: 65000, want: 350},
+		{name: "price per g rounds to whole grams", scale: price, unit: quantity.Gram, unitPrice: 65, want: quantity.FromInt(350)},
+		{name: "price of an item", scale: price, unit: quantity.Each, unitPrice: 22750, want: quantity.FromInt(1)},
+		{name: "no price of an item", scale: barcode.Scale{Kind: barcode.ScalePrice, ItemCode: "12345"}, unit: quantity.Each, unitPrice: 22750, wantErr: true},
+		{name: "price without a unit price", scale: price, unit: quantity.Kilogram, wantErr: true},
+		{name: "price below the smallest quantity", scale: barcode.Scale{Kind: barcode.ScalePrice, Value: 1}, unit: quantity.Gram, unitPrice: 65, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scale.Quantity(tt.unit, tt.unitPrice)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
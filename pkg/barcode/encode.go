@@ -0,0 +1,162 @@
+package barcode
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Symbology is the way a code is drawn as bars
+type Symbology string
+
+const (
+	SymbologyEAN13   Symbology = "ean13"
+	SymbologyCode128 Symbology = "code128"
+)
+
+// Pattern is a barcode as a row of equally wide modules, true for a bar.
+// Quiet zones are not included.
+type Pattern []bool
+
+// Quiet zone widths in modules
+const (
+	QuietEAN13   = 11
+	QuietCode128 = 10
+)
+
+// ean13Left holds the odd parity (L) codes of the digits; even parity (G)
+// codes are the L codes of the complement reversed and right-hand (R) codes
+// are the complement
+var ean13Left = [10]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// ean13Parity selects L or G codes for the left half from the first digit
+var ean13Parity = [10]string{
+	"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+	"LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL",
+}
+
+// code128Widths holds the bar and space widths of every Code 128 symbol;
+// 103 to 105 start code sets A, B and C and 106 is the stop symbol
+var code128Widths = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// EncodeEAN13 draws a 13-digit EAN code with a valid check digit, or a UPC-A
+// code in its EAN-13 form
+func EncodeEAN13(code string) (Pattern, error) {
+	normalized, kind, err := Normalize(code)
+	if err != nil {
+		return nil, err
+	}
+	if kind != EAN13 && kind != UPCA {
+		return nil, fmt.Errorf("%s is not an EAN-13 or UPC-A code", code)
+	}
+
+	var modules string
+	modules += "101"
+	parity := ean13Parity[normalized[0]-'0']
+	for i := 1; i <= 6; i++ {
+		left := ean13Left[normalized[i]-'0']
+		if parity[i-1] == 'G' {
+			left = reverse(complement(left))
+		}
+		modules += left
+	}
+	modules += "01010"
+	for i := 7; i <= 12; i++ {
+		modules += complement(ean13Left[normalized[i]-'0'])
+	}
+	modules += "101"
+	return fromBits(modules), nil
+}
+
+// EncodeCode128 draws printable ASCII data as Code 128. Data of an even
+// number of at least four digits uses the compact code set C; anything else
+// uses code set B.
+func EncodeCode128(data string) (Pattern, error) {
+	if data == "" {
+		return nil, fmt.Errorf("nothing to encode")
+	}
+
+	var symbols []int
+	if len(data) >= 4 && len(data)%2 == 0 && isDigits(data) {
+		symbols = append(symbols, code128StartC)
+		for i := 0; i < len(data); i += 2 {
+			pair, _ := strconv.Atoi(data[i : i+2])
+			symbols = append(symbols, pair)
+		}
+	} else {
+		symbols = append(symbols, code128StartB)
+		for _, r := range data {
+			if r < ' ' || r > '~' {
+				return nil, fmt.Errorf("%s cannot be drawn as Code 128: only printable ASCII is supported", data)
+			}
+			symbols = append(symbols, int(r-' '))
+		}
+	}
+
+	checksum := symbols[0]
+	for i, symbol := range symbols[1:] {
+		checksum += (i + 1) * symbol
+	}
+	symbols = append(symbols, checksum%103, code128Stop)
+
+	var pattern Pattern
+	for _, symbol := range symbols {
+		for i, width := range code128Widths[symbol] {
+			for n := 0; n < int(width-'0'); n++ {
+				pattern = append(pattern, i%2 == 0)
+			}
+		}
+	}
+	return pattern, nil
+}
+
+// complement swaps bars and spaces
+func complement(bits string) string {
+	out := []byte(bits)
+	for i, b := range out {
+		if b == '0' {
+			out[i] = '1'
+		} else {
+			out[i] = '0'
+		}
+	}
+	return string(out)
+}
+
+// reverse reverses the order of the modules
+func reverse(bits string) string {
+	out := []byte(bits)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// fromBits converts a string of 0 and 1 modules into a pattern
+func fromBits(bits string) Pattern {
+	pattern := make(Pattern, len(bits))
+	for i := range bits {
+		pattern[i] = bits[i] == '1'
+	}
+	return pattern
+}
@@ -0,0 +1,27 @@
+package barcode
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
+
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// LoadScaleLayout returns the scale prefixes of the user's store. Users who
+// never saved settings get DefaultScaleLayout.
+func LoadScaleLayout(db queryRower, userID int) (ScaleLayout, *customerror.CustomError) {
+	var weight, price pq.StringArray
+	err := db.QueryRow(`SELECT scale_weight_prefixes, scale_price_prefixes FROM store_settings WHERE user_id = $1`, userID).Scan(&weight, &price)
+	if err == sql.ErrNoRows {
+		return DefaultScaleLayout, nil
+	}
+	if err != nil {
+		return ScaleLayout{}, customerror.NewPostgresError(err)
+	}
+	return ScaleLayout{WeightPrefixes: weight, PricePrefixes: price}, nil
+}
@@ -0,0 +1,123 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+// ScaleKind is what the value embedded in a scale barcode measures
+type ScaleKind string
+
+const (
+	// ScaleWeight barcodes embed the weight in grams
+	ScaleWeight ScaleKind = "weight"
+	// ScalePrice barcodes embed the price in minor units of the store currency
+	ScalePrice ScaleKind = "price"
+)
+
+// ScaleLayout lists the in-store EAN-13 prefixes, 20 to 29, under which a
+// store's scales print the weight or the price of what was weighed
+type ScaleLayout struct {
+	WeightPrefixes []string
+	PricePrefixes  []string
+}
+
+// DefaultScaleLayout is used by stores that never configured their scales
+var DefaultScaleLayout = ScaleLayout{
+	WeightPrefixes: []string{"20", "21", "22", "23", "24"},
+	PricePrefixes:  []string{"25", "26", "27", "28", "29"},
+}
+
+// Validate checks that every prefix is an in-store prefix listed only once
+func (l ScaleLayout) Validate() error {
+	seen := make(map[string]bool)
+	for _, prefix := range slices.Concat(l.WeightPrefixes, l.PricePrefixes) {
+		if len(prefix) != 2 || prefix[0] != '2' || !isDigits(prefix) {
+			return fmt.Errorf("scale prefix %q must be between 20 and 29", prefix)
+		}
+		if seen[prefix] {
+			return fmt.Errorf("scale prefix %s is listed more than once", prefix)
+		}
+		seen[prefix] = true
+	}
+	return nil
+}
+
+// Scale is a decoded scale barcode. ItemCode identifies the product the
+// scale was set to, and Value is grams or minor units depending on Kind.
+type Scale struct {
+	Kind     ScaleKind
+	ItemCode string
+	Value    int64
+}
+
+// Parse decodes a scale barcode laid out as two prefix digits, a five-digit
+// item code, a five-digit value and the check digit, such as 2112345003504
+// for 350 g of item 12345. It reports false for codes that are not a valid
+// EAN-13 under one of the layout's prefixes.
+func (l ScaleLayout) Parse(code string) (Scale, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != 13 || !isDigits(code) || CheckDigit(code[:12]) != int(code[12]-'0') {
+		return Scale{}, false
+	}
+
+	var kind ScaleKind
+	switch prefix := code[:2]; {
+	case slices.Contains(l.WeightPrefixes, prefix):
+		kind = ScaleWeight
+	case slices.Contains(l.PricePrefixes, prefix):
+		kind = ScalePrice
+	default:
+		return Scale{}, false
+	}
+
+	value, err := strconv.ParseInt(code[7:12], 10, 64)
+	if err != nil {
+		return Scale{}, false
+	}
+	return Scale{Kind: kind, ItemCode: code[2:7], Value: value}, true
+}
+
+// Quantity returns the quantity a scale barcode stands for in the unit the
+// product is sold in. Weight barcodes need a product sold by kg or g; price
+// barcodes are divided by the unit price, rounded to the unit's decimals, and
+// stand for one item of products sold by the item. Barcodes that hold no
+// weight or price are rejected.
+func (s Scale) Quantity(unit quantity.Unit, unitPrice int64) (quantity.Quantity, error) {
+	if s.Kind == ScaleWeight {
+		if s.Value <= 0 {
+			return 0, errors.New("the barcode holds no weight")
+		}
+		switch unit {
+		case quantity.Kilogram:
+			return quantity.Quantity(s.Value), nil
+		case quantity.Gram:
+			return quantity.FromInt(int(s.Value)), nil
+		}
+		return 0, fmt.Errorf("weight barcodes need a product sold by kg or g, not by %s", unit)
+	}
+
+	if s.Value <= 0 {
+		return 0, errors.New("the barcode holds no price")
+	}
+	if unit == "" || unit == quantity.Each {
+		return quantity.FromInt(1), nil
+	}
+	if unitPrice <= 0 {
+		return 0, errors.New("price barcodes need a product with a price")
+	}
+	// Divide in steps of the smallest quantity the unit allows, rounding half up
+	step := int64(math.Pow10(3 - unit.Decimals()))
+	scaled := s.Value * quantity.Scale / step
+	milli := (2*scaled + unitPrice) / (2 * unitPrice) * step
+	if milli <= 0 {
+		return 0, fmt.Errorf("a price of %d buys less than the smallest quantity", s.Value)
+	}
+	return quantity.Quantity(milli), nil
+}
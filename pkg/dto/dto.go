@@ -6,6 +6,14 @@ type DataResponse[T any] struct {
 	Data T `json:"data"`
 }
 
+// PageResponse represents one page of a cursor-paginated list. NextCursor is
+// empty on the last page.
+// @Description Paginated data response model
+type PageResponse[T any] struct {
+	Data       T      `json:"data"`
+	NextCursor string `json:"next_cursor"`
+}
+
 // MessageResponse represents a generic message response
 // @Description Generic message response model
 type MessageResponse struct {
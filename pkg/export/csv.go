@@ -0,0 +1,165 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+// byteOrderMark makes spreadsheet programs read the file as UTF-8
+const byteOrderMark = "\uFEFF"
+
+// TimeLayout is how times are written in CSV files
+const TimeLayout = "2006-01-02 15:04:05"
+
+type csvWriter struct {
+	out     io.Writer
+	writer  *csv.Writer
+	locale  Locale
+	header  []string
+	started bool
+}
+
+func newCSVWriter(w io.Writer, locale Locale) *csvWriter {
+	writer := csv.NewWriter(w)
+	writer.Comma = locale.Delimiter
+	return &csvWriter{out: w, writer: writer, locale: locale}
+}
+
+// WriteHeader sets the column names written as the first line
+func (w *csvWriter) WriteHeader(columns ...string) error {
+	w.header = columns
+	return nil
+}
+
+// start writes the byte order mark and header before the first line
+func (w *csvWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	if _, err := io.WriteString(w.out, byteOrderMark); err != nil {
+		return err
+	}
+	if w.header != nil {
+		return w.writer.Write(w.header)
+	}
+	return nil
+}
+
+// WriteRow writes one line
+func (w *csvWriter) WriteRow(values ...any) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = w.format(value)
+	}
+	return w.writer.Write(record)
+}
+
+// Close writes the header of an empty export and flushes buffered lines
+func (w *csvWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// format renders a value with the locale's separators
+func (w *csvWriter) format(value any) string {
+	switch v := deref(value).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case money.Money:
+		return v.FormatNumber(w.locale.Thousands, w.locale.Decimal)
+	case int:
+		return w.group(strconv.Itoa(v))
+	case int64:
+		return w.group(strconv.FormatInt(v, 10))
+	case float64:
+		return w.group(strconv.FormatFloat(v, 'f', -1, 64))
+	case quantity.Quantity:
+		return w.group(v.String())
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(TimeLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// group applies the locale's separators to a plain decimal number
+func (w *csvWriter) group(number string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(number, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(w.locale.Thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+	if hasFraction {
+		return sign + grouped.String() + w.locale.Decimal + fraction
+	}
+	return sign + grouped.String()
+}
+
+// deref returns the value a pointer points to, or nil for nil pointers
+func deref(value any) any {
+	switch v := value.(type) {
+	case *string:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *int64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *float64:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *money.Money:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *quantity.Quantity:
+		if v == nil {
+			return nil
+		}
+		return *v
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return *v
+	default:
+		return value
+	}
+}
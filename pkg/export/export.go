@@ -0,0 +1,153 @@
+// Package export writes tabular data as CSV or XLSX one row at a time, so
+// large exports are streamed to the client instead of being built in memory.
+package export
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Format is the file format of an export
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// ParseFormat returns the format for a file extension such as "csv" or ".xlsx"
+func ParseFormat(extension string) (Format, error) {
+	switch format := Format(strings.ToLower(strings.TrimPrefix(extension, "."))); format {
+	case CSV, XLSX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", extension)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Locale describes how numbers are written in CSV files. Delimiter separates
+// fields; locales that use a decimal comma separate them with semicolons, as
+// spreadsheet programs in those locales expect.
+type Locale struct {
+	Tag       string
+	Thousands string
+	Decimal   string
+	Delimiter rune
+}
+
+// DefaultLocale is used when the client asks for no known locale
+var DefaultLocale = locales["en"]
+
+var locales = map[string]Locale{
+	"en": {Tag: "en", Thousands: ",", Decimal: ".", Delimiter: ','},
+	"id": {Tag: "id", Thousands: ".", Decimal: ",", Delimiter: ';'},
+	"ms": {Tag: "ms", Thousands: ",", Decimal: ".", Delimiter: ','},
+	"ja": {Tag: "ja", Thousands: ",", Decimal: ".", Delimiter: ','},
+	"de": {Tag: "de", Thousands: ".", Decimal: ",", Delimiter: ';'},
+	"nl": {Tag: "nl", Thousands: ".", Decimal: ",", Delimiter: ';'},
+	"es": {Tag: "es", Thousands: ".", Decimal: ",", Delimiter: ';'},
+	"fr": {Tag: "fr", Thousands: " ", Decimal: ",", Delimiter: ';'},
+}
+
+// LookupLocale returns the locale of a language tag such as "id-ID" or "en"
+func LookupLocale(tag string) (Locale, bool) {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	language, _, _ = strings.Cut(language, "_")
+	locale, ok := locales[language]
+	return locale, ok
+}
+
+// NegotiateLocale returns the first known locale of an Accept-Language
+// header, or DefaultLocale
+func NegotiateLocale(acceptLanguage string) Locale {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if locale, ok := LookupLocale(tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// RequestLocale returns the locale named by the locale query parameter of a
+// request, falling back to its Accept-Language header
+func RequestLocale(r *http.Request) Locale {
+	if locale, ok := LookupLocale(r.URL.Query().Get("locale")); ok {
+		return locale
+	}
+	return NegotiateLocale(r.Header.Get("Accept-Language"))
+}
+
+// Writer writes the rows of one table. Nothing is written to the underlying
+// writer before the first row or Close, so a failure before any row can
+// still be reported as an ordinary error response.
+//
+// Row values may be nil, strings, integers, floats, booleans, money.Money,
+// time.Time and pointers to those; anything else is written with fmt.
+type Writer interface {
+	WriteHeader(columns ...string) error
+	WriteRow(values ...any) error
+	Close() error
+}
+
+// NewWriter returns a Writer of the format that writes to w. sheet names the
+// XLSX worksheet; CSV files use the locale's separators.
+func NewWriter(format Format, w io.Writer, locale Locale, sheet string) Writer {
+	if format == XLSX {
+		return newXLSXWriter(w, sheet)
+	}
+	return newCSVWriter(w, locale)
+}
+
+// Download returns a Writer that streams an export to an HTTP response as a
+// file named after name and the format, such as orders.csv. The download
+// headers are only set once the first byte is written, so a failure before
+// the first row can still be answered with an ordinary error response.
+func Download(w http.ResponseWriter, r *http.Request, format Format, name string) Writer {
+	return NewWriter(format, &downloadWriter{response: w, format: format, name: name}, RequestLocale(r), name)
+}
+
+// downloadWriter sets the download headers before the first write
+type downloadWriter struct {
+	response http.ResponseWriter
+	format   Format
+	name     string
+	started  bool
+}
+
+func (w *downloadWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		header := w.response.Header()
+		header.Set("Content-Type", w.format.ContentType())
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": w.name + "." + string(w.format)}))
+		header.Set("Cache-Control", "no-store")
+	}
+	return w.response.Write(p)
+}
+
+// PlainNumber converts a number written with the locale's separators, such
+// as "1.250,50" in id, into the plain form "1250.50" accepted by
+// money.Parse and strconv
+func (l Locale) PlainNumber(value string) string {
+	value = strings.TrimSpace(value)
+	if l.Thousands != "" {
+		value = strings.ReplaceAll(value, l.Thousands, "")
+	}
+	if l.Thousands == " " {
+		// Spreadsheets often group with a no-break space instead
+		value = strings.NewReplacer("\u00a0", "", "\u202f", "").Replace(value)
+	}
+	return strings.Replace(value, l.Decimal, ".", 1)
+}
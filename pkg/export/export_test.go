@@ -0,0 +1,220 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/export"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		extension string
+		want      export.Format
+		wantErr   bool
+	}{
+		{name: "csv", extension: "csv", want: export.CSV},
+		{name: "xlsx with dot", extension: ".xlsx", want: export.XLSX},
+		{name: "upper case", extension: "CSV", want: export.CSV},
+		{name: "unsupported", extension: "pdf", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := export.ParseFormat(tt.extension)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+	}{
+		{name: "region tag", acceptLanguage: "id-ID", want: "id"},
+		{name: "first known language", acceptLanguage: "xx-XX, de;q=0.8, en;q=0.5", want: "de"},
+		{name: "empty", acceptLanguage: "", want: "en"},
+		{name: "unknown", acceptLanguage: "xx", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, export.NegotiateLocale(tt.acceptLanguage).Tag)
+		})
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	paidAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	var nilTime *time.Time
+
+	tests := []struct {
+		name   string
+		locale string
+		rows   [][]any
+		want   string
+	}{
+		{
+			name:   "english separators",
+			locale: "en",
+			rows:   [][]any{{"OUT1-0001", money.FromMinor(1250000), 3, 1.5, &paidAt}},
+			want:   "\uFEFFnumber,total,quantity,weight,paid_at\nOUT1-0001,\"1,250,000\",3,1.5,2026-10-17 09:30:00\n",
+		},
+		{
+			name:   "indonesian separators",
+			locale: "id",
+			rows:   [][]any{{"OUT1-0001", money.FromMinor(1250000), 1200, 1.5, nilTime}},
+			want:   "\uFEFFnumber;total;quantity;weight;paid_at\nOUT1-0001;1.250.000;1.200;1,5;\n",
+		},
+		{
+			name:   "minor units",
+			locale: "id",
+			rows:   [][]any{{"A", money.New(-123456, "USD"), -5, 0.25, nil}},
+			want:   "\uFEFFnumber;total;quantity;weight;paid_at\nA;-1.234,56;-5;0,25;\n",
+		},
+		{
+			name:   "decimal quantities",
+			locale: "id",
+			rows:   [][]any{{"B", money.FromMinor(22750), quantity.Quantity(1250350), quantity.Quantity(350), nil}},
+			want:   "\uFEFFnumber;total;quantity;weight;paid_at\nB;22.750;1.250,35;0,35;\n",
+		},
+		{
+			name:   "header only",
+			locale: "en",
+			want:   "\uFEFFnumber,total,quantity,weight,paid_at\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locale, _ := export.LookupLocale(tt.locale)
+			var buf bytes.Buffer
+			writer := export.NewWriter(export.CSV, &buf, locale, "Orders")
+			assert.NoError(t, writer.WriteHeader("number", "total", "quantity", "weight", "paid_at"))
+			assert.Equal(t, 0, buf.Len(), "Nothing should be written before the first row")
+			for _, row := range tt.rows {
+				assert.NoError(t, writer.WriteRow(row...))
+			}
+			assert.NoError(t, writer.Close())
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer := export.NewWriter(export.XLSX, &buf, export.DefaultLocale, "Orders: 2026/10")
+	assert.NoError(t, writer.WriteHeader("number", "total", "note"))
+	assert.Equal(t, 0, buf.Len(), "Nothing should be written before the first row")
+
+	row := []any{"OUT1-0001", money.FromMinor(15000), "a < b & c"}
+	for i := 0; i < 25; i++ {
+		row = append(row, i)
+	}
+	assert.NoError(t, writer.WriteRow(row...))
+	assert.NoError(t, writer.WriteRow(time.Date(1900, 3, 1, 12, 0, 0, 0, time.UTC), nil, true))
+	assert.NoError(t, writer.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	files := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		files[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		assert.Contains(t, files, name)
+	}
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Orders 202610"`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" s="4" t="inlineStr"><is><t xml:space="preserve">number</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B2" s="2"><v>15000</v></c>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">a &lt; b &amp; c</t>`)
+	assert.Contains(t, sheet, `<c r="AB2" s="1"><v>24</v></c>`)
+	assert.Contains(t, sheet, `<c r="A3" s="3"><v>61.50000000</v></c>`)
+	assert.Contains(t, sheet, `<c r="C3" s="0" t="b"><v>1</v></c>`)
+	assert.NotContains(t, sheet, `r="B3"`)
+}
+
+func TestDownload(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/exports/orders.csv?locale=id", nil)
+	request.Header.Set("Accept-Language", "en-US")
+	recorder := httptest.NewRecorder()
+
+	writer := export.Download(recorder, request, export.CSV, "orders")
+	assert.NoError(t, writer.WriteHeader("number", "total"))
+	assert.Empty(t, recorder.Header().Get("Content-Disposition"), "Headers should wait for the first row")
+
+	assert.NoError(t, writer.WriteRow("OUT1-0001", money.FromMinor(15000)))
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=orders.csv`, recorder.Header().Get("Content-Disposition"))
+	assert.Equal(t, "\uFEFFnumber;total\nOUT1-0001;15.000\n", recorder.Body.String())
+}
+
+func TestPlainNumber(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		value  string
+		want   string
+	}{
+		{name: "english grouping", locale: "en", value: "1,250.50", want: "1250.50"},
+		{name: "indonesian grouping", locale: "id", value: " 1.250,50 ", want: "1250.50"},
+		{name: "indonesian whole number", locale: "id", value: "15.000", want: "15000"},
+		{name: "french no-break space", locale: "fr", value: "1\u00a0250,5", want: "1250.5"},
+		{name: "plain", locale: "en", value: "15000", want: "15000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locale, _ := export.LookupLocale(tt.locale)
+			assert.Equal(t, tt.want, locale.PlainNumber(tt.value))
+		})
+	}
+}
+
+func TestNewCSVReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [][]string
+	}{
+		{name: "comma", input: "name,price\nTea,\"1,500\"\n", want: [][]string{{"name", "price"}, {"Tea", "1,500"}}},
+		{name: "semicolon with byte order mark", input: "\uFEFFname;price\nTea;1.500,50\n", want: [][]string{{"name", "price"}, {"Tea", "1.500,50"}}},
+		{name: "header without newline", input: "name;price", want: [][]string{{"name", "price"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := export.NewCSVReader(strings.NewReader(tt.input))
+			assert.NoError(t, err)
+			records, err := reader.ReadAll()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, records)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// NewCSVReader returns a CSV reader for a file written by a spreadsheet
+// program. A leading byte order mark is skipped, and fields are split on
+// semicolons when the first line has more semicolons than commas, as in files
+// saved in locales that use a decimal comma.
+func NewCSVReader(r io.Reader) (*csv.Reader, error) {
+	buffered := bufio.NewReader(r)
+	if mark, err := buffered.Peek(len(byteOrderMark)); err == nil && string(mark) == byteOrderMark {
+		buffered.Discard(len(byteOrderMark))
+	}
+
+	// Peek at the first line without consuming it; a short file is fine
+	first, err := buffered.Peek(buffered.Size())
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	line, _, _ := strings.Cut(string(first), "\n")
+
+	reader := csv.NewReader(buffered)
+	if strings.Count(line, ";") > strings.Count(line, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader, nil
+}
@@ -0,0 +1,258 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+// Cell styles defined in xlsxStyles
+const (
+	styleDefault = iota
+	styleInteger
+	styleMoney
+	styleDateTime
+	styleHeader
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// xlsxStyles uses built-in number formats 3 (#,##0) and 4 (#,##0.00), which
+// spreadsheet programs display with the reader's own separators
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="5">
+<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+<xf numFmtId="3" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="%d" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>
+</cellXfs>
+</styleSheet>`
+
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>
+<sheetData>`
+
+const xlsxSheetEnd = `</sheetData></worksheet>`
+
+// excelEpoch is day zero of spreadsheet date serial numbers
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxWriter streams a single worksheet. Strings are written inline, so the
+// rows never have to be held in memory.
+type xlsxWriter struct {
+	out     io.Writer
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	name    string
+	header  []string
+	row     int
+	started bool
+}
+
+func newXLSXWriter(w io.Writer, name string) *xlsxWriter {
+	return &xlsxWriter{out: w, name: sheetName(name)}
+}
+
+// sheetName strips the characters worksheet names may not contain
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return "Sheet1"
+	}
+	if len([]rune(name)) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	return name
+}
+
+// WriteHeader sets the column names written as the first, frozen row
+func (w *xlsxWriter) WriteHeader(columns ...string) error {
+	w.header = columns
+	return nil
+}
+
+// start writes the workbook parts and opens the worksheet. The money style
+// shows two decimals only when the default currency has minor units.
+func (w *xlsxWriter) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	w.zip = zip.NewWriter(w.out)
+
+	moneyFormat := 3
+	if currency, ok := money.LookupCurrency(money.DefaultCurrency); ok && currency.Exponent > 0 {
+		moneyFormat = 4
+	}
+	var name strings.Builder
+	xml.EscapeText(&name, []byte(w.name))
+	parts := []struct{ path, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, name.String())},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", fmt.Sprintf(xlsxStyles, moneyFormat)},
+	}
+	for _, part := range parts {
+		file, err := w.zip.Create(part.path)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return err
+		}
+	}
+
+	file, err := w.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(file)
+	if _, err := w.sheet.WriteString(xlsxSheetStart); err != nil {
+		return err
+	}
+	if w.header != nil {
+		values := make([]any, len(w.header))
+		for i, column := range w.header {
+			values[i] = column
+		}
+		return w.writeRow(values, styleHeader)
+	}
+	return nil
+}
+
+// WriteRow writes one row of cells
+func (w *xlsxWriter) WriteRow(values ...any) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	return w.writeRow(values, styleDefault)
+}
+
+func (w *xlsxWriter) writeRow(values []any, style int) error {
+	w.row++
+	fmt.Fprintf(w.sheet, `<row r="%d">`, w.row)
+	for i, value := range values {
+		ref := columnName(i) + strconv.Itoa(w.row)
+		if err := w.writeCell(ref, value, style); err != nil {
+			return err
+		}
+	}
+	_, err := w.sheet.WriteString(`</row>`)
+	return err
+}
+
+// writeCell writes numbers and dates as values so they can be summed and
+// sorted, and everything else as an inline string
+func (w *xlsxWriter) writeCell(ref string, value any, style int) error {
+	number := func(value string, numberStyle int) error {
+		if style == styleDefault {
+			style = numberStyle
+		}
+		_, err := fmt.Fprintf(w.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, value)
+		return err
+	}
+
+	switch v := deref(value).(type) {
+	case nil:
+		return nil
+	case money.Money:
+		return number(v.Decimal(), styleMoney)
+	case int:
+		return number(strconv.Itoa(v), styleInteger)
+	case int64:
+		return number(strconv.FormatInt(v, 10), styleInteger)
+	case float64:
+		return number(strconv.FormatFloat(v, 'f', -1, 64), styleDefault)
+	case quantity.Quantity:
+		return number(v.String(), styleDefault)
+	case bool:
+		_, err := fmt.Fprintf(w.sheet, `<c r="%s" s="%d" t="b"><v>%s</v></c>`, ref, style, map[bool]string{true: "1", false: "0"}[v])
+		return err
+	case time.Time:
+		days := v.Sub(excelEpoch).Hours() / 24
+		return number(strconv.FormatFloat(days, 'f', 8, 64), styleDateTime)
+	case string:
+		return w.writeString(ref, v, style)
+	default:
+		return w.writeString(ref, fmt.Sprint(v), style)
+	}
+}
+
+func (w *xlsxWriter) writeString(ref string, value string, style int) error {
+	if _, err := fmt.Fprintf(w.sheet, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style); err != nil {
+		return err
+	}
+	if err := xml.EscapeText(w.sheet, []byte(value)); err != nil {
+		return err
+	}
+	_, err := w.sheet.WriteString(`</t></is></c>`)
+	return err
+}
+
+// Close finishes the worksheet and the zip archive
+func (w *xlsxWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := w.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zip.Close()
+}
+
+// columnName returns the letters of a zero-based column index: A, B, ..., Z, AA
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
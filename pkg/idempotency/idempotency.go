@@ -0,0 +1,64 @@
+// Package idempotency remembers the responses of requests sent with an
+// Idempotency-Key header so that retries are answered from storage instead
+// of being processed twice.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
+
+const (
+	// Header is the request header carrying the client's key
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks responses served from storage
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLength is the longest key accepted
+	MaxKeyLength = 255
+	// MaxBodySize is the largest request body, in bytes, read to fingerprint
+	// a request. It leaves room for a 5 MB product import sent as multipart.
+	MaxBodySize = 6 << 20
+
+	// TTL is how long a completed response is kept for retries
+	TTL = 24 * time.Hour
+	// StaleAfter is how long a key may stay claimed without a response before
+	// another request may take it over, such as after a crash
+	StaleAfter = 5 * time.Minute
+)
+
+// Record is a request stored under a key. StatusCode is zero while the first
+// request with the key is still being processed.
+type Record struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// InProgress reports whether the first request with the key has not finished yet
+func (r *Record) InProgress() bool {
+	return r.StatusCode == 0
+}
+
+// Store persists keys and responses per user
+type Store interface {
+	// Claim reserves a key for a request. It returns nil when the key is new
+	// or expired and the request should be processed, and the stored record
+	// when the key is already taken.
+	Claim(userID int, key string, requestHash string) (*Record, *customerror.CustomError)
+	// Complete stores the response of a claimed request
+	Complete(userID int, key string, statusCode int, contentType string, body []byte) *customerror.CustomError
+	// Release forgets a claimed key so the request can be retried
+	Release(userID int, key string) *customerror.CustomError
+}
+
+// Fingerprint identifies a request by its method, path and body
+func Fingerprint(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
+
+// PostgresStore implements Store using the idempotency_keys table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a new PostgresStore instance
+func NewPostgresStore(db *sql.DB) Store {
+	return &PostgresStore{db: db}
+}
+
+// Claim reserves a key, taking over keys that expired or were abandoned
+// without a response
+func (s *PostgresStore) Claim(userID int, key string, requestHash string) (*Record, *customerror.CustomError) {
+	now := time.Now()
+	query := `
+        INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (user_id, idempotency_key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = NULL,
+            response_body = NULL, created_at = EXCLUDED.created_at
+        WHERE idempotency_keys.created_at < $5
+           OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at < $6)
+        RETURNING user_id
+    `
+	var claimedBy int
+	err := s.db.QueryRow(query, userID, key, requestHash, now, now.Add(-TTL), now.Add(-StaleAfter)).Scan(&claimedBy)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		fmt.Printf("PostgresStore.Claim: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	var record Record
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	err = s.db.QueryRow(`
+        SELECT request_hash, status_code, content_type, response_body
+        FROM idempotency_keys
+        WHERE user_id = $1 AND idempotency_key = $2
+    `, userID, key).Scan(&record.RequestHash, &statusCode, &contentType, &record.Body)
+	if err != nil {
+		fmt.Printf("PostgresStore.Claim: Error loading stored record: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	record.StatusCode = int(statusCode.Int64)
+	record.ContentType = contentType.String
+	return &record, nil
+}
+
+// Complete stores the response of a claimed request
+func (s *PostgresStore) Complete(userID int, key string, statusCode int, contentType string, body []byte) *customerror.CustomError {
+	query := `
+        UPDATE idempotency_keys
+        SET status_code = $3, content_type = $4, response_body = $5
+        WHERE user_id = $1 AND idempotency_key = $2
+    `
+	if _, err := s.db.Exec(query, userID, key, statusCode, contentType, body); err != nil {
+		fmt.Printf("PostgresStore.Complete: Database error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// Release forgets a claimed key
+func (s *PostgresStore) Release(userID int, key string) *customerror.CustomError {
+	if _, err := s.db.Exec(`DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`, userID, key); err != nil {
+		fmt.Printf("PostgresStore.Release: Database error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	return nil
+}
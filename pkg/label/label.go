@@ -0,0 +1,195 @@
+// Package label lays out shelf and price labels with a product name, a price
+// and a barcode, and renders them as PNG or SVG images, A4 PDF sheets or ZPL
+// for Zebra printers.
+package label
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/yantology/simple-pos/pkg/barcode"
+)
+
+// Format is the output format of rendered labels
+type Format string
+
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+	FormatPDF Format = "pdf"
+	FormatZPL Format = "zpl"
+)
+
+// A4 page size and the unprintable margin kept free on every side, in millimetres
+const (
+	A4Width     = 210.0
+	A4Height    = 297.0
+	SheetMargin = 5.0
+)
+
+// Label is the content of one label. Data is encoded with Symbology and
+// printed under the bars.
+type Label struct {
+	Name      string
+	Price     string
+	Symbology barcode.Symbology
+	Data      string
+}
+
+// Size is the size of one label in millimetres
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+// Grid places labels on A4 sheets in Columns by Rows with Gap millimetres
+// between neighbouring labels. The grid is centred on the page.
+type Grid struct {
+	Columns int
+	Rows    int
+	Gap     float64
+}
+
+// FitGrid returns the largest grid of labels of the given size that fits on
+// an A4 sheet inside its margins
+func FitGrid(size Size, gap float64) Grid {
+	fit := func(page float64, label float64) int {
+		return int(math.Floor((page - 2*SheetMargin + gap) / (label + gap)))
+	}
+	return Grid{Columns: fit(A4Width, size.Width), Rows: fit(A4Height, size.Height), Gap: gap}
+}
+
+// Validate checks that the grid holds at least one label and fits on an A4
+// sheet inside its margins
+func (g Grid) Validate(size Size) error {
+	if g.Columns < 1 || g.Rows < 1 {
+		return errors.New("a sheet needs at least one column and one row of labels")
+	}
+	width := float64(g.Columns)*size.Width + float64(g.Columns-1)*g.Gap
+	height := float64(g.Rows)*size.Height + float64(g.Rows-1)*g.Gap
+	if width > A4Width-2*SheetMargin || height > A4Height-2*SheetMargin {
+		return fmt.Errorf("%d by %d labels of %gx%g mm do not fit on an A4 sheet", g.Columns, g.Rows, size.Width, size.Height)
+	}
+	return nil
+}
+
+// pattern encodes the label's data with its symbology
+func (l Label) pattern() (barcode.Pattern, int, error) {
+	switch l.Symbology {
+	case barcode.SymbologyEAN13:
+		pattern, err := barcode.EncodeEAN13(l.Data)
+		return pattern, barcode.QuietEAN13, err
+	case barcode.SymbologyCode128:
+		pattern, err := barcode.EncodeCode128(l.Data)
+		return pattern, barcode.QuietCode128, err
+	}
+	return nil, 0, fmt.Errorf("unsupported symbology: %s", l.Symbology)
+}
+
+// text is a line of text. Y is the baseline and Size the font size, both in
+// millimetres from the label's top left corner.
+type text struct {
+	X, Y  float64
+	Size  float64
+	Bold  bool
+	Value string
+}
+
+// bars is a barcode placed on a label. X is the left edge of the first
+// module, after the quiet zone; Module is the ideal module width.
+type bars struct {
+	X, Y    float64
+	Height  float64
+	Module  float64
+	Width   float64 // space available for the modules and both quiet zones
+	Quiet   int
+	Pattern barcode.Pattern
+}
+
+// drawing is a label laid out in millimetres
+type drawing struct {
+	Size  Size
+	Texts []text
+	Bars  bars
+}
+
+// charWidth is the approximate width of a character relative to its font
+// size, used to shorten names that do not fit
+const charWidth = 0.6
+
+// layout places the name at the top, the price below it in bold and the
+// barcode with its human-readable data at the bottom
+func layout(l Label, size Size) (*drawing, error) {
+	pattern, quiet, err := l.pattern()
+	if err != nil {
+		return nil, err
+	}
+
+	pad := math.Max(1.5, math.Min(size.Width, size.Height)*0.05)
+	nameSize := clamp(size.Height*0.11, 2.2, 5)
+	priceSize := clamp(size.Height*0.2, 3.5, 10)
+	dataSize := clamp(size.Height*0.08, 1.8, 3.5)
+	inner := size.Width - 2*pad
+
+	d := &drawing{Size: size}
+	nameY := pad + nameSize*0.8
+	priceY := nameY + priceSize*0.9 + 0.8
+	d.Texts = append(d.Texts,
+		text{X: pad, Y: nameY, Size: nameSize, Value: fit(l.Name, inner, nameSize)},
+		text{X: pad, Y: priceY, Size: priceSize, Bold: true, Value: fit(l.Price, inner, priceSize)},
+	)
+
+	top := priceY + 1.5
+	bottom := size.Height - pad - dataSize - 0.5
+	if bottom-top < 4 {
+		return nil, fmt.Errorf("a %gx%g mm label is too small for a barcode", size.Width, size.Height)
+	}
+	module := inner / float64(len(pattern)+2*quiet)
+	d.Bars = bars{
+		X:       pad + float64(quiet)*module,
+		Y:       top,
+		Height:  bottom - top,
+		Module:  module,
+		Width:   inner,
+		Quiet:   quiet,
+		Pattern: pattern,
+	}
+	d.Texts = append(d.Texts, text{X: d.Bars.X, Y: size.Height - pad, Size: dataSize, Value: fit(l.Data, inner, dataSize)})
+	return d, nil
+}
+
+// runs returns the start and length in modules of every bar of the pattern,
+// merging adjacent dark modules
+func (b bars) runs() [][2]int {
+	var runs [][2]int
+	for i := 0; i < len(b.Pattern); i++ {
+		if !b.Pattern[i] {
+			continue
+		}
+		start := i
+		for i < len(b.Pattern) && b.Pattern[i] {
+			i++
+		}
+		runs = append(runs, [2]int{start, i - start})
+	}
+	return runs
+}
+
+// fit shortens value so it fits in width millimetres at the font size
+func fit(value string, width float64, size float64) string {
+	limit := int(width / (size * charWidth))
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	if limit <= 3 {
+		return string(runes[:max(limit, 0)])
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
+// clamp limits value to the range from low to high
+func clamp(value float64, low float64, high float64) float64 {
+	return math.Min(math.Max(value, low), high)
+}
@@ -0,0 +1,88 @@
+package label_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/barcode"
+	"github.com/yantology/simple-pos/pkg/label"
+)
+
+var (
+	shelfSize = label.Size{Width: 50, Height: 30}
+	coffee    = label.Label{Name: "Kopi Bubuk Gayo 250g", Price: "Rp65.000", Symbology: barcode.SymbologyEAN13, Data: "4006381333931"}
+	croissant = label.Label{Name: "Croissant (butter)", Price: "Rp15.000", Symbology: barcode.SymbologyCode128, Data: "BAK-CRS_01"}
+)
+
+func TestPNG(t *testing.T) {
+	data, err := label.PNG(coffee, shelfSize, 203)
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, img.Bounds().Dx(), "50 mm at 8 dots per mm")
+	assert.Equal(t, 240, img.Bounds().Dy(), "30 mm at 8 dots per mm")
+
+	_, err = label.PNG(coffee, label.Size{Width: 10, Height: 30}, 72)
+	assert.Error(t, err, "bars narrower than a pixel")
+}
+
+func TestSVG(t *testing.T) {
+	data, err := label.SVG(croissant, shelfSize)
+	assert.NoError(t, err)
+	svg := string(data)
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, `width="50mm"`)
+	assert.Contains(t, svg, ">Croissant (butter)</text>")
+	assert.Contains(t, svg, ">Rp15.000</text>")
+	assert.Contains(t, svg, ">BAK-CRS_01</text>")
+
+	_, err = label.SVG(label.Label{Name: "Bad", Symbology: barcode.SymbologyEAN13, Data: "4006381333932"}, shelfSize)
+	assert.Error(t, err, "invalid check digit")
+	_, err = label.SVG(coffee, label.Size{Width: 50, Height: 12})
+	assert.Error(t, err, "no room for the barcode")
+}
+
+func TestPDF(t *testing.T) {
+	grid := label.FitGrid(shelfSize, 2)
+	assert.Equal(t, label.Grid{Columns: 3, Rows: 9, Gap: 2}, grid)
+
+	labels := make([]label.Label, 30)
+	for i := range labels {
+		labels[i] = coffee
+	}
+	data, err := label.PDF(labels, shelfSize, grid)
+	assert.NoError(t, err)
+	pdf := string(data)
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	assert.Contains(t, pdf, "/Count 2", "30 labels need two sheets of 27")
+
+	data, err = label.PDF([]label.Label{croissant}, shelfSize, grid)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "(Croissant \\(butter\\)) Tj", "parentheses are escaped")
+
+	cafe := croissant
+	cafe.Name = "Caf\u00e9 \u2013 \u4e2d"
+	data, err = label.PDF([]label.Label{cafe}, shelfSize, grid)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "(Caf\\351 \\226 ?) Tj", "Windows-1252 characters use their code")
+
+	_, err = label.PDF(labels, shelfSize, label.Grid{Columns: 5, Rows: 9})
+	assert.Error(t, err, "five 50 mm columns do not fit on A4")
+}
+
+func TestZPL(t *testing.T) {
+	data, err := label.ZPL([]label.Label{coffee, croissant}, shelfSize, 203)
+	assert.NoError(t, err)
+	zpl := string(data)
+	assert.Equal(t, 2, strings.Count(zpl, "^XA"))
+	assert.Contains(t, zpl, "^PW400^LL240")
+	assert.Contains(t, zpl, "^BEN,")
+	assert.Contains(t, zpl, "^FD400638133393^FS", "the printer adds the check digit")
+	assert.Contains(t, zpl, "^BCN,")
+	assert.Contains(t, zpl, "^FDBAK-CRS_5F01^FS", "underscores are escaped for ^FH")
+}
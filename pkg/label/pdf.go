@@ -0,0 +1,127 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// pointsPerMM converts millimetres to PDF points
+const pointsPerMM = 72 / 25.4
+
+// PDF renders labels on as many A4 sheets as needed, filling each grid row
+// by row. Text uses the standard Helvetica fonts in WinAnsiEncoding, so
+// characters outside Windows-1252 are printed as '?'.
+func PDF(labels []Label, size Size, grid Grid) ([]byte, error) {
+	if err := grid.Validate(size); err != nil {
+		return nil, err
+	}
+	drawings := make([]*drawing, len(labels))
+	for i, l := range labels {
+		d, err := layout(l, size)
+		if err != nil {
+			return nil, fmt.Errorf("label %d: %w", i+1, err)
+		}
+		drawings[i] = d
+	}
+
+	perPage := grid.Columns * grid.Rows
+	left := (A4Width - float64(grid.Columns)*size.Width - float64(grid.Columns-1)*grid.Gap) / 2
+	top := (A4Height - float64(grid.Rows)*size.Height - float64(grid.Rows-1)*grid.Gap) / 2
+
+	var pages []string
+	for start := 0; start < len(drawings); start += perPage {
+		var content strings.Builder
+		for i, d := range drawings[start:min(start+perPage, len(drawings))] {
+			x := left + float64(i%grid.Columns)*(size.Width+grid.Gap)
+			y := top + float64(i/grid.Columns)*(size.Height+grid.Gap)
+			writeLabel(&content, d, x, y)
+		}
+		pages = append(pages, content.String())
+	}
+	if len(pages) == 0 {
+		pages = append(pages, "")
+	}
+	return writePDF(pages), nil
+}
+
+// writeLabel adds the drawing of one label with its top left corner at x, y
+// millimetres from the top left of the page
+func writeLabel(content *strings.Builder, d *drawing, x float64, y float64) {
+	// PDF measures from the bottom left in points
+	point := func(mm float64) string { return num(mm * pointsPerMM) }
+	fromBottom := func(mm float64) string { return num((A4Height - mm) * pointsPerMM) }
+
+	content.WriteString("0 g\n")
+	for _, run := range d.Bars.runs() {
+		barX := x + d.Bars.X + float64(run[0])*d.Bars.Module
+		fmt.Fprintf(content, "%s %s %s %s re f\n",
+			point(barX), fromBottom(y+d.Bars.Y+d.Bars.Height), point(float64(run[1])*d.Bars.Module), point(d.Bars.Height))
+	}
+	for _, t := range d.Texts {
+		font := "F1"
+		if t.Bold {
+			font = "F2"
+		}
+		fmt.Fprintf(content, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, point(t.Size), point(x+t.X), fromBottom(y+t.Y), pdfString(t.Value))
+	}
+}
+
+// writePDF assembles a PDF document with one A4 page per content stream
+func writePDF(pages []string) []byte {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	// Objects 1 to 4 are the catalog, the page tree and the two fonts; each
+	// page is followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(A4Width*pointsPerMM), num(A4Height*pointsPerMM), 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+// pdfString escapes text for a PDF string literal. Characters beyond ASCII
+// are written as octal escapes of their WinAnsiEncoding code.
+func pdfString(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= ' ' && r <= '~':
+			b.WriteRune(r)
+		default:
+			if code, ok := charmap.Windows1252.EncodeRune(r); ok && code > '~' {
+				fmt.Fprintf(&b, "\\%03o", code)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
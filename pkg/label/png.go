@@ -0,0 +1,84 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// PNG renders one label as a greyscale PNG image at the given resolution.
+// Bars are snapped to whole pixels so every module has the same width.
+func PNG(l Label, size Size, dpi int) ([]byte, error) {
+	d, err := layout(l, size)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := float64(dpi) / 25.4
+	img := image.NewGray(image.Rect(0, 0, px(size.Width, scale), px(size.Height, scale)))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	module := int(math.Floor(d.Bars.Width * scale / float64(len(d.Bars.Pattern)+2*d.Bars.Quiet)))
+	if module < 1 {
+		return nil, fmt.Errorf("a %g mm wide label is too narrow for this barcode at %d dpi", size.Width, dpi)
+	}
+	x := px(d.Bars.X-float64(d.Bars.Quiet)*d.Bars.Module, scale) + d.Bars.Quiet*module
+	y := px(d.Bars.Y, scale)
+	height := px(d.Bars.Height, scale)
+	for _, run := range d.Bars.runs() {
+		bar := image.Rect(x+run[0]*module, y, x+(run[0]+run[1])*module, y+height)
+		draw.Draw(img, bar, image.Black, image.Point{}, draw.Src)
+	}
+
+	for _, t := range d.Texts {
+		drawText(img, t, scale)
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// drawText draws a line of text with the built-in bitmap font, enlarged by
+// a whole factor to approach the font size. Bold text is drawn twice, one
+// pixel apart.
+func drawText(img *image.Gray, t text, scale float64) {
+	face := basicfont.Face7x13
+	factor := max(1, int(math.Round(t.Size*scale/float64(face.Height))))
+
+	mask := image.NewAlpha(image.Rect(0, 0, len(t.Value)*face.Advance+1, face.Height))
+	drawer := font.Drawer{Dst: mask, Src: image.Opaque, Face: face}
+	drawer.Dot = fixed.P(0, face.Ascent)
+	drawer.DrawString(t.Value)
+	if t.Bold {
+		drawer.Dot = fixed.P(1, face.Ascent)
+		drawer.DrawString(t.Value)
+	}
+
+	left := px(t.X, scale)
+	top := px(t.Y, scale) - face.Ascent*factor
+	for my := 0; my < mask.Bounds().Dy(); my++ {
+		for mx := 0; mx < mask.Bounds().Dx(); mx++ {
+			if mask.AlphaAt(mx, my).A < 0x80 {
+				continue
+			}
+			cell := image.Rect(left+mx*factor, top+my*factor, left+(mx+1)*factor, top+(my+1)*factor)
+			draw.Draw(img, cell, &image.Uniform{C: color.Black}, image.Point{}, draw.Src)
+		}
+	}
+}
+
+// px converts millimetres to whole pixels
+func px(mm float64, scale float64) int {
+	return int(math.Round(mm * scale))
+}
@@ -0,0 +1,47 @@
+package label
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// SVG renders one label as an SVG image measured in millimetres
+func SVG(l Label, size Size) ([]byte, error) {
+	d, err := layout(l, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %s %s">`+"\n",
+		num(size.Width), num(size.Height), num(size.Width), num(size.Height))
+	fmt.Fprintf(&b, `<rect width="%s" height="%s" fill="#fff"/>`+"\n", num(size.Width), num(size.Height))
+
+	for _, run := range d.Bars.runs() {
+		fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="#000"/>`+"\n",
+			num(d.Bars.X+float64(run[0])*d.Bars.Module), num(d.Bars.Y), num(float64(run[1])*d.Bars.Module), num(d.Bars.Height))
+	}
+
+	for _, t := range d.Texts {
+		weight := "normal"
+		if t.Bold {
+			weight = "bold"
+		}
+		fmt.Fprintf(&b, `<text x="%s" y="%s" font-family="Helvetica, Arial, sans-serif" font-size="%s" font-weight="%s">`,
+			num(t.X), num(t.Y), num(t.Size), weight)
+		if err := xml.EscapeText(&b, []byte(t.Value)); err != nil {
+			return nil, err
+		}
+		b.WriteString("</text>\n")
+	}
+	b.WriteString("</svg>\n")
+	return b.Bytes(), nil
+}
+
+// num formats a length rounded to a thousandth of a millimetre
+func num(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
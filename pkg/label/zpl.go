@@ -0,0 +1,58 @@
+package label
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/yantology/simple-pos/pkg/barcode"
+)
+
+// ZPL renders labels as ZPL II for Zebra printers of the given resolution,
+// one format per label. Barcodes use the printer's own EAN-13 and Code 128
+// commands so bars are drawn at the printer's resolution.
+func ZPL(labels []Label, size Size, dpi int) ([]byte, error) {
+	dots := float64(dpi) / 25.4
+	dot := func(mm float64) int { return int(math.Round(mm * dots)) }
+
+	var b bytes.Buffer
+	for i, l := range labels {
+		d, err := layout(l, size)
+		if err != nil {
+			return nil, fmt.Errorf("label %d: %w", i+1, err)
+		}
+
+		// ^CI28 reads field data as UTF-8
+		fmt.Fprintf(&b, "^XA^CI28^PW%d^LL%d\n", dot(size.Width), dot(size.Height))
+		for _, t := range d.Texts {
+			height := dot(t.Size)
+			width := height * 4 / 5
+			if t.Bold {
+				width = height
+			}
+			fmt.Fprintf(&b, "^FO%d,%d^A0N,%d,%d^FH^FD%s^FS\n", dot(t.X), dot(t.Y-t.Size*0.8), height, width, zplField(t.Value))
+		}
+
+		module := int(math.Floor(d.Bars.Module * dots))
+		if module < 1 {
+			return nil, fmt.Errorf("label %d: a %g mm wide label is too narrow for this barcode at %d dpi", i+1, size.Width, dpi)
+		}
+		fmt.Fprintf(&b, "^BY%d^FO%d,%d", module, dot(d.Bars.X), dot(d.Bars.Y))
+		if l.Symbology == barcode.SymbologyEAN13 {
+			// The printer adds the check digit to the first twelve digits
+			code, _, _ := barcode.Normalize(l.Data)
+			fmt.Fprintf(&b, "^BEN,%d,N,N^FD%s^FS\n", dot(d.Bars.Height), code[:12])
+		} else {
+			fmt.Fprintf(&b, "^BCN,%d,N,N,N,A^FH^FD%s^FS\n", dot(d.Bars.Height), zplField(l.Data))
+		}
+		b.WriteString("^XZ\n")
+	}
+	return b.Bytes(), nil
+}
+
+// zplField escapes the characters that start ZPL commands, and the escape
+// character itself, as hexadecimal for fields preceded by ^FH
+func zplField(value string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(value)
+}
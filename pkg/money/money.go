@@ -0,0 +1,325 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of amounts read from the database and of
+// bare JSON numbers
+const DefaultCurrency = "IDR"
+
+// Currency describes how amounts in a currency are stored and displayed
+type Currency struct {
+	Code string
+	// Exponent is the number of minor-unit digits, 0 for IDR and 2 for USD
+	Exponent  int
+	Symbol    string
+	Thousands string
+	Decimal   string
+}
+
+var currencies = map[string]Currency{
+	"IDR": {Code: "IDR", Exponent: 0, Symbol: "Rp", Thousands: ".", Decimal: ","},
+	"USD": {Code: "USD", Exponent: 2, Symbol: "$", Thousands: ",", Decimal: "."},
+	"SGD": {Code: "SGD", Exponent: 2, Symbol: "S$", Thousands: ",", Decimal: "."},
+	"MYR": {Code: "MYR", Exponent: 2, Symbol: "RM", Thousands: ",", Decimal: "."},
+	"EUR": {Code: "EUR", Exponent: 2, Symbol: "€", Thousands: ".", Decimal: ","},
+	"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥", Thousands: ",", Decimal: "."},
+}
+
+// LookupCurrency returns the currency with the given ISO 4217 code
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := currencies[strings.ToUpper(code)]
+	return currency, ok
+}
+
+// Money is an amount in integer minor units of a currency. The zero value is
+// zero in the default currency.
+type Money struct {
+	Amount   int64  `json:"amount" example:"15000"`
+	Currency string `json:"currency" example:"IDR"`
+}
+
+// New returns an amount of minor units in the given currency
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// FromMinor returns an amount of minor units in the default currency
+func FromMinor(amount int64) Money {
+	return Money{Amount: amount, Currency: DefaultCurrency}
+}
+
+// Parse parses a decimal amount in major units, such as "15000" or "12.50",
+// into the given currency. More decimals than the currency has are rejected.
+func Parse(value string, currency string) (Money, error) {
+	info, ok := LookupCurrency(currency)
+	if !ok {
+		return Money{}, fmt.Errorf("unsupported currency: %s", currency)
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if whole == "" || len(fraction) > info.Exponent {
+		return Money{}, fmt.Errorf("invalid %s amount %q", info.Code, value)
+	}
+	fraction += strings.Repeat("0", info.Exponent-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid %s amount %q", info.Code, value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return New(amount, info.Code), nil
+}
+
+// currency returns the code of m, falling back to the default currency
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+// info returns the currency details of m. Unknown currencies are shown with
+// their code and no minor units.
+func (m Money) info() Currency {
+	if info, ok := LookupCurrency(m.currency()); ok {
+		return info
+	}
+	return Currency{Code: m.currency(), Symbol: m.currency() + " ", Thousands: ",", Decimal: "."}
+}
+
+// mustMatch panics when two amounts are in different currencies. Mixing
+// currencies is a programming error; amounts are never converted implicitly.
+func (m Money) mustMatch(other Money) {
+	if m.currency() != other.currency() {
+		panic(fmt.Sprintf("money: currency mismatch %s and %s", m.currency(), other.currency()))
+	}
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return New(m.Amount+other.Amount, m.currency())
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return New(m.Amount-other.Amount, m.currency())
+}
+
+// Mul returns m multiplied by a whole quantity
+func (m Money) Mul(quantity int64) Money {
+	return New(m.Amount*quantity, m.currency())
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return New(-m.Amount, m.currency())
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Decimal returns the amount in major units without grouping, such as
+// "15000" for IDR or "12.50" for USD
+func (m Money) Decimal() string {
+	return m.FormatNumber("", ".")
+}
+
+// FormatNumber returns the amount in major units with the given thousands and
+// decimal separators
+func (m Money) FormatNumber(thousands string, decimal string) string {
+	info := m.info()
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if len(digits) <= info.Exponent {
+		digits = strings.Repeat("0", info.Exponent-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-info.Exponent], digits[len(digits)-info.Exponent:]
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if fraction == "" {
+		return sign + grouped.String()
+	}
+	return sign + grouped.String() + decimal + fraction
+}
+
+// Number returns the amount with the currency's separators but without its
+// symbol, such as "15.000" for IDR
+func (m Money) Number() string {
+	info := m.info()
+	return m.FormatNumber(info.Thousands, info.Decimal)
+}
+
+// Format returns the amount with the currency's symbol and separators,
+// such as "Rp15.000" or "$12.50"
+func (m Money) Format() string {
+	info := m.info()
+	formatted := m.Number()
+	if strings.HasPrefix(formatted, "-") {
+		return "-" + info.Symbol + formatted[1:]
+	}
+	return info.Symbol + formatted
+}
+
+// String implements fmt.Stringer
+func (m Money) String() string {
+	return m.Format()
+}
+
+// MarshalJSON encodes the amount as {"amount": minor units, "currency": code}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   int64  `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Amount, m.currency()})
+}
+
+// UnmarshalJSON accepts either {"amount": minor units, "currency": code} or
+// a bare number in major units of the default currency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		return fmt.Errorf("money: expected an object or a number, got %s", data)
+	}
+	if len(data) > 0 && data[0] != '{' {
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return fmt.Errorf("money: expected an object or a number: %w", err)
+		}
+		parsed, err := Parse(number.String(), DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	var raw struct {
+		Amount   int64  `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("money: %w", err)
+	}
+	if raw.Currency == "" {
+		raw.Currency = DefaultCurrency
+	}
+	if _, ok := LookupCurrency(raw.Currency); !ok {
+		return fmt.Errorf("unsupported currency: %s", raw.Currency)
+	}
+	*m = New(raw.Amount, raw.Currency)
+	return nil
+}
+
+// Scan implements sql.Scanner. Columns hold minor units; the currency is kept
+// when already set and is the default currency otherwise.
+func (m *Money) Scan(src any) error {
+	var amount int64
+	switch v := src.(type) {
+	case nil:
+		amount = 0
+	case int64:
+		amount = v
+	case float64:
+		amount = int64(math.Round(v))
+	case []byte:
+		parsed, err := parseMinor(string(v))
+		if err != nil {
+			return err
+		}
+		amount = parsed
+	case string:
+		parsed, err := parseMinor(v)
+		if err != nil {
+			return err
+		}
+		amount = parsed
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+
+	m.Amount = amount
+	m.Currency = m.currency()
+	return nil
+}
+
+// parseMinor parses a database numeric holding minor units, such as the
+// result of SUM over a BIGINT column
+func parseMinor(value string) (int64, error) {
+	whole, fraction, _ := strings.Cut(value, ".")
+	if strings.Trim(fraction, "0") != "" {
+		return 0, fmt.Errorf("money: %q is not a whole number of minor units", value)
+	}
+	amount, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: cannot scan %q: %w", value, err)
+	}
+	return amount, nil
+}
+
+// Value implements driver.Valuer, storing the amount in minor units
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount, nil
+}
+
+// BindingTag is the validator tag of request fields holding money. It has no
+// rule of its own; it makes sure ValidateAmount runs on fields that have no
+// other rule.
+const BindingTag = "money"
+
+// ValidateAmount lets validator rules such as gt=0 check the minor-unit
+// amount. Amounts are stored without their currency, so amounts in another
+// currency than DefaultCurrency read as missing and fail every rule on the
+// field. Register it with validator's RegisterCustomTypeFunc for Money.
+func ValidateAmount(field reflect.Value) any {
+	if m, ok := field.Interface().(Money); ok && m.currency() == DefaultCurrency {
+		return m.Amount
+	}
+	return nil
+}
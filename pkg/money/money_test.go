@@ -0,0 +1,130 @@
+package money_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/money"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     money.Money
+		wantErr  bool
+	}{
+		{name: "rupiah", value: "15000", currency: "IDR", want: money.New(15000, "IDR")},
+		{name: "rupiah with zero decimals", value: "15000.00", currency: "IDR", want: money.New(15000, "IDR")},
+		{name: "rupiah with sen", value: "15000.50", currency: "IDR", wantErr: true},
+		{name: "dollars and cents", value: "12.5", currency: "USD", want: money.New(1250, "USD")},
+		{name: "negative", value: "-3.25", currency: "USD", want: money.New(-325, "USD")},
+		{name: "not a number", value: "abc", currency: "IDR", wantErr: true},
+		{name: "unknown currency", value: "1", currency: "XYZ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := money.Parse(tt.value, tt.currency)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		money money.Money
+		want  string
+	}{
+		{name: "rupiah", money: money.FromMinor(1500000), want: "Rp1.500.000"},
+		{name: "small rupiah", money: money.FromMinor(500), want: "Rp500"},
+		{name: "negative rupiah", money: money.FromMinor(-2500), want: "-Rp2.500"},
+		{name: "dollars", money: money.New(123456, "USD"), want: "$1,234.56"},
+		{name: "cents only", money: money.New(5, "USD"), want: "$0.05"},
+		{name: "zero value", money: money.Money{}, want: "Rp0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.money.Format())
+		})
+	}
+
+	assert.Equal(t, "1234.56", money.New(123456, "USD").Decimal())
+	assert.Equal(t, "1.500.000", money.FromMinor(1500000).Number())
+	assert.Equal(t, "1 234,56", money.New(123456, "USD").FormatNumber(" ", ","))
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(money.FromMinor(15000))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount":15000,"currency":"IDR"}`, string(data))
+
+	var fromObject money.Money
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount":1250,"currency":"usd"}`), &fromObject))
+	assert.Equal(t, money.New(1250, "USD"), fromObject)
+
+	var fromNumber money.Money
+	assert.NoError(t, json.Unmarshal([]byte(`25000`), &fromNumber))
+	assert.Equal(t, money.FromMinor(25000), fromNumber)
+
+	var invalid money.Money
+	assert.Error(t, json.Unmarshal([]byte(`{"amount":1,"currency":"XYZ"}`), &invalid))
+	assert.Error(t, json.Unmarshal([]byte(`"15000"`), &invalid))
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    money.Money
+		wantErr bool
+	}{
+		{name: "bigint", src: int64(15000), want: money.FromMinor(15000)},
+		{name: "numeric sum", src: []byte("42000"), want: money.FromMinor(42000)},
+		{name: "numeric with zero decimals", src: []byte("42000.00"), want: money.FromMinor(42000)},
+		{name: "numeric with fraction", src: []byte("1.5"), wantErr: true},
+		{name: "null", src: nil, want: money.FromMinor(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got money.Money
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	value, err := money.FromMinor(15000).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15000), value)
+}
+
+func TestArithmetic(t *testing.T) {
+	price := money.FromMinor(12500)
+	assert.Equal(t, money.FromMinor(37500), price.Mul(3))
+	assert.Equal(t, money.FromMinor(15000), price.Add(money.FromMinor(2500)))
+	assert.Equal(t, money.FromMinor(10000), price.Sub(money.FromMinor(2500)))
+	assert.Equal(t, money.FromMinor(-12500), price.Neg())
+	assert.Panics(t, func() { price.Add(money.New(100, "USD")) })
+}
+
+func TestValidateAmount(t *testing.T) {
+	assert.Equal(t, int64(1250), money.ValidateAmount(reflect.ValueOf(money.FromMinor(1250))))
+	assert.Equal(t, int64(0), money.ValidateAmount(reflect.ValueOf(money.Money{})))
+	assert.Nil(t, money.ValidateAmount(reflect.ValueOf(money.New(1250, "USD"))), "amounts in another currency cannot be stored")
+}
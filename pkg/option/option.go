@@ -0,0 +1,148 @@
+// Package option validates the choices made from option groups, such as
+// "Size: S/M/L" or "Ice: less/normal", and prices them.
+package option
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/yantology/simple-pos/pkg/money"
+)
+
+// Option is one choice of a group. PriceDelta is added to the unit price
+// when it is chosen and may be negative.
+type Option struct {
+	ID          int         `json:"id" example:"1"`
+	Name        string      `json:"name" example:"Large"`
+	PriceDelta  money.Money `json:"price_delta"`
+	IsAvailable bool        `json:"is_available" example:"true"`
+}
+
+// Group is a set of options of which between MinSelect and MaxSelect must be
+// chosen. A group with MinSelect above zero is required; MaxSelect zero
+// allows every option to be chosen.
+type Group struct {
+	ID        int      `json:"id" example:"1"`
+	Name      string   `json:"name" example:"Size"`
+	Required  bool     `json:"required" example:"true"`
+	MinSelect int      `json:"min_select" example:"1"`
+	MaxSelect int      `json:"max_select" example:"1"`
+	Options   []Option `json:"options"`
+}
+
+// Choice is an option chosen for an order line, copied with its group name
+// and price so later menu changes do not alter past orders
+type Choice struct {
+	GroupID    int         `json:"group_id" example:"1"`
+	Group      string      `json:"group" example:"Size"`
+	OptionID   int         `json:"option_id" example:"2"`
+	Name       string      `json:"name" example:"Large"`
+	PriceDelta money.Money `json:"price_delta"`
+}
+
+// Limits returns the effective selection bounds of the group. Required
+// groups need at least one option and MaxSelect zero means all options.
+func (g *Group) Limits() (least int, most int) {
+	least, most = g.MinSelect, g.MaxSelect
+	if g.Required && least < 1 {
+		least = 1
+	}
+	if most == 0 {
+		most = len(g.Options)
+	}
+	return least, most
+}
+
+// Validate checks that the group is named, has uniquely named options and
+// selection bounds its options can satisfy
+func (g *Group) Validate() error {
+	if strings.TrimSpace(g.Name) == "" {
+		return errors.New("option groups need a name")
+	}
+	if len(g.Options) == 0 {
+		return fmt.Errorf("option group %s has no options", g.Name)
+	}
+	if g.MinSelect < 0 || g.MaxSelect < 0 {
+		return fmt.Errorf("option group %s cannot have negative selection limits", g.Name)
+	}
+	least, most := g.Limits()
+	if most > len(g.Options) {
+		return fmt.Errorf("option group %s allows %d choices but has only %d options", g.Name, most, len(g.Options))
+	}
+	if least > most {
+		return fmt.Errorf("option group %s needs at least %d choices but allows at most %d", g.Name, least, most)
+	}
+
+	names := make(map[string]bool, len(g.Options))
+	for _, option := range g.Options {
+		name := strings.ToLower(strings.TrimSpace(option.Name))
+		if name == "" {
+			return fmt.Errorf("every option of group %s needs a name", g.Name)
+		}
+		if names[name] {
+			return fmt.Errorf("option group %s has more than one option named %s", g.Name, option.Name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// Select resolves the chosen option IDs against the groups and checks every
+// group's selection bounds. Choices are returned in group and option order.
+func Select(groups []Group, optionIDs []int) ([]Choice, error) {
+	chosen := make(map[int]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if chosen[id] {
+			return nil, fmt.Errorf("option %d is chosen more than once", id)
+		}
+		chosen[id] = true
+	}
+
+	choices := make([]Choice, 0, len(optionIDs))
+	for _, group := range groups {
+		count := 0
+		for _, option := range group.Options {
+			if !chosen[option.ID] {
+				continue
+			}
+			if !option.IsAvailable {
+				return nil, fmt.Errorf("%s %s is not available", group.Name, option.Name)
+			}
+			delete(chosen, option.ID)
+			count++
+			choices = append(choices, Choice{
+				GroupID:    group.ID,
+				Group:      group.Name,
+				OptionID:   option.ID,
+				Name:       option.Name,
+				PriceDelta: option.PriceDelta,
+			})
+		}
+
+		least, most := group.Limits()
+		if count < least {
+			if least == 1 {
+				return nil, fmt.Errorf("%s is required", group.Name)
+			}
+			return nil, fmt.Errorf("choose at least %d of %s", least, group.Name)
+		}
+		if count > most {
+			return nil, fmt.Errorf("choose at most %d of %s", most, group.Name)
+		}
+	}
+
+	for id := range chosen {
+		return nil, fmt.Errorf("option %d does not belong to this product", id)
+	}
+	return choices, nil
+}
+
+// Total returns the sum of the choices' price deltas in the given currency
+func Total(choices []Choice, currency string) money.Money {
+	total := money.New(0, currency)
+	for _, choice := range choices {
+		total = total.Add(choice.PriceDelta)
+	}
+	return total
+}
@@ -0,0 +1,91 @@
+package option_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+)
+
+func coffeeGroups() []option.Group {
+	return []option.Group{
+		{ID: 1, Name: "Size", Required: true, MaxSelect: 1, Options: []option.Option{
+			{ID: 11, Name: "Regular", IsAvailable: true},
+			{ID: 12, Name: "Large", PriceDelta: money.New(5000, "IDR"), IsAvailable: true},
+		}},
+		{ID: 2, Name: "Ice", MaxSelect: 1, Options: []option.Option{
+			{ID: 21, Name: "Less", IsAvailable: true},
+			{ID: 22, Name: "Normal", IsAvailable: true},
+		}},
+		{ID: 3, Name: "Extras", Options: []option.Option{
+			{ID: 31, Name: "Extra shot", PriceDelta: money.New(6000, "IDR"), IsAvailable: true},
+			{ID: 32, Name: "Oat milk", PriceDelta: money.New(7000, "IDR"), IsAvailable: false},
+			{ID: 33, Name: "Vanilla", PriceDelta: money.New(4000, "IDR"), IsAvailable: true},
+		}},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name      string
+		optionIDs []int
+		want      []int
+		wantTotal int64
+		wantErr   string
+	}{
+		{name: "required only", optionIDs: []int{11}, want: []int{11}},
+		{name: "ordered by group", optionIDs: []int{33, 21, 12, 31}, want: []int{12, 21, 31, 33}, wantTotal: 15000},
+		{name: "missing required", optionIDs: []int{21}, wantErr: "Size is required"},
+		{name: "too many of single choice", optionIDs: []int{11, 12}, wantErr: "choose at most 1 of Size"},
+		{name: "unavailable option", optionIDs: []int{11, 32}, wantErr: "Extras Oat milk is not available"},
+		{name: "unknown option", optionIDs: []int{11, 99}, wantErr: "option 99 does not belong to this product"},
+		{name: "duplicate option", optionIDs: []int{11, 11}, wantErr: "option 11 is chosen more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choices, err := option.Select(coffeeGroups(), tt.optionIDs)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			ids := make([]int, len(choices))
+			for i, choice := range choices {
+				ids[i] = choice.OptionID
+			}
+			assert.Equal(t, tt.want, ids)
+			assert.Equal(t, tt.wantTotal, option.Total(choices, "IDR").Amount)
+		})
+	}
+}
+
+func TestGroupValidate(t *testing.T) {
+	options := []option.Option{{Name: "Hot"}, {Name: "Iced"}}
+
+	tests := []struct {
+		name    string
+		group   option.Group
+		wantErr bool
+	}{
+		{name: "single choice", group: option.Group{Name: "Temperature", Required: true, MaxSelect: 1, Options: options}},
+		{name: "any number", group: option.Group{Name: "Temperature", Options: options}},
+		{name: "no name", group: option.Group{Name: " ", Options: options}, wantErr: true},
+		{name: "no options", group: option.Group{Name: "Temperature"}, wantErr: true},
+		{name: "more choices than options", group: option.Group{Name: "Temperature", MaxSelect: 3, Options: options}, wantErr: true},
+		{name: "minimum above maximum", group: option.Group{Name: "Temperature", MinSelect: 2, MaxSelect: 1, Options: options}, wantErr: true},
+		{name: "duplicate names", group: option.Group{Name: "Temperature", Options: []option.Option{{Name: "Hot"}, {Name: "hot"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.group.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
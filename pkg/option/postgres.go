@@ -0,0 +1,52 @@
+package option
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+// Querier is implemented by *sql.DB and *sql.Tx
+type Querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// Load reads the option groups of the given products in display order,
+// grouped by product ID
+func Load(db Querier, productIDs []int64) (map[int][]Group, error) {
+	groups := make(map[int][]Group, len(productIDs))
+	if len(productIDs) == 0 {
+		return groups, nil
+	}
+
+	query := `
+        SELECT g.product_id, g.id, g.name, g.required, g.min_select, g.max_select,
+               o.id, o.name, o.price_delta, o.is_available
+        FROM product_option_groups g
+        JOIN product_options o ON o.group_id = g.id
+        WHERE g.product_id = ANY($1)
+        ORDER BY g.product_id, g.position, g.id, o.position, o.id
+    `
+	rows, err := db.Query(query, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID int
+		var group Group
+		var o Option
+		if err := rows.Scan(&productID, &group.ID, &group.Name, &group.Required, &group.MinSelect, &group.MaxSelect,
+			&o.ID, &o.Name, &o.PriceDelta, &o.IsAvailable); err != nil {
+			return nil, err
+		}
+		list := groups[productID]
+		if len(list) == 0 || list[len(list)-1].ID != group.ID {
+			list = append(list, group)
+		}
+		list[len(list)-1].Options = append(list[len(list)-1].Options, o)
+		groups[productID] = list
+	}
+	return groups, rows.Err()
+}
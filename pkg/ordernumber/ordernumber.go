@@ -0,0 +1,106 @@
+// Package ordernumber builds human-readable order numbers such as
+// OUT1-20261017-0042 from a configurable format.
+//
+// A format is literal text with placeholders:
+//
+//	{outlet}          the outlet code
+//	{date}            the business day as YYYYMMDD
+//	{yyyy} {yy}       the year
+//	{mm} {dd}         the month and day
+//	{seq} {seq:N}     the daily sequence, zero-padded to N digits (default 4)
+//
+// The sequence restarts every day and is kept per outlet, so a format must
+// contain the sequence, the full day and the outlet to produce unique numbers.
+package ordernumber
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultFormat produces numbers such as OUT1-20261017-0042
+	DefaultFormat = "{outlet}-{date}-{seq:4}"
+	// DefaultOutlet is the outlet code of stores that never set one
+	DefaultOutlet = "OUT1"
+
+	defaultWidth = 4
+	maxWidth     = 9
+)
+
+var placeholder = regexp.MustCompile(`\{([a-z]+)(?::(\d+))?\}`)
+
+// Validate checks that a format only uses known placeholders and identifies
+// the outlet, the day and the position in that day's sequence
+func Validate(format string) error {
+	if strings.TrimSpace(format) == "" {
+		return errors.New("order number format must not be empty")
+	}
+
+	seen := map[string]bool{}
+	for _, match := range placeholder.FindAllStringSubmatch(format, -1) {
+		name, width := match[1], match[2]
+		switch name {
+		case "outlet", "date", "yyyy", "yy", "mm", "dd":
+			if width != "" {
+				return fmt.Errorf("placeholder {%s} does not take a width", name)
+			}
+		case "seq":
+			if width != "" {
+				if n, err := strconv.Atoi(width); err != nil || n < 1 || n > maxWidth {
+					return fmt.Errorf("sequence width must be between 1 and %d", maxWidth)
+				}
+			}
+		default:
+			return fmt.Errorf("unknown placeholder {%s}", name)
+		}
+		seen[name] = true
+	}
+
+	if strings.ContainsAny(placeholder.ReplaceAllString(format, ""), "{}") {
+		return errors.New("order number format has an unclosed placeholder")
+	}
+	if !seen["seq"] {
+		return errors.New("order number format must contain {seq}")
+	}
+	if !seen["date"] && !(seen["dd"] && seen["mm"] && (seen["yy"] || seen["yyyy"])) {
+		return errors.New("order number format must contain {date} or the year, {mm} and {dd}")
+	}
+	// Each outlet counts from 1, so numbers of two outlets differ only by it
+	if !seen["outlet"] {
+		return errors.New("order number format must contain {outlet}")
+	}
+	return nil
+}
+
+// Format renders the order number of the seq-th order of an outlet on a day
+func Format(format string, outlet string, day time.Time, seq int) string {
+	return placeholder.ReplaceAllStringFunc(format, func(token string) string {
+		match := placeholder.FindStringSubmatch(token)
+		switch match[1] {
+		case "outlet":
+			return outlet
+		case "date":
+			return day.Format("20060102")
+		case "yyyy":
+			return day.Format("2006")
+		case "yy":
+			return day.Format("06")
+		case "mm":
+			return day.Format("01")
+		case "dd":
+			return day.Format("02")
+		case "seq":
+			width := defaultWidth
+			if match[2] != "" {
+				width, _ = strconv.Atoi(match[2])
+			}
+			return fmt.Sprintf("%0*d", width, seq)
+		}
+		return token
+	})
+}
@@ -0,0 +1,63 @@
+package ordernumber_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/ordernumber"
+)
+
+func TestFormat(t *testing.T) {
+	day := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format string
+		outlet string
+		seq    int
+		want   string
+	}{
+		{name: "default", format: ordernumber.DefaultFormat, outlet: "OUT1", seq: 42, want: "OUT1-20261017-0042"},
+		{name: "sequence wider than padding", format: ordernumber.DefaultFormat, outlet: "OUT1", seq: 12345, want: "OUT1-20261017-12345"},
+		{name: "custom width and date parts", format: "{outlet}/{yy}{mm}{dd}/{seq:3}", outlet: "JKT", seq: 7, want: "JKT/261017/007"},
+		{name: "unpadded sequence", format: "#{date}-{seq:1}", outlet: "OUT1", seq: 9, want: "#20261017-9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ordernumber.Format(tt.format, tt.outlet, day, tt.seq))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		wantErr bool
+	}{
+		{name: "default", format: ordernumber.DefaultFormat},
+		{name: "date parts", format: "{outlet}{yyyy}{mm}{dd}-{seq}"},
+		{name: "empty", format: " ", wantErr: true},
+		{name: "missing sequence", format: "{outlet}-{date}", wantErr: true},
+		{name: "missing day", format: "{outlet}-{seq}", wantErr: true},
+		{name: "missing month", format: "{outlet}{yy}{dd}-{seq}", wantErr: true},
+		{name: "missing outlet", format: "{date}-{seq}", wantErr: true},
+		{name: "unknown placeholder", format: "{store}-{date}-{seq}", wantErr: true},
+		{name: "width too large", format: "{date}-{seq:12}", wantErr: true},
+		{name: "width on date", format: "{date:8}-{seq}", wantErr: true},
+		{name: "unclosed placeholder", format: "{date}-{seq", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ordernumber.Validate(tt.format)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,431 @@
+package promo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Type is the kind of discount a promotion or manual discount gives
+type Type string
+
+const (
+	Percentage Type = "percentage"
+	Fixed      Type = "fixed"
+	BuyXGetY   Type = "buy_x_get_y"
+)
+
+// Scope is what a promotion applies to
+type Scope string
+
+const (
+	ScopeOrder    Scope = "order"
+	ScopeCategory Scope = "category"
+	ScopeProduct  Scope = "product"
+)
+
+// Source tells whether a discount came from a promotion or from the cashier
+type Source string
+
+const (
+	SourcePromotion Source = "promotion"
+	SourceManual    Source = "manual"
+)
+
+// OrderLevel is the line index used for discounts applied to the whole order
+const OrderLevel = -1
+
+// TimeWindow is a daily window in minutes after midnight. When End is before
+// Start the window crosses midnight.
+type TimeWindow struct {
+	Start int
+	End   int
+}
+
+// Contains reports whether the minute of the day falls inside the window
+func (w TimeWindow) Contains(minute int) bool {
+	if w.Start <= w.End {
+		return minute >= w.Start && minute < w.End
+	}
+	return minute >= w.Start || minute < w.End
+}
+
+// ParseClock parses an "HH:MM" time of day into minutes after midnight.
+// "24:00" is accepted as the end of the day.
+func ParseClock(value string) (int, error) {
+	if value == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Promotion describes an automatic discount rule
+type Promotion struct {
+	ID    int
+	Name  string
+	Type  Type
+	Scope Scope
+	// Percent is used by Percentage promotions, 10 means 10%
+	Percent float64
+	// Amount is used by Fixed promotions: per unit for category and product
+	// scope, once per order for order scope
+	Amount int64
+	// BuyQuantity and GetQuantity are used by BuyXGetY promotions
+	BuyQuantity int
+	GetQuantity int
+	// MinSpend is the order subtotal required before the promotion applies
+	MinSpend    int64
+	ProductIDs  []int
+	CategoryIDs []int
+	StartsAt    *time.Time
+	EndsAt      *time.Time
+	DaysOfWeek  []time.Weekday
+	Window      *TimeWindow
+}
+
+// Validate checks that the promotion is internally consistent
+func (p Promotion) Validate() error {
+	switch p.Scope {
+	case ScopeOrder:
+	case ScopeCategory:
+		if len(p.CategoryIDs) == 0 {
+			return errors.New("category promotions need at least one category")
+		}
+	case ScopeProduct:
+		if len(p.ProductIDs) == 0 {
+			return errors.New("product promotions need at least one product")
+		}
+	default:
+		return fmt.Errorf("unsupported promotion scope: %s", p.Scope)
+	}
+
+	switch p.Type {
+	case Percentage:
+		if p.Percent <= 0 || p.Percent > 100 {
+			return errors.New("percentage must be greater than 0 and at most 100")
+		}
+	case Fixed:
+		if p.Amount <= 0 {
+			return errors.New("fixed amount must be greater than zero")
+		}
+	case BuyXGetY:
+		if p.BuyQuantity <= 0 || p.GetQuantity <= 0 {
+			return errors.New("buy and get quantities must be greater than zero")
+		}
+	default:
+		return fmt.Errorf("unsupported promotion type: %s", p.Type)
+	}
+
+	if p.MinSpend < 0 {
+		return errors.New("minimum spend cannot be negative")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && p.EndsAt.Before(*p.StartsAt) {
+		return errors.New("promotion ends before it starts")
+	}
+	if p.Window != nil && (p.Window.Start < 0 || p.Window.Start >= 24*60 || p.Window.End < 0 || p.Window.End > 24*60) {
+		return errors.New("time window must be within a day")
+	}
+	return nil
+}
+
+// ActiveAt reports whether the promotion's date range, weekdays and daily
+// time window all include t
+func (p Promotion) ActiveAt(t time.Time) bool {
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && t.After(*p.EndsAt) {
+		return false
+	}
+	if len(p.DaysOfWeek) > 0 {
+		found := false
+		for _, day := range p.DaysOfWeek {
+			if day == t.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if p.Window != nil && !p.Window.Contains(t.Hour()*60+t.Minute()) {
+		return false
+	}
+	return true
+}
+
+// matches reports whether a line falls within the promotion's scope
+func (p Promotion) matches(line Line) bool {
+	switch p.Scope {
+	case ScopeOrder:
+		return true
+	case ScopeCategory:
+		return containsInt(p.CategoryIDs, line.CategoryID)
+	case ScopeProduct:
+		return containsInt(p.ProductIDs, line.ProductID)
+	}
+	return false
+}
+
+// Manual is a discount entered by the cashier. A reason is mandatory.
+type Manual struct {
+	Type   Type
+	Value  float64
+	Reason string
+}
+
+// Validate checks the manual discount values
+func (m Manual) Validate() error {
+	if m.Reason == "" {
+		return errors.New("manual discounts require a reason")
+	}
+	switch m.Type {
+	case Percentage:
+		if m.Value <= 0 || m.Value > 100 {
+			return errors.New("percentage must be greater than 0 and at most 100")
+		}
+	case Fixed:
+		if m.Value <= 0 || m.Value != math.Trunc(m.Value) {
+			return errors.New("fixed discount must be a positive whole amount")
+		}
+	default:
+		return fmt.Errorf("unsupported manual discount type: %s", m.Type)
+	}
+	return nil
+}
+
+// amountOf returns the discount the manual entry gives on base, capped at base
+func (m Manual) amountOf(base int64) int64 {
+	var amount int64
+	if m.Type == Percentage {
+		amount = percentOf(base, m.Value)
+	} else {
+		amount = int64(m.Value)
+	}
+	return min(amount, base)
+}
+
+// Line is an order line to evaluate
+type Line struct {
+	ProductID  int
+	CategoryID int
+	UnitPrice  int64
+	Quantity   int
+	Manual     *Manual
+}
+
+// Gross returns the undiscounted amount of the line
+func (l Line) Gross() int64 {
+	return l.UnitPrice * int64(l.Quantity)
+}
+
+// Discount is a single applied discount. Line is OrderLevel for discounts on
+// the whole order.
+type Discount struct {
+	PromotionID int
+	Source      Source
+	Name        string
+	Reason      string
+	Line        int
+	Amount      int64
+}
+
+// Result is the outcome of evaluating an order
+type Result struct {
+	Subtotal      int64
+	DiscountTotal int64
+	Total         int64
+	// LineDiscounts holds the total discount per line, including each line's
+	// share of order-level discounts, so that line nets sum to Total
+	LineDiscounts []int64
+	Discounts     []Discount
+}
+
+// Evaluate applies the active promotions and manual discounts to the lines.
+//
+// Line promotions (product and category scope) do not stack: each line gets
+// the single promotion that saves it the most, then any manual line discount.
+// The best order promotion is then applied to what is left, followed by the
+// manual order discount. Order-level discounts are spread over the lines in
+// proportion to their remaining amount.
+func Evaluate(lines []Line, promotions []Promotion, orderManual *Manual, at time.Time) *Result {
+	result := &Result{LineDiscounts: make([]int64, len(lines))}
+	for _, line := range lines {
+		result.Subtotal += line.Gross()
+	}
+
+	var linePromos, orderPromos []Promotion
+	for _, p := range promotions {
+		if !p.ActiveAt(at) || result.Subtotal < p.MinSpend {
+			continue
+		}
+		if p.Scope == ScopeOrder && p.Type != BuyXGetY {
+			orderPromos = append(orderPromos, p)
+		} else {
+			linePromos = append(linePromos, p)
+		}
+	}
+
+	// Pick the best line promotion for every line
+	best := make([]Discount, len(lines))
+	for _, p := range linePromos {
+		for i, amount := range lineAmounts(p, lines) {
+			if amount > best[i].Amount {
+				best[i] = Discount{PromotionID: p.ID, Source: SourcePromotion, Name: p.Name, Line: i, Amount: amount}
+			}
+		}
+	}
+	for i, line := range lines {
+		if best[i].Amount > 0 {
+			result.LineDiscounts[i] += best[i].Amount
+			result.Discounts = append(result.Discounts, best[i])
+		}
+		if line.Manual != nil {
+			remaining := line.Gross() - result.LineDiscounts[i]
+			if amount := line.Manual.amountOf(remaining); amount > 0 {
+				result.LineDiscounts[i] += amount
+				result.Discounts = append(result.Discounts, Discount{Source: SourceManual, Name: "Manual discount", Reason: line.Manual.Reason, Line: i, Amount: amount})
+			}
+		}
+	}
+
+	// Order-level discounts apply to what remains after line discounts
+	remaining := result.Subtotal
+	for _, amount := range result.LineDiscounts {
+		remaining -= amount
+	}
+
+	var orderLevel int64
+	var bestOrder *Discount
+	for _, p := range orderPromos {
+		amount := p.Amount
+		if p.Type == Percentage {
+			amount = percentOf(remaining, p.Percent)
+		}
+		amount = min(amount, remaining)
+		if amount > 0 && (bestOrder == nil || amount > bestOrder.Amount) {
+			bestOrder = &Discount{PromotionID: p.ID, Source: SourcePromotion, Name: p.Name, Line: OrderLevel, Amount: amount}
+		}
+	}
+	if bestOrder != nil {
+		orderLevel += bestOrder.Amount
+		result.Discounts = append(result.Discounts, *bestOrder)
+	}
+	if orderManual != nil {
+		if amount := orderManual.amountOf(remaining - orderLevel); amount > 0 {
+			orderLevel += amount
+			result.Discounts = append(result.Discounts, Discount{Source: SourceManual, Name: "Manual discount", Reason: orderManual.Reason, Line: OrderLevel, Amount: amount})
+		}
+	}
+
+	if orderLevel > 0 {
+		nets := make([]int64, len(lines))
+		for i, line := range lines {
+			nets[i] = line.Gross() - result.LineDiscounts[i]
+		}
+		for i, share := range Allocate(orderLevel, nets) {
+			result.LineDiscounts[i] += share
+		}
+	}
+
+	for _, amount := range result.LineDiscounts {
+		result.DiscountTotal += amount
+	}
+	result.Total = result.Subtotal - result.DiscountTotal
+	return result
+}
+
+// lineAmounts returns the discount a line-level promotion gives to each line
+func lineAmounts(p Promotion, lines []Line) []int64 {
+	amounts := make([]int64, len(lines))
+	switch p.Type {
+	case Percentage:
+		for i, line := range lines {
+			if p.matches(line) {
+				amounts[i] = percentOf(line.Gross(), p.Percent)
+			}
+		}
+	case Fixed:
+		for i, line := range lines {
+			if p.matches(line) {
+				amounts[i] = min(p.Amount*int64(line.Quantity), line.Gross())
+			}
+		}
+	case BuyXGetY:
+		// Pool every matching unit, most expensive first, so the cheapest
+		// unit of each buy+get group is the one given away
+		type unit struct {
+			line  int
+			price int64
+		}
+		var units []unit
+		for i, line := range lines {
+			if !p.matches(line) {
+				continue
+			}
+			for q := 0; q < line.Quantity; q++ {
+				units = append(units, unit{line: i, price: line.UnitPrice})
+			}
+		}
+		sort.SliceStable(units, func(a, b int) bool { return units[a].price > units[b].price })
+		group := p.BuyQuantity + p.GetQuantity
+		for start := 0; start+group <= len(units); start += group {
+			for _, free := range units[start+p.BuyQuantity : start+group] {
+				amounts[free.line] += free.price
+			}
+		}
+	}
+	return amounts
+}
+
+// Allocate splits amount over the weights proportionally using the largest
+// remainder method so the shares always add up to amount exactly
+func Allocate(amount int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+	var total int64
+	for _, w := range weights {
+		total += w
+	}
+	if total <= 0 || amount <= 0 {
+		return shares
+	}
+
+	type remainder struct {
+		index int
+		value int64
+	}
+	remainders := make([]remainder, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		shares[i] = amount * w / total
+		remainders[i] = remainder{index: i, value: amount * w % total}
+		allocated += shares[i]
+	}
+	sort.SliceStable(remainders, func(a, b int) bool { return remainders[a].value > remainders[b].value })
+	for i := 0; allocated < amount; i++ {
+		shares[remainders[i%len(remainders)].index]++
+		allocated++
+	}
+	return shares
+}
+
+// percentOf returns pct percent of base rounded half away from zero
+func percentOf(base int64, pct float64) int64 {
+	return int64(math.Round(float64(base) * pct / 100))
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package promo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/promo"
+)
+
+func TestEvaluate(t *testing.T) {
+	// Friday 2026-10-16 17:30 local time
+	at := time.Date(2026, 10, 16, 17, 30, 0, 0, time.Local)
+	coffee := promo.Line{ProductID: 1, CategoryID: 10, UnitPrice: 20000, Quantity: 2}
+	cake := promo.Line{ProductID: 2, CategoryID: 20, UnitPrice: 15000, Quantity: 1}
+
+	tests := []struct {
+		name          string
+		lines         []promo.Line
+		promotions    []promo.Promotion
+		manual        *promo.Manual
+		wantDiscount  int64
+		wantTotal     int64
+		wantLines     []int64
+		wantDiscounts int
+	}{
+		{
+			name:          "no promotions",
+			lines:         []promo.Line{coffee, cake},
+			wantDiscount:  0,
+			wantTotal:     55000,
+			wantLines:     []int64{0, 0},
+			wantDiscounts: 0,
+		},
+		{
+			name:  "category percentage",
+			lines: []promo.Line{coffee, cake},
+			promotions: []promo.Promotion{
+				{ID: 1, Name: "Coffee 10%", Type: promo.Percentage, Scope: promo.ScopeCategory, Percent: 10, CategoryIDs: []int{10}},
+			},
+			wantDiscount:  4000,
+			wantTotal:     51000,
+			wantLines:     []int64{4000, 0},
+			wantDiscounts: 1,
+		},
+		{
+			name:  "best line promotion wins without stacking",
+			lines: []promo.Line{coffee},
+			promotions: []promo.Promotion{
+				{ID: 1, Name: "Coffee 10%", Type: promo.Percentage, Scope: promo.ScopeCategory, Percent: 10, CategoryIDs: []int{10}},
+				{ID: 2, Name: "Coffee 5k off", Type: promo.Fixed, Scope: promo.ScopeProduct, Amount: 5000, ProductIDs: []int{1}},
+			},
+			wantDiscount:  10000,
+			wantTotal:     30000,
+			wantLines:     []int64{10000},
+			wantDiscounts: 1,
+		},
+		{
+			name:  "buy one get one gives the cheapest unit away",
+			lines: []promo.Line{coffee, {ProductID: 3, CategoryID: 10, UnitPrice: 18000, Quantity: 2}},
+			promotions: []promo.Promotion{
+				{ID: 1, Name: "BOGO coffee", Type: promo.BuyXGetY, Scope: promo.ScopeCategory, BuyQuantity: 1, GetQuantity: 1, CategoryIDs: []int{10}},
+			},
+			wantDiscount:  38000,
+			wantTotal:     38000,
+			wantLines:     []int64{20000, 18000},
+			wantDiscounts: 2,
+		},
+		{
+			name:  "minimum spend not reached",
+			lines: []promo.Line{cake},
+			promotions: []promo.Promotion{
+				{ID: 1, Name: "10k off above 50k", Type: promo.Fixed, Scope: promo.ScopeOrder, Amount: 10000, MinSpend: 50000},
+			},
+			wantDiscount:  0,
+			wantTotal:     15000,
+			wantLines:     []int64{0},
+			wantDiscounts: 0,
+		},
+		{
+			name:  "order discount is allocated over lines",
+			lines: []promo.Line{coffee, cake},
+			promotions: []promo.Promotion{
+				{ID: 1, Name: "10k off above 50k", Type: promo.Fixed, Scope: promo.ScopeOrder, Amount: 10000, MinSpend: 50000},
+			},
+			wantDiscount:  10000,
+			wantTotal:     45000,
+			wantLines:     []int64{7273, 2727},
+			wantDiscounts: 1,
+		},
+		{
+			name:  "happy hour outside window",
+			lines: []promo.Line{coffee},
+			promotions: []promo.Promotion{
+				{ID: 1, Name: "Happy hour", Type: promo.Percentage, Scope: promo.ScopeOrder, Percent: 50, Window: &promo.TimeWindow{Start: 14 * 60, End: 16 * 60}},
+			},
+			wantDiscount:  0,
+			wantTotal:     40000,
+			wantLines:     []int64{0},
+			wantDiscounts: 0,
+		},
+		{
+			name:  "manual order discount after promotions",
+			lines: []promo.Line{coffee},
+			promotions: []promo.Promotion{
+				{ID: 1, Name: "Friday 25%", Type: promo.Percentage, Scope: promo.ScopeOrder, Percent: 25, DaysOfWeek: []time.Weekday{time.Friday}},
+			},
+			manual:        &promo.Manual{Type: promo.Fixed, Value: 5000, Reason: "Loyal customer"},
+			wantDiscount:  15000,
+			wantTotal:     25000,
+			wantLines:     []int64{15000},
+			wantDiscounts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := promo.Evaluate(tt.lines, tt.promotions, tt.manual, at)
+
+			assert.Equal(t, tt.wantDiscount, result.DiscountTotal, "DiscountTotal should match")
+			assert.Equal(t, tt.wantTotal, result.Total, "Total should match")
+			assert.Equal(t, tt.wantLines, result.LineDiscounts, "LineDiscounts should match")
+			assert.Len(t, result.Discounts, tt.wantDiscounts, "Number of applied discounts should match")
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		weights []int64
+		want    []int64
+	}{
+		{name: "even split", amount: 100, weights: []int64{50, 50}, want: []int64{50, 50}},
+		{name: "remainder goes to largest fraction", amount: 100, weights: []int64{1, 1, 1}, want: []int64{34, 33, 33}},
+		{name: "zero weights", amount: 100, weights: []int64{0, 0}, want: []int64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, promo.Allocate(tt.amount, tt.weights))
+		})
+	}
+}
+
+func TestPromotionValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion promo.Promotion
+		wantErr   bool
+	}{
+		{name: "valid order percentage", promotion: promo.Promotion{Type: promo.Percentage, Scope: promo.ScopeOrder, Percent: 10}},
+		{name: "percentage above 100", promotion: promo.Promotion{Type: promo.Percentage, Scope: promo.ScopeOrder, Percent: 110}, wantErr: true},
+		{name: "category without categories", promotion: promo.Promotion{Type: promo.Fixed, Scope: promo.ScopeCategory, Amount: 1000}, wantErr: true},
+		{name: "buy x get y without quantities", promotion: promo.Promotion{Type: promo.BuyXGetY, Scope: promo.ScopeProduct, ProductIDs: []int{1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.promotion.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
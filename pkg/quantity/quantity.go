@@ -0,0 +1,184 @@
+// Package quantity holds the quantities products are sold and stocked in:
+// whole units, or decimal amounts of a unit of measure such as 0.35 kg.
+package quantity
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of thousandths in one unit. Quantities keep three
+// decimals, enough for grams of a kilogram or millilitres of a litre.
+const Scale = 1000
+
+// Quantity is an amount in thousandths of a unit. Its JSON form is a plain
+// number, so whole quantities read and write as before.
+type Quantity int64
+
+// FromInt returns a quantity of whole units
+func FromInt(n int) Quantity {
+	return Quantity(int64(n) * Scale)
+}
+
+// Parse parses a decimal quantity such as "2", "0.35" or "-1.5". More than
+// three decimals are rejected.
+func Parse(value string) (Quantity, error) {
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(digits, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if whole == "" || len(fraction) > 3 || strings.ContainsAny(whole+fraction, "+-") {
+		return 0, fmt.Errorf("invalid quantity %q", value)
+	}
+	fraction += strings.Repeat("0", 3-len(fraction))
+
+	milli, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", value)
+	}
+	if negative {
+		milli = -milli
+	}
+	return Quantity(milli), nil
+}
+
+// IsWhole reports whether q is a whole number of units
+func (q Quantity) IsWhole() bool {
+	return q%Scale == 0
+}
+
+// String returns q without trailing zeros, such as "2" or "0.35"
+func (q Quantity) String() string {
+	sign := ""
+	milli := int64(q)
+	if milli < 0 {
+		sign, milli = "-", -milli
+	}
+	whole := strconv.FormatInt(milli/Scale, 10)
+	fraction := strings.TrimRight(fmt.Sprintf("%03d", milli%Scale), "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// MarshalJSON encodes q as a JSON number
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number with at most three decimals
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var number json.Number
+	if len(data) > 0 && data[0] == '"' {
+		return fmt.Errorf("quantity: expected a number, got %s", data)
+	}
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("quantity: expected a number: %w", err)
+	}
+	parsed, err := Parse(number.String())
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns and whole-number sums
+func (q *Quantity) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*q = 0
+	case int64:
+		*q = Quantity(v * Scale)
+	case float64:
+		*q = Quantity(math.Round(v * Scale))
+	case []byte:
+		return q.scanString(string(v))
+	case string:
+		return q.scanString(v)
+	default:
+		return fmt.Errorf("quantity: cannot scan %T", src)
+	}
+	return nil
+}
+
+// scanString parses a database numeric, which may carry more decimal zeros
+// than a quantity keeps, such as the result of SUM over NUMERIC(12, 3)
+func (q *Quantity) scanString(value string) error {
+	parsed, err := Parse(value)
+	if err != nil {
+		return fmt.Errorf("quantity: cannot scan %q: %w", value, err)
+	}
+	*q = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing q as a decimal string for NUMERIC
+// columns
+func (q Quantity) Value() (driver.Value, error) {
+	return q.String(), nil
+}
+
+// Unit is the unit of measure a product is sold and stocked in
+type Unit string
+
+const (
+	// Each is a countable item, sold in whole units
+	Each     Unit = "each"
+	Kilogram Unit = "kg"
+	// Gram is sold in whole grams
+	Gram  Unit = "g"
+	Litre Unit = "l"
+	Metre Unit = "m"
+)
+
+// Decimals returns how many decimals quantities of the unit may have
+func (u Unit) Decimals() int {
+	switch u {
+	case Kilogram, Litre, Metre:
+		return 3
+	}
+	return 0
+}
+
+// Check reports an error when q has more decimals than the unit allows
+func (u Unit) Check(q Quantity) error {
+	step := int64(math.Pow10(3 - u.Decimals()))
+	if int64(q)%step != 0 {
+		if u.Decimals() == 0 {
+			return fmt.Errorf("%s is sold in whole units, not %s", u.label(), q)
+		}
+		return fmt.Errorf("%s allows at most %d decimals, not %s", u.label(), u.Decimals(), q)
+	}
+	return nil
+}
+
+// Format returns q with the unit's symbol, such as "0.35 kg". Quantities of
+// countable items are returned as the bare number.
+func (u Unit) Format(q Quantity) string {
+	if u == "" || u == Each {
+		return q.String()
+	}
+	return q.String() + " " + string(u)
+}
+
+// label names the unit in error messages
+func (u Unit) label() string {
+	if u == "" || u == Each {
+		return "a product sold by the item"
+	}
+	return "a product sold by " + string(u)
+}
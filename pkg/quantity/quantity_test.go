@@ -0,0 +1,101 @@
+package quantity_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    quantity.Quantity
+		wantErr bool
+	}{
+		{name: "whole", value: "2", want: 2000},
+		{name: "decimal", value: "0.35", want: 350},
+		{name: "three decimals", value: "1.125", want: 1125},
+		{name: "trailing zeros", value: "12.500000", want: 12500},
+		{name: "negative", value: "-1.5", want: -1500},
+		{name: "spaces", value: " 3 ", want: 3000},
+		{name: "four decimals", value: "0.1234", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+		{name: "no whole part", value: ".5", wantErr: true},
+		{name: "double sign", value: "--1", wantErr: true},
+		{name: "letters", value: "1kg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := quantity.Parse(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "2", quantity.FromInt(2).String())
+	assert.Equal(t, "0.35", quantity.Quantity(350).String())
+	assert.Equal(t, "0.005", quantity.Quantity(5).String())
+	assert.Equal(t, "-1.5", quantity.Quantity(-1500).String())
+	assert.Equal(t, "0", quantity.Quantity(0).String())
+}
+
+func TestJSON(t *testing.T) {
+	var line struct {
+		Quantity quantity.Quantity `json:"quantity"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"quantity": 0.35}`), &line))
+	assert.Equal(t, quantity.Quantity(350), line.Quantity)
+
+	data, err := json.Marshal(line)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"quantity": 0.35}`, string(data))
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"quantity": 2}`), &line))
+	assert.Equal(t, quantity.FromInt(2), line.Quantity)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"quantity": "2"}`), &line))
+	assert.Error(t, json.Unmarshal([]byte(`{"quantity": 0.0001}`), &line))
+}
+
+func TestScan(t *testing.T) {
+	var q quantity.Quantity
+	assert.NoError(t, q.Scan([]byte("12.500")))
+	assert.Equal(t, quantity.Quantity(12500), q)
+
+	assert.NoError(t, q.Scan(int64(3)))
+	assert.Equal(t, quantity.FromInt(3), q)
+
+	assert.NoError(t, q.Scan(nil))
+	assert.Equal(t, quantity.Quantity(0), q)
+
+	assert.Error(t, q.Scan(true))
+
+	value, err := quantity.Quantity(350).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.35", value)
+}
+
+func TestUnitCheck(t *testing.T) {
+	assert.NoError(t, quantity.Each.Check(quantity.FromInt(2)))
+	assert.Error(t, quantity.Each.Check(1500))
+	assert.NoError(t, quantity.Gram.Check(quantity.FromInt(350)))
+	assert.Error(t, quantity.Gram.Check(350))
+	assert.NoError(t, quantity.Kilogram.Check(350))
+	assert.NoError(t, quantity.Litre.Check(1))
+}
+
+func TestUnitFormat(t *testing.T) {
+	assert.Equal(t, "2", quantity.Each.Format(quantity.FromInt(2)))
+	assert.Equal(t, "0.35 kg", quantity.Kilogram.Format(350))
+	assert.Equal(t, "350 g", quantity.Gram.Format(quantity.FromInt(350)))
+}
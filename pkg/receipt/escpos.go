@@ -0,0 +1,87 @@
+package receipt
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// ESC/POS commands understood by common 58mm and 80mm thermal printers
+var (
+	escInit        = []byte{0x1b, 0x40}
+	escAlignLeft   = []byte{0x1b, 0x61, 0x00}
+	escAlignCenter = []byte{0x1b, 0x61, 0x01}
+	escBoldOn      = []byte{0x1b, 0x45, 0x01}
+	escBoldOff     = []byte{0x1b, 0x45, 0x00}
+	gsDoubleHeight = []byte{0x1d, 0x21, 0x01}
+	gsNormalSize   = []byte{0x1d, 0x21, 0x00}
+	escFeedLines   = []byte{0x1b, 0x64, 0x04}
+	gsPartialCut   = []byte{0x1d, 0x56, 0x42, 0x00}
+)
+
+// ESCPOS renders the receipt as raw ESC/POS printer bytes for a printer with
+// the given column width. Large rows use double height so the width is kept.
+// Characters outside ASCII are printed as '?'.
+func ESCPOS(r *Receipt, width int) []byte {
+	return escposRows(layout(r), width)
+}
+
+// escposRows renders laid out rows as ESC/POS printer bytes, ending with a
+// feed and a partial cut
+func escposRows(rows []row, width int) []byte {
+	var b bytes.Buffer
+	b.Write(escInit)
+	for _, row := range rows {
+		if row.align == alignCenter && row.right == "" && !row.rule {
+			b.Write(escAlignCenter)
+		}
+		if row.bold {
+			b.Write(escBoldOn)
+		}
+		if row.large {
+			b.Write(gsDoubleHeight)
+		}
+
+		for _, line := range row.format(width) {
+			if row.align == alignCenter {
+				// The printer centres the text itself
+				line = trimLeftSpaces(line)
+			}
+			b.Write(toASCII(line))
+			b.WriteByte('\n')
+		}
+
+		if row.large {
+			b.Write(gsNormalSize)
+		}
+		if row.bold {
+			b.Write(escBoldOff)
+		}
+		if row.align == alignCenter && row.right == "" && !row.rule {
+			b.Write(escAlignLeft)
+		}
+	}
+	b.Write(escFeedLines)
+	b.Write(gsPartialCut)
+	return b.Bytes()
+}
+
+// trimLeftSpaces removes the padding added for plain text centring
+func trimLeftSpaces(line string) string {
+	for len(line) > 0 && line[0] == ' ' {
+		line = line[1:]
+	}
+	return line
+}
+
+// toASCII replaces characters the printer's default code page cannot print
+func toASCII(line string) []byte {
+	out := make([]byte, 0, len(line))
+	for _, r := range line {
+		if r < utf8.RuneSelf && r >= 0x20 {
+			out = append(out, byte(r))
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+package receipt
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// htmlRow is a pre-formatted label and amount
+type htmlRow struct {
+	Label  string
+	Amount string
+	Strong bool
+}
+
+// htmlLine is a pre-formatted order line
+type htmlLine struct {
+	Name      string
+	Quantity  string
+	Total     string
+	Details   []string
+	Discounts []htmlRow
+}
+
+// htmlView is the data passed to the HTML template
+type htmlView struct {
+	Header  Header
+	Number  string
+	Date    string
+	Status  string
+	Lines   []htmlLine
+	Totals  []htmlRow
+	Tenders []htmlRow
+	Footer  string
+}
+
+var htmlTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Number}}</title>
+<style>
+body { font-family: monospace; max-width: 360px; margin: 0 auto; padding: 16px; color: #111; }
+h1 { font-size: 1.25em; margin: 0; text-align: center; }
+.center { text-align: center; }
+.muted { color: #555; }
+table { width: 100%; border-collapse: collapse; }
+td { padding: 2px 0; vertical-align: top; }
+td.amount { text-align: right; white-space: nowrap; }
+.detail td { padding-left: 12px; color: #555; }
+.strong td { font-weight: bold; font-size: 1.15em; }
+hr { border: 0; border-top: 1px dashed #999; margin: 8px 0; }
+</style>
+</head>
+<body>
+<h1>{{.Header.Name}}</h1>
+{{if .Header.Address}}<div class="center muted">{{.Header.Address}}</div>{{end}}
+{{if .Header.Phone}}<div class="center muted">Tel: {{.Header.Phone}}</div>{{end}}
+{{if .Header.TaxID}}<div class="center muted">NPWP: {{.Header.TaxID}}</div>{{end}}
+<hr>
+<table><tr><td>{{.Number}}</td><td class="amount">{{.Date}}</td></tr></table>
+{{if .Status}}<div class="center"><strong>{{.Status}}</strong></div>{{end}}
+<hr>
+<table>
+{{range .Lines}}<tr><td colspan="2">{{.Name}}</td></tr>
+{{range .Details}}<tr class="detail"><td colspan="2">{{.}}</td></tr>
+{{end}}<tr class="detail"><td>{{.Quantity}}</td><td class="amount">{{.Total}}</td></tr>
+{{range .Discounts}}<tr class="detail"><td>{{.Label}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}{{end}}</table>
+<hr>
+<table>
+{{range .Totals}}<tr{{if .Strong}} class="strong"{{end}}><td>{{.Label}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}</table>
+{{if .Tenders}}<hr>
+<table>
+{{range .Tenders}}<tr><td>{{.Label}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Footer}}<hr>
+<div class="center muted">{{.Footer}}</div>{{end}}
+</body>
+</html>
+`))
+
+// HTML renders the receipt as a standalone HTML page
+func HTML(r *Receipt) ([]byte, error) {
+	view := htmlView{
+		Header: r.Header,
+		Number: r.Number,
+		Date:   r.Date.Format("02/01/2006 15:04"),
+		Status: r.Status,
+		Footer: r.Footer,
+	}
+
+	for _, line := range r.Lines {
+		item := htmlLine{
+			Name:     line.Name,
+			Quantity: formatQuantity(line),
+			Total:    line.Total.Number(),
+			Details:  line.Details,
+		}
+		for _, discount := range line.Discounts {
+			item.Discounts = append(item.Discounts, htmlRow{Label: discount.Label, Amount: discount.Amount.Neg().Number()})
+		}
+		view.Lines = append(view.Lines, item)
+	}
+
+	view.Totals = append(view.Totals, htmlRow{Label: "Subtotal", Amount: r.Subtotal.Number()})
+	for _, discount := range r.Discounts {
+		view.Totals = append(view.Totals, htmlRow{Label: discount.Label, Amount: discount.Amount.Neg().Number()})
+	}
+	if !r.ServiceCharge.IsZero() {
+		view.Totals = append(view.Totals, htmlRow{Label: r.ServiceChargeLabel, Amount: r.ServiceCharge.Number()})
+	}
+	if !r.Tax.IsZero() {
+		label := r.TaxLabel
+		if r.TaxInclusive {
+			label += " (included)"
+		}
+		view.Totals = append(view.Totals, htmlRow{Label: label, Amount: r.Tax.Number()})
+	}
+	if !r.Rounding.IsZero() {
+		view.Totals = append(view.Totals, htmlRow{Label: "Rounding", Amount: r.Rounding.Number()})
+	}
+	view.Totals = append(view.Totals, htmlRow{Label: "TOTAL", Amount: r.Total.Format(), Strong: true})
+
+	if len(r.Tenders) > 0 {
+		for _, tender := range r.Tenders {
+			view.Tenders = append(view.Tenders, htmlRow{Label: tender.Method, Amount: tender.Tendered.Number()})
+		}
+		view.Tenders = append(view.Tenders, htmlRow{Label: "Change", Amount: r.Change.Number()})
+	}
+
+	var b bytes.Buffer
+	if err := htmlTemplate.Execute(&b, view); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
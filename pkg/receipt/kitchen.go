@@ -0,0 +1,61 @@
+package receipt
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+// KitchenLine is one item to prepare. Details are the variant, options and
+// modifiers; Note is the customer's own instruction.
+type KitchenLine struct {
+	Name     string
+	Quantity quantity.Quantity
+	Unit     quantity.Unit
+	Details  []string
+	Note     string
+}
+
+// KitchenTicket is what the kitchen or bar needs to prepare an order: the
+// items and the customer's instructions, without prices
+type KitchenTicket struct {
+	Number string
+	// Label is where the order goes, such as "Table 4"
+	Label string
+	Date  time.Time
+	Lines []KitchenLine
+}
+
+// kitchenLayout arranges a kitchen ticket into printable rows. Items and
+// notes are printed large so they can be read from a distance.
+func kitchenLayout(t *KitchenTicket) []row {
+	rows := []row{{left: t.Number, align: alignCenter, bold: true, large: true}}
+	if t.Label != "" {
+		rows = append(rows, row{left: t.Label, align: alignCenter, bold: true, large: true})
+	}
+	rows = append(rows, row{left: t.Date.Format("02/01/2006 15:04"), align: alignCenter}, row{rule: true})
+
+	for _, line := range t.Lines {
+		rows = append(rows, row{left: fmt.Sprintf("%sx %s", line.Unit.Format(line.Quantity), line.Name), bold: true, large: true})
+		for _, detail := range line.Details {
+			rows = append(rows, row{left: "   " + detail})
+		}
+		if line.Note != "" {
+			rows = append(rows, row{left: "   Note: " + line.Note, bold: true})
+		}
+	}
+	rows = append(rows, row{rule: true})
+	return rows
+}
+
+// KitchenText renders the ticket as plain text of the given column width
+func KitchenText(t *KitchenTicket, width int) string {
+	return textRows(kitchenLayout(t), width)
+}
+
+// KitchenESCPOS renders the ticket as raw ESC/POS printer bytes for a
+// printer with the given column width
+func KitchenESCPOS(t *KitchenTicket, width int) []byte {
+	return escposRows(kitchenLayout(t), width)
+}
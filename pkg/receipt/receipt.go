@@ -0,0 +1,292 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+// Column widths of the common thermal paper sizes in the default font
+const (
+	Width58mm = 32
+	Width80mm = 48
+
+	MinWidth = 24
+	MaxWidth = 64
+)
+
+// WidthForPaper returns the column width of a paper size in millimetres
+func WidthForPaper(mm int) (int, error) {
+	switch mm {
+	case 58:
+		return Width58mm, nil
+	case 80:
+		return Width80mm, nil
+	}
+	return 0, fmt.Errorf("unsupported paper size %dmm, expected 58 or 80", mm)
+}
+
+// Header identifies the store at the top of a receipt
+type Header struct {
+	Name    string
+	Address string
+	Phone   string
+	// TaxID is the store's NPWP, printed when set
+	TaxID string
+}
+
+// Adjustment is a named amount such as a discount
+type Adjustment struct {
+	Label  string
+	Amount money.Money
+}
+
+// Line is one order line on a receipt
+type Line struct {
+	Name      string
+	Quantity  quantity.Quantity
+	Unit      quantity.Unit
+	UnitPrice money.Money
+	Total     money.Money
+	// Details are extra lines printed under the item, such as options
+	Details   []string
+	Discounts []Adjustment
+}
+
+// Tender is one payment on a receipt
+type Tender struct {
+	Method   string
+	Amount   money.Money
+	Tendered money.Money
+	Change   money.Money
+}
+
+// Receipt is everything printed on a customer receipt
+type Receipt struct {
+	Header    Header
+	Number    string
+	Date      time.Time
+	Status    string
+	Lines     []Line
+	Discounts []Adjustment
+	Subtotal  money.Money
+	// ServiceChargeLabel and TaxLabel name the charges, such as "PPN 11%"
+	ServiceChargeLabel string
+	ServiceCharge      money.Money
+	TaxLabel           string
+	TaxInclusive       bool
+	Tax                money.Money
+	Rounding           money.Money
+	Total              money.Money
+	Tenders            []Tender
+	Change             money.Money
+	Footer             string
+}
+
+// align is the horizontal alignment of a row
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+// row is one printed line. A row with a right part prints it right-aligned
+// on the same line as the left part.
+type row struct {
+	left  string
+	right string
+	align align
+	bold  bool
+	large bool
+	rule  bool
+}
+
+// layout arranges a receipt into printable rows
+func layout(r *Receipt) []row {
+	var rows []row
+	center := func(text string, bold bool, large bool) {
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				rows = append(rows, row{left: line, align: alignCenter, bold: bold, large: large})
+			}
+		}
+	}
+	pair := func(left string, right string) {
+		rows = append(rows, row{left: left, right: right})
+	}
+	rule := func() {
+		rows = append(rows, row{rule: true})
+	}
+
+	center(r.Header.Name, true, true)
+	center(r.Header.Address, false, false)
+	if r.Header.Phone != "" {
+		center("Tel: "+r.Header.Phone, false, false)
+	}
+	if r.Header.TaxID != "" {
+		center("NPWP: "+r.Header.TaxID, false, false)
+	}
+	rule()
+
+	pair(r.Number, r.Date.Format("02/01/2006 15:04"))
+	if r.Status != "" {
+		center("*** "+strings.ToUpper(r.Status)+" ***", true, false)
+	}
+	rule()
+
+	for _, line := range r.Lines {
+		rows = append(rows, row{left: line.Name})
+		for _, detail := range line.Details {
+			rows = append(rows, row{left: "  " + detail})
+		}
+		pair("  "+formatQuantity(line), line.Total.Number())
+		for _, discount := range line.Discounts {
+			pair("  "+discount.Label, discount.Amount.Neg().Number())
+		}
+	}
+	rule()
+
+	pair("Subtotal", r.Subtotal.Number())
+	for _, discount := range r.Discounts {
+		pair(discount.Label, discount.Amount.Neg().Number())
+	}
+	if !r.ServiceCharge.IsZero() {
+		pair(r.ServiceChargeLabel, r.ServiceCharge.Number())
+	}
+	if !r.Tax.IsZero() {
+		if r.TaxInclusive {
+			pair(r.TaxLabel+" (included)", r.Tax.Number())
+		} else {
+			pair(r.TaxLabel, r.Tax.Number())
+		}
+	}
+	if !r.Rounding.IsZero() {
+		pair("Rounding", r.Rounding.Number())
+	}
+	rows = append(rows, row{left: "TOTAL", right: r.Total.Format(), bold: true, large: true})
+
+	if len(r.Tenders) > 0 {
+		rule()
+		for _, tender := range r.Tenders {
+			pair(tender.Method, tender.Tendered.Number())
+		}
+		pair("Change", r.Change.Number())
+	}
+
+	if r.Footer != "" {
+		rule()
+		center(r.Footer, false, false)
+	}
+	return rows
+}
+
+// formatQuantity returns the quantity and unit price of a line, such as
+// "2 x 20.000" or "0.35 kg x 65.000"
+func formatQuantity(line Line) string {
+	return fmt.Sprintf("%s x %s", line.Unit.Format(line.Quantity), line.UnitPrice.Number())
+}
+
+// format renders a row as one or more plain lines of the given width.
+// Left text that does not fit next to the right part is wrapped above it.
+func (r row) format(width int) []string {
+	if r.rule {
+		return []string{strings.Repeat("-", width)}
+	}
+
+	var lines []string
+	if r.right == "" {
+		for _, line := range wrap(r.left, width) {
+			switch r.align {
+			case alignCenter:
+				line = strings.Repeat(" ", (width-textWidth(line))/2) + line
+			case alignRight:
+				line = strings.Repeat(" ", width-textWidth(line)) + line
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+
+	left := wrap(r.left, width)
+	last := left[len(left)-1]
+	if textWidth(last)+1+textWidth(r.right) > width {
+		lines = append(lines, left...)
+		last = ""
+	} else {
+		lines = append(lines, left[:len(left)-1]...)
+	}
+	gap := max(width-textWidth(last)-textWidth(r.right), 1)
+	return append(lines, last+strings.Repeat(" ", gap)+r.right)
+}
+
+// wrap splits text into lines of at most width characters, breaking on spaces
+func wrap(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	indent := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	current := indent
+	for _, word := range words {
+		for textWidth(word) > width-textWidth(indent) {
+			if strings.TrimSpace(current) != "" {
+				lines = append(lines, current)
+				current = indent
+			}
+			cut := byteIndex(word, width-textWidth(indent))
+			lines = append(lines, indent+word[:cut])
+			word = word[cut:]
+		}
+		switch {
+		case strings.TrimSpace(current) == "":
+			current = indent + word
+		case textWidth(current)+1+textWidth(word) <= width:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = indent + word
+		}
+	}
+	return append(lines, current)
+}
+
+// textWidth counts characters rather than bytes
+func textWidth(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// byteIndex returns the byte offset of the n-th character of text
+func byteIndex(text string, n int) int {
+	for i := range text {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(text)
+}
+
+// Text renders the receipt as plain text of the given column width
+func Text(r *Receipt, width int) string {
+	return textRows(layout(r), width)
+}
+
+// textRows renders laid out rows as plain text of the given column width
+func textRows(rows []row, width int) string {
+	var b strings.Builder
+	for _, row := range rows {
+		for _, line := range row.format(width) {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
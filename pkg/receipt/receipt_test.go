@@ -0,0 +1,122 @@
+package receipt_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/receipt"
+)
+
+func sampleReceipt() *receipt.Receipt {
+	return &receipt.Receipt{
+		Header: receipt.Header{Name: "Kopi Kita", Address: "Jl. Sudirman 1\nJakarta", TaxID: "01.234.567.8-901.000"},
+		Number: "Order #42",
+		Date:   time.Date(2026, 10, 17, 14, 5, 0, 0, time.UTC),
+		Lines: []receipt.Line{
+			{
+				Name:      "Kopi Susu Gula Aren",
+				Quantity:  quantity.FromInt(2),
+				UnitPrice: money.FromMinor(20000),
+				Total:     money.FromMinor(40000),
+				Discounts: []receipt.Adjustment{{Label: "Happy hour", Amount: money.FromMinor(4000)}},
+			},
+			{Name: "Croissant", Quantity: quantity.FromInt(1), UnitPrice: money.FromMinor(15000), Total: money.FromMinor(15000)},
+		},
+		Subtotal:           money.FromMinor(55000),
+		ServiceChargeLabel: "Service 5%",
+		ServiceCharge:      money.FromMinor(2550),
+		TaxLabel:           "PPN 11%",
+		Tax:                money.FromMinor(5891),
+		Rounding:           money.FromMinor(-41),
+		Total:              money.FromMinor(59400),
+		Tenders:            []receipt.Tender{{Method: "Cash", Amount: money.FromMinor(59400), Tendered: money.FromMinor(100000), Change: money.FromMinor(40600)}},
+		Change:             money.FromMinor(40600),
+		Footer:             "Terima kasih!",
+	}
+}
+
+func TestText(t *testing.T) {
+	for _, width := range []int{receipt.Width58mm, receipt.Width80mm} {
+		text := receipt.Text(sampleReceipt(), width)
+		lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+		for _, line := range lines {
+			assert.LessOrEqual(t, len([]rune(line)), width, "line %q should fit in %d columns", line, width)
+		}
+		assert.Contains(t, text, "Kopi Kita")
+		assert.Contains(t, text, "NPWP: 01.234.567.8-901.000")
+		assert.Contains(t, text, "-4.000")
+		assert.Contains(t, text, "PPN 11%")
+		assert.Contains(t, text, "Rp59.400")
+		assert.Contains(t, text, "40.600")
+		assert.Contains(t, text, "Terima kasih!")
+	}
+
+	narrow := receipt.Text(sampleReceipt(), receipt.Width58mm)
+	assert.Contains(t, narrow, "  2 x 20.000              40.000\n")
+
+	weighed := sampleReceipt()
+	weighed.Lines[1] = receipt.Line{Name: "Beef", Quantity: 350, Unit: quantity.Kilogram, UnitPrice: money.FromMinor(65000), Total: money.FromMinor(22750)}
+	assert.Contains(t, receipt.Text(weighed, receipt.Width58mm), "  0.35 kg x 65.000        22.750\n")
+}
+
+func TestESCPOS(t *testing.T) {
+	data := receipt.ESCPOS(sampleReceipt(), receipt.Width58mm)
+
+	assert.True(t, bytes.HasPrefix(data, []byte{0x1b, 0x40}), "should start by initialising the printer")
+	assert.True(t, bytes.HasSuffix(data, []byte{0x1d, 0x56, 0x42, 0x00}), "should end with a paper cut")
+	assert.Contains(t, string(data), "Kopi Kita")
+	assert.Contains(t, string(data), "Rp59.400")
+}
+
+func TestHTML(t *testing.T) {
+	r := sampleReceipt()
+	r.Lines[1].Name = "<script>alert(1)</script>"
+
+	page, err := receipt.HTML(r)
+	assert.NoError(t, err)
+	assert.Contains(t, string(page), "Kopi Kita")
+	assert.Contains(t, string(page), "Rp59.400")
+	assert.NotContains(t, string(page), "<script>", "item names should be escaped")
+}
+
+func TestWidthForPaper(t *testing.T) {
+	width, err := receipt.WidthForPaper(58)
+	assert.NoError(t, err)
+	assert.Equal(t, receipt.Width58mm, width)
+
+	_, err = receipt.WidthForPaper(76)
+	assert.Error(t, err)
+}
+
+func TestKitchenText(t *testing.T) {
+	ticket := &receipt.KitchenTicket{
+		Number: "OUT1-20261017-0042",
+		Label:  "Table 4",
+		Date:   time.Date(2026, 10, 17, 14, 5, 0, 0, time.UTC),
+		Lines: []receipt.KitchenLine{
+			{Name: "Kopi Susu Gula Aren", Quantity: quantity.FromInt(2), Details: []string{"Large", "Extras: Extra shot", "Sugar: No sugar"}, Note: "Less ice, please"},
+			{Name: "Croissant", Quantity: quantity.FromInt(1)},
+		},
+	}
+
+	text := receipt.KitchenText(ticket, receipt.Width58mm)
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		assert.LessOrEqual(t, len([]rune(line)), receipt.Width58mm, "line %q should fit", line)
+	}
+	assert.Contains(t, text, "Table 4")
+	assert.Contains(t, text, "2x Kopi Susu Gula Aren\n")
+	assert.Contains(t, text, "   Extras: Extra shot\n")
+	assert.Contains(t, text, "   Note: Less ice, please\n")
+	assert.Contains(t, text, "1x Croissant\n")
+	assert.NotContains(t, text, "Rp", "kitchen tickets should not show prices")
+
+	data := receipt.KitchenESCPOS(ticket, receipt.Width80mm)
+	assert.True(t, bytes.HasSuffix(data, []byte{0x1d, 0x56, 0x42, 0x00}), "should end with a paper cut")
+	assert.Contains(t, string(data), "Note: Less ice, please")
+}
@@ -0,0 +1,96 @@
+package stock
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
+
+// AllowNegative reports whether the user lets tracked products be sold
+// beyond their stock on hand. Users who never saved settings do not.
+func AllowNegative(tx *sql.Tx, userID int) (bool, *customerror.CustomError) {
+	var allow bool
+	err := tx.QueryRow(`SELECT allow_negative_stock FROM store_settings WHERE user_id = $1`, userID).Scan(&allow)
+	if err != nil && err != sql.ErrNoRows {
+		return false, customerror.NewPostgresError(err)
+	}
+	return allow, nil
+}
+
+// Record applies movements to the stock on hand of the user's products and
+// variants and appends them to the ledger. Products and variants that do not
+// track stock are skipped, so only the recorded movements are returned. They
+// are updated in ID order so concurrent orders lock them in the same order. Unless
+// allowNegative is set, a movement that would take stock below zero fails
+// with a conflict.
+func Record(tx *sql.Tx, userID int, movements []Movement, allowNegative bool) ([]Movement, *customerror.CustomError) {
+	sorted := make([]Movement, 0, len(movements))
+	for _, movement := range movements {
+		if movement.Quantity != 0 {
+			sorted = append(sorted, movement)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].ProductID != sorted[j].ProductID {
+			return sorted[i].ProductID < sorted[j].ProductID
+		}
+		return variantOrder(sorted[i].VariantID) < variantOrder(sorted[j].VariantID)
+	})
+
+	updateProduct := `
+        UPDATE products SET stock_on_hand = stock_on_hand + $1
+        WHERE id = $2 AND user_id = $3 AND track_stock
+        RETURNING stock_on_hand, name
+    `
+	updateVariant := `
+        UPDATE product_variants v SET stock_on_hand = v.stock_on_hand + $1
+        FROM products p
+        WHERE v.id = $4 AND v.product_id = $2 AND v.user_id = $3 AND v.track_stock AND p.id = v.product_id
+        RETURNING v.stock_on_hand, p.name || ' ' || v.name
+    `
+	insert := `
+        INSERT INTO stock_movements (user_id, product_id, variant_id, type, quantity, balance_after, order_id, refund_id, reason, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id, created_at
+    `
+	recorded := make([]Movement, 0, len(sorted))
+	for _, movement := range sorted {
+		var name string
+		var row *sql.Row
+		if movement.VariantID != nil {
+			row = tx.QueryRow(updateVariant, movement.Quantity, movement.ProductID, userID, *movement.VariantID)
+		} else {
+			row = tx.QueryRow(updateProduct, movement.Quantity, movement.ProductID, userID)
+		}
+		err := row.Scan(&movement.BalanceAfter, &name)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		if movement.Quantity < 0 && movement.BalanceAfter < 0 && !allowNegative {
+			available := movement.BalanceAfter - movement.Quantity
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Not enough stock of %s: %s available, %s requested", name, available, -movement.Quantity), http.StatusConflict)
+		}
+
+		err = tx.QueryRow(insert, userID, movement.ProductID, movement.VariantID, movement.Type, movement.Quantity, movement.BalanceAfter,
+			movement.OrderID, movement.RefundID, movement.Reason, movement.CreatedBy).Scan(&movement.ID, &movement.CreatedAt)
+		if err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		recorded = append(recorded, movement)
+	}
+	return recorded, nil
+}
+
+// variantOrder sorts a product's own stock before that of its variants
+func variantOrder(variantID *int) int {
+	if variantID == nil {
+		return 0
+	}
+	return *variantID
+}
@@ -0,0 +1,70 @@
+// Package stock keeps the stock on hand of tracked products together with an
+// append-only ledger of the movements that changed it.
+package stock
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+// MovementType is the reason stock on hand changed
+type MovementType string
+
+const (
+	// MovementSale takes sold units out of stock
+	MovementSale MovementType = "sale"
+	// MovementVoid puts back the units of a voided or deleted order
+	MovementVoid MovementType = "void"
+	// MovementRefund puts back returned units
+	MovementRefund MovementType = "refund"
+	// MovementAdjustment corrects the stock on hand, such as after a count
+	MovementAdjustment MovementType = "adjustment"
+	// MovementReceiving adds delivered units
+	MovementReceiving MovementType = "receiving"
+	// MovementWaste takes spoiled, damaged or lost units out of stock
+	MovementWaste MovementType = "waste"
+)
+
+// Movement is one entry of the stock ledger. Quantity is the signed change
+// and BalanceAfter the stock on hand once it was applied, both in the
+// product's unit. Movements with a VariantID change the stock of that
+// variant instead of the product's.
+type Movement struct {
+	ID           int               `json:"id"`
+	ProductID    int               `json:"product_id" example:"1"`
+	VariantID    *int              `json:"variant_id"`
+	Type         MovementType      `json:"type" example:"receiving"`
+	Quantity     quantity.Quantity `json:"quantity" example:"24"`
+	BalanceAfter quantity.Quantity `json:"balance_after" example:"30"`
+	OrderID      *int              `json:"order_id"`
+	RefundID     *int              `json:"refund_id"`
+	Reason       string            `json:"reason" example:"Weekly delivery"`
+	CreatedBy    int               `json:"created_by" example:"1"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// ManualChange returns the signed stock change of a movement entered by hand.
+// Receiving and waste take the amount, which waste removes; an adjustment
+// takes the signed correction itself.
+func ManualChange(movementType MovementType, amount quantity.Quantity) (quantity.Quantity, error) {
+	switch movementType {
+	case MovementReceiving:
+		if amount <= 0 {
+			return 0, errors.New("receiving quantity must be positive")
+		}
+		return amount, nil
+	case MovementWaste:
+		if amount <= 0 {
+			return 0, errors.New("waste quantity must be positive")
+		}
+		return -amount, nil
+	case MovementAdjustment:
+		if amount == 0 {
+			return 0, errors.New("adjustment quantity must not be zero")
+		}
+		return amount, nil
+	}
+	return 0, errors.New("movement type must be adjustment, receiving or waste")
+}
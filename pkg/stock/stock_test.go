@@ -0,0 +1,42 @@
+package stock_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/stock"
+)
+
+func TestManualChange(t *testing.T) {
+	tests := []struct {
+		name         string
+		movementType stock.MovementType
+		quantity     quantity.Quantity
+		want         quantity.Quantity
+		wantErr      bool
+	}{
+		{name: "receiving adds", movementType: stock.MovementReceiving, quantity: quantity.FromInt(24), want: quantity.FromInt(24)},
+		{name: "receiving by weight", movementType: stock.MovementReceiving, quantity: 12500, want: 12500},
+		{name: "waste removes", movementType: stock.MovementWaste, quantity: quantity.FromInt(3), want: quantity.FromInt(-3)},
+		{name: "adjustment up", movementType: stock.MovementAdjustment, quantity: quantity.FromInt(2), want: quantity.FromInt(2)},
+		{name: "adjustment down", movementType: stock.MovementAdjustment, quantity: quantity.FromInt(-5), want: quantity.FromInt(-5)},
+		{name: "negative receiving", movementType: stock.MovementReceiving, quantity: quantity.FromInt(-1), wantErr: true},
+		{name: "zero waste", movementType: stock.MovementWaste, quantity: 0, wantErr: true},
+		{name: "zero adjustment", movementType: stock.MovementAdjustment, quantity: 0, wantErr: true},
+		{name: "sales are recorded by orders", movementType: stock.MovementSale, quantity: quantity.FromInt(1), wantErr: true},
+		{name: "unknown type", movementType: "theft", quantity: quantity.FromInt(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stock.ManualChange(tt.movementType, tt.quantity)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Class is the tax treatment of a product
+type Class string
+
+const (
+	ClassStandard Class = "standard"
+	ClassExempt   Class = "exempt"
+)
+
+// IsValid reports whether the tax class is supported
+func (c Class) IsValid() bool {
+	return c == ClassStandard || c == ClassExempt
+}
+
+// RoundingMode is how the grand total is rounded to the rounding unit
+type RoundingMode string
+
+const (
+	RoundNone    RoundingMode = "none"
+	RoundNearest RoundingMode = "nearest"
+	RoundUp      RoundingMode = "up"
+	RoundDown    RoundingMode = "down"
+)
+
+// Settings holds a store's tax and service charge configuration
+type Settings struct {
+	// Rate is the PPN rate in percent, 11 means 11%
+	Rate float64
+	// Inclusive means catalog prices already include tax. The tax is then
+	// extracted from the amounts instead of being added on top.
+	Inclusive bool
+	// ServiceChargeRate is the service charge in percent of the discounted
+	// subtotal. Service charge is taxed like the lines it is charged on.
+	ServiceChargeRate float64
+	RoundingMode      RoundingMode
+	// RoundingUnit is the step the grand total is rounded to, such as 100
+	RoundingUnit int64
+}
+
+// Validate checks that the settings are usable
+func (s Settings) Validate() error {
+	if s.Rate < 0 || s.Rate > 100 {
+		return errors.New("tax rate must be between 0 and 100")
+	}
+	if s.ServiceChargeRate < 0 || s.ServiceChargeRate > 100 {
+		return errors.New("service charge rate must be between 0 and 100")
+	}
+	switch s.RoundingMode {
+	case "", RoundNone:
+	case RoundNearest, RoundUp, RoundDown:
+		if s.RoundingUnit <= 0 {
+			return errors.New("rounding unit must be greater than zero")
+		}
+	default:
+		return fmt.Errorf("unsupported rounding mode: %s", s.RoundingMode)
+	}
+	return nil
+}
+
+// Line is an order line after discounts
+type Line struct {
+	Amount int64
+	Class  Class
+}
+
+// Breakdown is the tax and service charge computed for an order
+type Breakdown struct {
+	// Net is the sum of the discounted line amounts
+	Net           int64
+	ServiceCharge int64
+	// Tax is the tax contained in (inclusive) or added to (exclusive) the order
+	Tax int64
+	// Rounding is the adjustment applied to reach the rounded grand total
+	Rounding int64
+	Total    int64
+	// LineServiceCharges and LineTaxes hold each line's share, in line order
+	LineServiceCharges []int64
+	LineTaxes          []int64
+}
+
+// Compute calculates service charge, tax, rounding and the grand total of an
+// order. Amounts are computed on running totals so the line shares always
+// add up to the order amounts without rounding drift.
+func Compute(lines []Line, settings Settings) Breakdown {
+	result := Breakdown{
+		LineServiceCharges: make([]int64, len(lines)),
+		LineTaxes:          make([]int64, len(lines)),
+	}
+
+	var net int64
+	for i, line := range lines {
+		before := percentOf(net, settings.ServiceChargeRate)
+		net += line.Amount
+		result.LineServiceCharges[i] = percentOf(net, settings.ServiceChargeRate) - before
+	}
+	result.Net = net
+	result.ServiceCharge = percentOf(net, settings.ServiceChargeRate)
+
+	var base int64
+	for i, line := range lines {
+		if line.Class == ClassExempt {
+			continue
+		}
+		before := taxOf(base, settings)
+		base += line.Amount + result.LineServiceCharges[i]
+		result.LineTaxes[i] = taxOf(base, settings) - before
+	}
+	result.Tax = taxOf(base, settings)
+
+	total := net + result.ServiceCharge
+	if !settings.Inclusive {
+		total += result.Tax
+	}
+	result.Total = Round(total, settings.RoundingMode, settings.RoundingUnit)
+	result.Rounding = result.Total - total
+	return result
+}
+
+// Round rounds an amount to the given unit
+func Round(amount int64, mode RoundingMode, unit int64) int64 {
+	if unit <= 0 {
+		return amount
+	}
+	remainder := amount % unit
+	if remainder == 0 {
+		return amount
+	}
+	switch mode {
+	case RoundNearest:
+		if remainder*2 >= unit {
+			return amount - remainder + unit
+		}
+		return amount - remainder
+	case RoundUp:
+		return amount - remainder + unit
+	case RoundDown:
+		return amount - remainder
+	default:
+		return amount
+	}
+}
+
+// taxOf returns the tax on a taxable base
+func taxOf(base int64, settings Settings) int64 {
+	if settings.Inclusive {
+		return int64(math.Round(float64(base) * settings.Rate / (100 + settings.Rate)))
+	}
+	return percentOf(base, settings.Rate)
+}
+
+// percentOf returns percent of amount, rounded to the nearest unit
+func percentOf(amount int64, percent float64) int64 {
+	return int64(math.Round(float64(amount) * percent / 100))
+}
@@ -0,0 +1,148 @@
+package tax_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+func TestCompute(t *testing.T) {
+	lines := []tax.Line{
+		{Amount: 40000, Class: tax.ClassStandard},
+		{Amount: 15000, Class: tax.ClassExempt},
+	}
+
+	tests := []struct {
+		name              string
+		lines             []tax.Line
+		settings          tax.Settings
+		wantService       int64
+		wantTax           int64
+		wantRounding      int64
+		wantTotal         int64
+		wantLineTaxes     []int64
+		wantLineSurcharge []int64
+	}{
+		{
+			name:              "no tax configured",
+			lines:             lines,
+			wantTotal:         55000,
+			wantLineTaxes:     []int64{0, 0},
+			wantLineSurcharge: []int64{0, 0},
+		},
+		{
+			name:              "exclusive PPN skips exempt lines",
+			lines:             lines,
+			settings:          tax.Settings{Rate: 11},
+			wantTax:           4400,
+			wantTotal:         59400,
+			wantLineTaxes:     []int64{4400, 0},
+			wantLineSurcharge: []int64{0, 0},
+		},
+		{
+			name:              "inclusive PPN is extracted from the price",
+			lines:             []tax.Line{{Amount: 11100, Class: tax.ClassStandard}},
+			settings:          tax.Settings{Rate: 11, Inclusive: true},
+			wantTax:           1100,
+			wantTotal:         11100,
+			wantLineTaxes:     []int64{1100},
+			wantLineSurcharge: []int64{0},
+		},
+		{
+			name:              "service charge is taxed",
+			lines:             []tax.Line{{Amount: 100000, Class: tax.ClassStandard}},
+			settings:          tax.Settings{Rate: 11, ServiceChargeRate: 5},
+			wantService:       5000,
+			wantTax:           11550,
+			wantTotal:         116550,
+			wantLineTaxes:     []int64{11550},
+			wantLineSurcharge: []int64{5000},
+		},
+		{
+			name:              "grand total rounded to nearest hundred",
+			lines:             []tax.Line{{Amount: 12345, Class: tax.ClassStandard}},
+			settings:          tax.Settings{Rate: 11, RoundingMode: tax.RoundNearest, RoundingUnit: 100},
+			wantTax:           1358,
+			wantRounding:      -3,
+			wantTotal:         13700,
+			wantLineTaxes:     []int64{1358},
+			wantLineSurcharge: []int64{0},
+		},
+		{
+			name: "line shares add up to the order amounts",
+			lines: []tax.Line{
+				{Amount: 3333, Class: tax.ClassStandard},
+				{Amount: 3333, Class: tax.ClassStandard},
+				{Amount: 3334, Class: tax.ClassStandard},
+			},
+			settings:          tax.Settings{Rate: 11, ServiceChargeRate: 5},
+			wantService:       500,
+			wantTax:           1155,
+			wantTotal:         11655,
+			wantLineTaxes:     []int64{385, 385, 385},
+			wantLineSurcharge: []int64{167, 166, 167},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tax.Compute(tt.lines, tt.settings)
+
+			assert.Equal(t, tt.wantService, result.ServiceCharge, "ServiceCharge should match")
+			assert.Equal(t, tt.wantTax, result.Tax, "Tax should match")
+			assert.Equal(t, tt.wantRounding, result.Rounding, "Rounding should match")
+			assert.Equal(t, tt.wantTotal, result.Total, "Total should match")
+			assert.Equal(t, tt.wantLineTaxes, result.LineTaxes, "LineTaxes should match")
+			assert.Equal(t, tt.wantLineSurcharge, result.LineServiceCharges, "LineServiceCharges should match")
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		mode   tax.RoundingMode
+		unit   int64
+		want   int64
+	}{
+		{name: "nearest down", amount: 12340, mode: tax.RoundNearest, unit: 100, want: 12300},
+		{name: "nearest half up", amount: 12350, mode: tax.RoundNearest, unit: 100, want: 12400},
+		{name: "up", amount: 12301, mode: tax.RoundUp, unit: 100, want: 12400},
+		{name: "down", amount: 12399, mode: tax.RoundDown, unit: 100, want: 12300},
+		{name: "none", amount: 12345, mode: tax.RoundNone, unit: 100, want: 12345},
+		{name: "no unit", amount: 12345, mode: tax.RoundNearest, unit: 0, want: 12345},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tax.Round(tt.amount, tt.mode, tt.unit))
+		})
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings tax.Settings
+		wantErr  bool
+	}{
+		{name: "defaults", settings: tax.Settings{}},
+		{name: "ppn with rounding", settings: tax.Settings{Rate: 11, RoundingMode: tax.RoundNearest, RoundingUnit: 100}},
+		{name: "negative rate", settings: tax.Settings{Rate: -1}, wantErr: true},
+		{name: "rounding without unit", settings: tax.Settings{RoundingMode: tax.RoundUp}, wantErr: true},
+		{name: "unknown rounding mode", settings: tax.Settings{RoundingMode: "bankers", RoundingUnit: 100}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package category
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+	"github.com/yantology/simple-pos/pkg/export"
+)
+
+// RegisterExportRoutes registers the spreadsheet downloads of categories
+func (h *CategoryHandler) RegisterExportRoutes(router *gin.RouterGroup) {
+	router.GET("/categories.csv", h.ExportCategories)
+	router.GET("/categories.xlsx", h.ExportCategories)
+}
+
+// @Summary Export categories
+// @Description Streams the categories of the authenticated user with their number of products as a CSV or XLSX file, ordered by name. CSV numbers use the separators of the locale query parameter or the Accept-Language header.
+// @Tags exports
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param locale query string false "Number format of CSV files, such as id or en"
+// @Success 200 {file} file "Category export"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /exports/categories.csv [get]
+// @Router /exports/categories.xlsx [get]
+func (h *CategoryHandler) ExportCategories(c *gin.Context) {
+	format, err := export.ParseFormat(path.Ext(c.FullPath()))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.MessageResponse{Message: err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	writer := export.Download(c.Writer, c.Request, format, "categories")
+	writer.WriteHeader("ID", "Name", "Products", "Created at", "Updated at")
+	customErr := h.repository.ExportCategories(userID, func(category *ExportedCategory) error {
+		return writer.WriteRow(category.ID, category.Name, category.ProductCount, category.CreatedAt, category.UpdatedAt)
+	})
+	if customErr != nil {
+		// Once rows were sent the download can only be cut short
+		if !c.Writer.Written() {
+			c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		}
+		fmt.Printf("ExportCategories: Export failed: %s\n", customErr.Message()) // Add log
+		return
+	}
+	if err := writer.Close(); err != nil {
+		fmt.Printf("ExportCategories: Error finishing export: %v\n", err) // Add log
+	}
+}
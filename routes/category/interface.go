@@ -1,6 +1,10 @@
 package category
 
-import "github.com/yantology/simple-pos/pkg/customerror"
+import (
+	"database/sql"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
 
 // Repository defines the data access methods for categories
 type Repository interface {
@@ -11,8 +15,12 @@ type Repository interface {
 	GetCategoryByName(name string, userID int) (*Category, *customerror.CustomError) // Changed userID to int
 	// CreateCategory now requires userID
 	CreateCategory(category *CreateCategory, userID int) (*Category, *customerror.CustomError) // Changed userID to int
+	// CreateCategoryTx creates a category inside the caller's transaction
+	CreateCategoryTx(tx *sql.Tx, category *CreateCategory, userID int) (*Category, *customerror.CustomError)
 	// UpdateCategory now requires userID for authorization
 	UpdateCategory(id int, userID int, category *UpdateCategoryRequest) (*Category, *customerror.CustomError) // Changed id and userID to int
 	// DeleteCategory now requires userID for authorization
 	DeleteCategory(id int, userID int) *customerror.CustomError // Changed id and userID to int
+	// ExportCategories streams the user's categories to write
+	ExportCategories(userID int, write func(*ExportedCategory) error) *customerror.CustomError
 }
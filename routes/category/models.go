@@ -32,3 +32,9 @@ type DataResponse[T any] struct {
 	Data    T      `json:"data"`
 	Message string `json:"message" example:"Operation completed successfully"`
 }
+
+// ExportedCategory is one row of the category export
+type ExportedCategory struct {
+	Category
+	ProductCount int
+}
@@ -102,12 +102,28 @@ func (r *PostgresRepository) GetCategoryByName(name string, userID int) (*Catego
 	return &category, nil
 }
 
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
 // CreateCategory creates a new category
 func (r *PostgresRepository) CreateCategory(categoryData *CreateCategory, userID int) (*Category, *customerror.CustomError) { // Changed userID to int
+	return createCategory(r.db, categoryData, userID)
+}
+
+// CreateCategoryTx creates a new category inside the caller's transaction, so
+// it is rolled back with it
+func (r *PostgresRepository) CreateCategoryTx(tx *sql.Tx, categoryData *CreateCategory, userID int) (*Category, *customerror.CustomError) {
+	return createCategory(tx, categoryData, userID)
+}
+
+// createCategory inserts a category with db
+func createCategory(db queryRower, categoryData *CreateCategory, userID int) (*Category, *customerror.CustomError) {
 	var newCategory Category
 
 	query := `INSERT INTO categories (name, user_id) VALUES ($1, $2) RETURNING id, name, user_id, created_at, updated_at`
-	err := r.db.QueryRow(query, categoryData.Name, userID).Scan( // Use categoryData.Name and userID
+	err := db.QueryRow(query, categoryData.Name, userID).Scan( // Use categoryData.Name and userID
 		&newCategory.ID,
 		&newCategory.Name,
 		&newCategory.UserID,
@@ -168,3 +184,45 @@ func (r *PostgresRepository) DeleteCategory(id int, userID int) *customerror.Cus
 
 	return nil
 }
+
+// ExportCategories streams the categories of a user with their number of
+// products to write, ordered by name
+func (r *PostgresRepository) ExportCategories(userID int, write func(*ExportedCategory) error) *customerror.CustomError {
+	query := `
+		SELECT c.id, c.name, c.user_id, c.created_at, c.updated_at,
+			(SELECT COUNT(*) FROM products p WHERE p.category_id = c.id)
+		FROM categories c
+		WHERE c.user_id = $1
+		ORDER BY c.name
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category ExportedCategory
+		err := rows.Scan(
+			&category.ID,
+			&category.Name,
+			&category.UserID,
+			&category.CreatedAt,
+			&category.UpdatedAt,
+			&category.ProductCount,
+		)
+		if err != nil {
+			return customerror.NewPostgresError(err)
+		}
+		if err := write(&category); err != nil {
+			return customerror.NewCustomError(err, "Failed to write export", http.StatusInternalServerError)
+		}
+	}
+
+	// Check for errors during row iteration
+	if err := rows.Err(); err != nil {
+		return customerror.NewPostgresError(err)
+	}
+
+	return nil
+}
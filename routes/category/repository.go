@@ -1,6 +1,10 @@
 package category
 
-import "github.com/yantology/simple-pos/pkg/customerror"
+import (
+	"database/sql"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
 
 // CategoryRepository implements the Repository interface
 type CategoryRepository struct {
@@ -32,6 +36,11 @@ func (r *CategoryRepository) CreateCategory(category *CreateCategory, userID int
 	return r.postgres.CreateCategory(category, userID)
 }
 
+// CreateCategoryTx creates a new category inside a transaction
+func (r *CategoryRepository) CreateCategoryTx(tx *sql.Tx, category *CreateCategory, userID int) (*Category, *customerror.CustomError) {
+	return r.postgres.CreateCategoryTx(tx, category, userID)
+}
+
 // UpdateCategory updates an existing category, passing userID for authorization
 func (r *CategoryRepository) UpdateCategory(id int, userID int, category *UpdateCategoryRequest) (*Category, *customerror.CustomError) {
 	return r.postgres.UpdateCategory(id, userID, category)
@@ -41,3 +50,8 @@ func (r *CategoryRepository) UpdateCategory(id int, userID int, category *Update
 func (r *CategoryRepository) DeleteCategory(id int, userID int) *customerror.CustomError {
 	return r.postgres.DeleteCategory(id, userID)
 }
+
+// ExportCategories streams the categories of a specific user
+func (r *CategoryRepository) ExportCategories(userID int, write func(*ExportedCategory) error) *customerror.CustomError {
+	return r.postgres.ExportCategories(userID, write)
+}
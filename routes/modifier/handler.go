@@ -0,0 +1,236 @@
+package modifier
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+)
+
+// Handler holds the dependencies for the modifier list handlers
+type Handler struct {
+	repository Repository
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(repository Repository) *Handler {
+	return &Handler{
+		repository: repository,
+	}
+}
+
+// RegisterRoutes sets up all the routes for modifier list management
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetAllModifierLists)
+	router.GET("/:id", h.GetModifierListByID)
+	router.POST("", h.CreateModifierList)
+	router.PUT("/:id", h.UpdateModifierList)
+	router.DELETE("/:id", h.DeleteModifierList)
+}
+
+// @Summary Get all modifier lists
+// @Description Retrieves all modifier lists of the authenticated user with their modifiers.
+// @Tags modifiers
+// @Produce json
+// @Success 200 {object} dto.DataResponse[[]ModifierList] "Successfully retrieved modifier lists"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /modifier-lists [get]
+func (h *Handler) GetAllModifierLists(c *gin.Context) {
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	lists, customErr := h.repository.GetAll(userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]ModifierList]{Data: lists})
+}
+
+// @Summary Get modifier list by ID
+// @Description Retrieves a specific modifier list of the authenticated user with its modifiers.
+// @Tags modifiers
+// @Produce json
+// @Param id path int true "Modifier list ID"
+// @Success 200 {object} dto.DataResponse[ModifierList] "Successfully retrieved modifier list"
+// @Failure 400 {object} dto.MessageResponse "Invalid modifier list ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Modifier list not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /modifier-lists/{id} [get]
+func (h *Handler) GetModifierListByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid modifier list ID format"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	list, customErr := h.repository.GetByID(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*ModifierList]{Data: list})
+}
+
+// @Summary Create a new modifier list
+// @Description Creates a list of add-on modifiers, such as extra shot or no sugar, with optional prices and selection limits, offered on the given products and categories.
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Param list body SaveModifierList true "Modifier list details"
+// @Success 201 {object} dto.DataResponse[ModifierList] "Modifier list created successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /modifier-lists [post]
+func (h *Handler) CreateModifierList(c *gin.Context) {
+	var request SaveModifierList
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	if _, err := request.Group(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid modifier list: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	list, customErr := h.repository.Create(&request, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DataResponse[*ModifierList]{Data: list})
+}
+
+// @Summary Update an existing modifier list
+// @Description Replaces a modifier list of the authenticated user and its modifiers. Orders already created keep the modifiers they were sold with.
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Param id path int true "Modifier list ID"
+// @Param list body SaveModifierList true "Updated modifier list details"
+// @Success 200 {object} dto.DataResponse[ModifierList] "Modifier list updated successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Modifier list not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /modifier-lists/{id} [put]
+func (h *Handler) UpdateModifierList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid modifier list ID format"})
+		return
+	}
+
+	var request SaveModifierList
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	if _, err := request.Group(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid modifier list: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	list, customErr := h.repository.Update(id, userID, &request)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*ModifierList]{Data: list})
+}
+
+// @Summary Delete a modifier list
+// @Description Deletes a modifier list of the authenticated user. Orders already created keep the modifiers they were sold with.
+// @Tags modifiers
+// @Produce json
+// @Param id path int true "Modifier list ID"
+// @Success 200 {object} dto.MessageResponse "Modifier list deleted successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid modifier list ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Modifier list not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /modifier-lists/{id} [delete]
+func (h *Handler) DeleteModifierList(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid modifier list ID format"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	if customErr := h.repository.Delete(id, userID); customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Modifier list deleted successfully"})
+}
@@ -0,0 +1,12 @@
+package modifier
+
+import "github.com/yantology/simple-pos/pkg/customerror"
+
+// Repository defines the data access methods for modifier lists
+type Repository interface {
+	GetAll(userID int) ([]ModifierList, *customerror.CustomError)
+	GetByID(id int, userID int) (*ModifierList, *customerror.CustomError)
+	Create(list *SaveModifierList, userID int) (*ModifierList, *customerror.CustomError)
+	Update(id int, userID int, list *SaveModifierList) (*ModifierList, *customerror.CustomError)
+	Delete(id int, userID int) *customerror.CustomError
+}
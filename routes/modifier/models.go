@@ -0,0 +1,77 @@
+package modifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+)
+
+// ModifierList is a reusable set of add-ons such as "Extras" or "Sugar",
+// offered on order lines of the products and categories it is attached to.
+// Between MinSelect and MaxSelect modifiers are chosen; MaxSelect zero lets
+// every modifier be chosen.
+// @Description Modifier list model
+type ModifierList struct {
+	ID          int             `json:"id" example:"1"`
+	Name        string          `json:"name" example:"Extras"`
+	Required    bool            `json:"required" example:"false"`
+	MinSelect   int             `json:"min_select" example:"0"`
+	MaxSelect   int             `json:"max_select" example:"0"`
+	Modifiers   []option.Option `json:"modifiers"`
+	ProductIDs  []int           `json:"product_ids"`
+	CategoryIDs []int           `json:"category_ids" example:"1"`
+	Position    int             `json:"position" example:"0"`
+	UserID      int             `json:"user_id" example:"1"`
+	CreatedAt   time.Time       `json:"created_at" example:"2025-05-14T15:04:05Z07:00"`
+	UpdatedAt   time.Time       `json:"updated_at" example:"2025-05-14T15:04:05Z07:00"`
+}
+
+// SaveModifier is one modifier of a list. IsAvailable defaults to true.
+type SaveModifier struct {
+	Name        string      `json:"name" binding:"required,max=100" example:"Extra shot"`
+	PriceDelta  money.Money `json:"price_delta" binding:"money"`
+	IsAvailable *bool       `json:"is_available" example:"true"`
+}
+
+// SaveModifierList defines the structure for creating or replacing a
+// modifier list. Modifiers are listed in display order and replace the
+// list's previous modifiers; orders keep the modifiers they were sold with.
+// @Description Save modifier list request model
+type SaveModifierList struct {
+	Name        string         `json:"name" binding:"required,max=100" example:"Extras"`
+	Required    bool           `json:"required" example:"false"`
+	MinSelect   int            `json:"min_select" binding:"min=0" example:"0"`
+	MaxSelect   int            `json:"max_select" binding:"min=0" example:"2"`
+	Modifiers   []SaveModifier `json:"modifiers" binding:"required,min=1,max=50,dive"`
+	ProductIDs  []int          `json:"product_ids" binding:"omitempty,dive,gt=0"`
+	CategoryIDs []int          `json:"category_ids" binding:"omitempty,dive,gt=0" example:"1"`
+	Position    int            `json:"position" example:"0"`
+}
+
+// Group converts the request into a validated option group, the form the
+// order pricing selects modifiers from
+func (r *SaveModifierList) Group() (option.Group, error) {
+	group := option.Group{
+		Name:      strings.TrimSpace(r.Name),
+		Required:  r.Required,
+		MinSelect: r.MinSelect,
+		MaxSelect: r.MaxSelect,
+	}
+	for _, modifier := range r.Modifiers {
+		group.Options = append(group.Options, option.Option{
+			Name:        strings.TrimSpace(modifier.Name),
+			PriceDelta:  modifier.PriceDelta,
+			IsAvailable: modifier.IsAvailable == nil || *modifier.IsAvailable,
+		})
+	}
+	if err := group.Validate(); err != nil {
+		return group, err
+	}
+	if len(r.ProductIDs) == 0 && len(r.CategoryIDs) == 0 {
+		return group, fmt.Errorf("modifier list %s must be attached to at least one product or category", group.Name)
+	}
+	return group, nil
+}
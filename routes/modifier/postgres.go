@@ -0,0 +1,258 @@
+package modifier
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/option"
+)
+
+// PostgresRepository implements the Repository interface using PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository instance
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &PostgresRepository{db: db}
+}
+
+const listColumns = `
+	id, name, required, min_select, max_select, product_ids, category_ids,
+	position, user_id, created_at, updated_at`
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// scanList reads a modifier list selected with listColumns
+func scanList(row scanner) (*ModifierList, error) {
+	var list ModifierList
+	var productIDs, categoryIDs pq.Int64Array
+	err := row.Scan(
+		&list.ID,
+		&list.Name,
+		&list.Required,
+		&list.MinSelect,
+		&list.MaxSelect,
+		&productIDs,
+		&categoryIDs,
+		&list.Position,
+		&list.UserID,
+		&list.CreatedAt,
+		&list.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	list.ProductIDs = toInts(productIDs)
+	list.CategoryIDs = toInts(categoryIDs)
+	list.Modifiers = []option.Option{}
+	return &list, nil
+}
+
+// loadModifiers fills in the modifiers of the lists in display order
+func loadModifiers(db querier, lists []*ModifierList) error {
+	if len(lists) == 0 {
+		return nil
+	}
+	byID := make(map[int]*ModifierList, len(lists))
+	ids := make([]int64, len(lists))
+	for i, list := range lists {
+		byID[list.ID] = list
+		ids[i] = int64(list.ID)
+	}
+
+	rows, err := db.Query(`
+		SELECT list_id, id, name, price_delta, is_available
+		FROM modifiers
+		WHERE list_id = ANY($1)
+		ORDER BY list_id, position, id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var listID int
+		var modifier option.Option
+		if err := rows.Scan(&listID, &modifier.ID, &modifier.Name, &modifier.PriceDelta, &modifier.IsAvailable); err != nil {
+			return err
+		}
+		list := byID[listID]
+		list.Modifiers = append(list.Modifiers, modifier)
+	}
+	return rows.Err()
+}
+
+// GetAll retrieves all modifier lists of a user in display order
+func (r *PostgresRepository) GetAll(userID int) ([]ModifierList, *customerror.CustomError) {
+	query := `SELECT ` + listColumns + ` FROM modifier_lists WHERE user_id = $1 ORDER BY position, id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var found []*ModifierList
+	for rows.Next() {
+		list, err := scanList(rows)
+		if err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		found = append(found, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	rows.Close()
+
+	if err := loadModifiers(r.db, found); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	lists := make([]ModifierList, len(found))
+	for i, list := range found {
+		lists[i] = *list
+	}
+	return lists, nil
+}
+
+// GetByID retrieves a modifier list by its ID and user ID
+func (r *PostgresRepository) GetByID(id int, userID int) (*ModifierList, *customerror.CustomError) {
+	query := `SELECT ` + listColumns + ` FROM modifier_lists WHERE id = $1 AND user_id = $2`
+	list, err := scanList(r.db.QueryRow(query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(err, fmt.Sprintf("Modifier list with ID %d not found or user not authorized", id), http.StatusNotFound)
+		}
+		return nil, customerror.NewPostgresError(err)
+	}
+	if err := loadModifiers(r.db, []*ModifierList{list}); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return list, nil
+}
+
+// Create stores a new modifier list with its modifiers
+func (r *PostgresRepository) Create(data *SaveModifierList, userID int) (*ModifierList, *customerror.CustomError) {
+	query := `
+		INSERT INTO modifier_lists (name, required, min_select, max_select, product_ids, category_ids, position, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + listColumns
+	return r.save(data, func(tx *sql.Tx, group option.Group) (*ModifierList, error) {
+		return scanList(tx.QueryRow(query, append(listArgs(data, group), userID)...))
+	})
+}
+
+// Update replaces a modifier list and its modifiers, ensuring the user owns
+// it. Orders keep the modifiers they were sold with.
+func (r *PostgresRepository) Update(id int, userID int, data *SaveModifierList) (*ModifierList, *customerror.CustomError) {
+	query := `
+		UPDATE modifier_lists
+		SET name = $1, required = $2, min_select = $3, max_select = $4, product_ids = $5,
+			category_ids = $6, position = $7
+		WHERE id = $8 AND user_id = $9
+		RETURNING ` + listColumns
+	return r.save(data, func(tx *sql.Tx, group option.Group) (*ModifierList, error) {
+		return scanList(tx.QueryRow(query, append(listArgs(data, group), id, userID)...))
+	})
+}
+
+// save writes a modifier list with upsert and replaces its modifiers in one
+// transaction
+func (r *PostgresRepository) save(data *SaveModifierList, upsert func(tx *sql.Tx, group option.Group) (*ModifierList, error)) (*ModifierList, *customerror.CustomError) {
+	group, err := data.Group()
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "Invalid modifier list: "+err.Error(), http.StatusBadRequest)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	list, err := upsert(tx, group)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(nil, "Modifier list not found or user not authorized to update", http.StatusNotFound)
+		}
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM modifiers WHERE list_id = $1`, list.ID); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	query := `
+		INSERT INTO modifiers (list_id, name, price_delta, is_available, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+	for i, modifier := range group.Options {
+		if err := tx.QueryRow(query, list.ID, modifier.Name, modifier.PriceDelta, modifier.IsAvailable, i).Scan(&modifier.ID); err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		list.Modifiers = append(list.Modifiers, modifier)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return list, nil
+}
+
+// Delete removes a modifier list and its modifiers, ensuring the user owns it
+func (r *PostgresRepository) Delete(id int, userID int) *customerror.CustomError {
+	result, err := r.db.Exec(`DELETE FROM modifier_lists WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return customerror.NewPostgresError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return customerror.NewCustomError(err, fmt.Sprintf("Error getting rows affected: %v", err), http.StatusInternalServerError)
+	}
+
+	if rowsAffected == 0 {
+		return customerror.NewCustomError(nil, "Modifier list not found or user not authorized to delete", http.StatusNotFound)
+	}
+
+	return nil
+}
+
+// listArgs returns the column values of a modifier list request in insert order
+func listArgs(data *SaveModifierList, group option.Group) []any {
+	return []any{
+		group.Name,
+		group.Required,
+		group.MinSelect,
+		group.MaxSelect,
+		pq.Array(toInt64s(data.ProductIDs)),
+		pq.Array(toInt64s(data.CategoryIDs)),
+		data.Position,
+	}
+}
+
+func toInts(values pq.Int64Array) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
+}
+
+func toInt64s(values []int) []int64 {
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i] = int64(v)
+	}
+	return ints
+}
@@ -0,0 +1,40 @@
+package modifier
+
+import "github.com/yantology/simple-pos/pkg/customerror"
+
+// repository implements the Repository interface
+type repository struct {
+	database Repository
+}
+
+// NewRepository creates a new repository instance
+func NewRepository(db Repository) Repository {
+	return &repository{
+		database: db,
+	}
+}
+
+// GetAll calls the database GetAll method
+func (r *repository) GetAll(userID int) ([]ModifierList, *customerror.CustomError) {
+	return r.database.GetAll(userID)
+}
+
+// GetByID calls the database GetByID method
+func (r *repository) GetByID(id int, userID int) (*ModifierList, *customerror.CustomError) {
+	return r.database.GetByID(id, userID)
+}
+
+// Create calls the database Create method
+func (r *repository) Create(list *SaveModifierList, userID int) (*ModifierList, *customerror.CustomError) {
+	return r.database.Create(list, userID)
+}
+
+// Update calls the database Update method
+func (r *repository) Update(id int, userID int, list *SaveModifierList) (*ModifierList, *customerror.CustomError) {
+	return r.database.Update(id, userID, list)
+}
+
+// Delete calls the database Delete method
+func (r *repository) Delete(id int, userID int) *customerror.CustomError {
+	return r.database.Delete(id, userID)
+}
@@ -0,0 +1,192 @@
+package order
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/money"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// OrderFilter narrows the order list. From and To are inclusive dates; To is
+// extended to the end of its day. Search matches part of the order number or
+// of an item name, ignoring case.
+type OrderFilter struct {
+	From          *time.Time
+	To            *time.Time
+	Statuses      []OrderStatus
+	PaymentMethod PaymentMethod
+	MinTotal      *money.Money
+	MaxTotal      *money.Money
+	Search        string
+	Cursor        *orderCursor
+	Limit         int
+}
+
+// OrderPage is one page of the order list. NextCursor is empty on the last page.
+type OrderPage struct {
+	Orders     []*Order
+	NextCursor string
+}
+
+// orderCursor is the position after the last order of a page. Orders are
+// listed newest first, with the ID breaking ties between equal times.
+type orderCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// encode returns the opaque cursor string handed to clients
+func (c orderCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.ID)))
+}
+
+// decodeOrderCursor parses a cursor string produced by encode
+func decodeOrderCursor(value string) (*orderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, errors.New("invalid cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	orderID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &orderCursor{CreatedAt: time.Unix(0, n).UTC(), ID: orderID}, nil
+}
+
+// parseOrderFilter reads the order list filters from query parameters
+func parseOrderFilter(query url.Values) (*OrderFilter, error) {
+	filter := &OrderFilter{Limit: defaultPageSize, Search: strings.TrimSpace(query.Get("search"))}
+
+	parseDate := func(name string) (*time.Time, error) {
+		value := query.Get(name)
+		if value == "" {
+			return nil, nil
+		}
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a date such as 2026-10-17", name)
+		}
+		return &date, nil
+	}
+	var err error
+	if filter.From, err = parseDate("from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseDate("to"); err != nil {
+		return nil, err
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return nil, errors.New("to must not be before from")
+	}
+
+	if value := query.Get("status"); value != "" {
+		for _, status := range strings.Split(value, ",") {
+			status := OrderStatus(strings.TrimSpace(status))
+			if !status.IsValid() {
+				return nil, fmt.Errorf("unknown status: %s", status)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if value := query.Get("payment_method"); value != "" {
+		filter.PaymentMethod = PaymentMethod(value)
+		if !filter.PaymentMethod.IsValid() {
+			return nil, fmt.Errorf("unsupported payment method: %s", value)
+		}
+	}
+
+	parseTotal := func(name string) (*money.Money, error) {
+		value := query.Get(name)
+		if value == "" {
+			return nil, nil
+		}
+		total, err := money.Parse(value, money.DefaultCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an amount: %v", name, err)
+		}
+		return &total, nil
+	}
+	if filter.MinTotal, err = parseTotal("min_total"); err != nil {
+		return nil, err
+	}
+	if filter.MaxTotal, err = parseTotal("max_total"); err != nil {
+		return nil, err
+	}
+
+	if value := query.Get("limit"); value != "" {
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit < 1 || filter.Limit > maxPageSize {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		if filter.Cursor, err = decodeOrderCursor(value); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// where builds the SQL conditions and arguments of the filter for orders of
+// the given user
+func (f *OrderFilter) where(userID int) (string, []any) {
+	conditions := []string{"user_id = $1"}
+	args := []any{userID}
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", f.To.AddDate(0, 0, 1))
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, len(f.Statuses))
+		for i, status := range f.Statuses {
+			statuses[i] = string(status)
+		}
+		add("status = ANY($%d)", pq.Array(statuses))
+	}
+	if f.PaymentMethod != "" {
+		add("EXISTS (SELECT 1 FROM payments p WHERE p.order_id = orders.id AND p.method = $%d)", f.PaymentMethod)
+	}
+	if f.MinTotal != nil {
+		add("total >= $%d", f.MinTotal.Amount)
+	}
+	if f.MaxTotal != nil {
+		add("total <= $%d", f.MaxTotal.Amount)
+	}
+	if f.Search != "" {
+		add(`(STRPOS(LOWER(order_number), LOWER($%[1]d)) > 0
+            OR EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = orders.id AND STRPOS(LOWER(oi.name), LOWER($%[1]d)) > 0))`, f.Search)
+	}
+	if f.Cursor != nil {
+		args = append(args, f.Cursor.CreatedAt, f.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/yantology/simple-pos/pkg/dto"
+	"github.com/yantology/simple-pos/pkg/receipt"
+	"github.com/yantology/simple-pos/pkg/resendutils"
 )
 
 // RegisterRoutes registers all order routes
@@ -14,30 +17,66 @@ func (h *orderHandler) RegisterRoutes(router *gin.RouterGroup) {
 	fmt.Println("RegisterRoutes: Starting...") // Add log
 
 	router.GET("/", h.GetOrders)
+	router.GET("/held", h.GetHeldOrders)
 	router.GET("/:id", h.GetOrderByID)
 	router.POST("/", h.CreateOrder)
 	router.DELETE("/:id", h.DeleteOrder)
+	router.PUT("/:id/items", h.UpdateOrderItems)
+	router.POST("/:id/hold", h.HoldOrder)
+	router.POST("/:id/resume", h.ResumeOrder)
+	router.POST("/:id/discard", h.DiscardOrder)
+	router.POST("/:id/pay", h.PayOrder)
+	router.POST("/:id/void", h.VoidOrder)
+	router.POST("/:id/refund", h.RefundOrder)
+	router.POST("/:id/refunds", h.CreateRefund)
+	router.GET("/:id/payments", h.GetPayments)
+	router.POST("/:id/payments", h.RecordPayments)
+	router.GET("/:id/receipt", h.GetReceipt)
+	router.GET("/:id/kitchen-ticket", h.GetKitchenTicket)
+	router.POST("/:id/receipt/email", h.EmailReceipt)
 
 }
 
+// RegisterSyncRoutes registers the routes terminals use to upload offline sales
+func (h *orderHandler) RegisterSyncRoutes(router *gin.RouterGroup) {
+	router.POST("/orders", h.SyncOrders)
+}
+
 type orderHandler struct {
 	orderRepository OrderRepository
+	emailSender     resendutils.ResendUtilsInterface
+	emailTemplate   EmailTemplateInterface
 }
 
 // NewOrderHandler creates a new order handler
-func NewOrderHandler(repository OrderRepository) *orderHandler {
+func NewOrderHandler(
+	repository OrderRepository,
+	emailSender resendutils.ResendUtilsInterface,
+	emailTemplate EmailTemplateInterface,
+) *orderHandler {
 	fmt.Println("NewOrderHandler: Starting...") // Add log
 	return &orderHandler{
 		orderRepository: repository,
+		emailSender:     emailSender,
+		emailTemplate:   emailTemplate,
 	}
 }
 
-// @Summary Get all orders for the authenticated user
-// @Description Retrieves a list of all orders associated with the logged-in user.
+// @Summary Get orders for the authenticated user
+// @Description Retrieves the logged-in user's orders, newest first, one page at a time. Pass the returned next_cursor as cursor to fetch the following page; it is empty on the last page. Search matches part of the order number (so a customer can quote "0042" at pickup) or of an item name.
 // @Tags orders
 // @Produce json
-// @Success 200 {object} dto.DataResponse[[]Order] "Successfully retrieved orders"
-// @Failure 400 {object} dto.MessageResponse "User ID is required"
+// @Param from query string false "First day, such as 2026-10-01"
+// @Param to query string false "Last day, inclusive, such as 2026-10-31"
+// @Param status query string false "Comma-separated statuses, such as paid,refunded"
+// @Param payment_method query string false "Only orders with a payment of this method" Enums(cash, card, qris, e_wallet, store_credit)
+// @Param min_total query string false "Minimum grand total"
+// @Param max_total query string false "Maximum grand total"
+// @Param search query string false "Part of the order number or an item name, case-insensitive"
+// @Param limit query int false "Orders per page (1-200)" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} dto.PageResponse[[]Order] "Successfully retrieved orders"
+// @Failure 400 {object} dto.MessageResponse "Invalid filter or cursor"
 // @Failure 401 {object} dto.MessageResponse "Unauthorized"
 // @Failure 500 {object} dto.MessageResponse "Internal Server Error"
 // @Router /orders [get]
@@ -58,9 +97,15 @@ func (h *orderHandler) GetOrders(c *gin.Context) {
 	}
 	fmt.Printf("GetOrders: User ID retrieved: %d\n", userID) // Add log
 
+	filter, err := parseOrderFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid filter: " + err.Error()})
+		return
+	}
+
 	// ONLY the handler calls the repository
-	fmt.Println("GetOrders: Calling repository to get orders") // Add log
-	orders, customErr := h.orderRepository.GetOrders(userID)   // Pass int userID
+	fmt.Println("GetOrders: Calling repository to get orders")     // Add log
+	page, customErr := h.orderRepository.GetOrders(userID, filter) // Pass int userID
 	if customErr != nil {
 		fmt.Printf("GetOrders: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
 		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
@@ -69,6 +114,37 @@ func (h *orderHandler) GetOrders(c *gin.Context) {
 
 	// Return raw orders directly
 	fmt.Println("GetOrders: Orders retrieved successfully") // Add log
+	c.JSON(http.StatusOK, dto.PageResponse[[]*Order]{Data: page.Orders, NextCursor: page.NextCursor})
+}
+
+// @Summary List held orders
+// @Description Retrieves the parked baskets of the authenticated user with their labels and lines, most recently touched first.
+// @Tags orders
+// @Produce json
+// @Success 200 {object} dto.DataResponse[[]Order] "Successfully retrieved held orders"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/held [get]
+func (h *orderHandler) GetHeldOrders(c *gin.Context) {
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	orders, customErr := h.orderRepository.GetHeldOrders(userID)
+	if customErr != nil {
+		fmt.Printf("GetHeldOrders: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
 	c.JSON(http.StatusOK, dto.DataResponse[[]*Order]{Data: orders})
 }
 
@@ -122,14 +198,16 @@ func (h *orderHandler) GetOrderByID(c *gin.Context) {
 }
 
 // @Summary Create a new order
-// @Description Creates a new order for the authenticated user.
+// @Description Creates a new order for the authenticated user. Only product IDs, variant IDs, chosen option and modifier IDs, quantities and line notes are accepted; names, categories, prices and totals are resolved from the user's products. Products with variants must be ordered by variant, options must satisfy the product's option groups and modifiers the modifier lists attached to the product or its category; their price deltas are added to the unit price. Products sold by weight or measure take decimal quantities, or a scale_code printed by one of the store's scales in place of the quantity. Active promotions are applied automatically, and an optional manual discount with a reason can be given per line or for the whole order. Service charge, PPN and rounding follow the user's store settings. With hold set the basket is parked under the given label instead of being left open for payment. Products that track stock are taken out of stock with the order; unless the store allows negative stock, an order for more than is on hand is rejected.
 // @Tags orders
 // @Accept json
 // @Produce json
-// @Param order body CreateOrder true "Order details"
+// @Param order body CreateOrder true "Order items"
 // @Success 201 {object} dto.DataResponse[Order] "Order created successfully"
-// @Failure 400 {object} dto.MessageResponse "Invalid request data"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or product not available"
 // @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 409 {object} dto.MessageResponse "Not enough stock"
 // @Failure 500 {object} dto.MessageResponse "Internal Server Error"
 // @Router /orders [post]
 func (h *orderHandler) CreateOrder(c *gin.Context) {
@@ -170,7 +248,7 @@ func (h *orderHandler) CreateOrder(c *gin.Context) {
 }
 
 // @Summary Delete an order
-// @Description Deletes an order by its ID for the authenticated user.
+// @Description Deletes an open order by its ID for the authenticated user. Its units are put back into stock. Orders that already took a payment cannot be deleted, and completed sales must be voided or refunded instead.
 // @Tags orders
 // @Produce json
 // @Param id path int true "Order ID"
@@ -178,6 +256,7 @@ func (h *orderHandler) CreateOrder(c *gin.Context) {
 // @Failure 400 {object} dto.MessageResponse "Invalid order ID format"
 // @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context or not owner"
 // @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not open or has payments"
 // @Failure 500 {object} dto.MessageResponse "Internal Server Error"
 // @Router /orders/{id} [delete]
 func (h *orderHandler) DeleteOrder(c *gin.Context) {
@@ -216,3 +295,699 @@ func (h *orderHandler) DeleteOrder(c *gin.Context) {
 	fmt.Println("DeleteOrder: Order deleted successfully") // Add log
 	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Order deleted successfully"})
 }
+
+// @Summary Mark an order as paid
+// @Description Moves an open order to paid once its recorded payments cover the total. The change is recorded with the acting user and an optional reason.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param transition body OrderTransitionRequest false "Optional note"
+// @Success 200 {object} dto.DataResponse[Order] "Order marked as paid"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format or request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Transition not allowed or order not fully paid"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/pay [post]
+func (h *orderHandler) PayOrder(c *gin.Context) {
+	h.transitionOrder(c, OrderTransition{To: StatusPaid}, false)
+}
+
+// @Summary Void an order
+// @Description Voids an open order that has no payments. A reason is required and recorded with the acting user, and its units are put back into stock.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param transition body OrderTransitionRequest true "Void reason"
+// @Success 200 {object} dto.DataResponse[Order] "Order voided"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format or missing reason"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Transition not allowed"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/void [post]
+func (h *orderHandler) VoidOrder(c *gin.Context) {
+	h.transitionOrder(c, OrderTransition{To: StatusVoided}, true)
+}
+
+// @Summary Replace the lines of an order
+// @Description Replaces the lines of an open or held order that has no payments and reprices it with the current promotions and store settings. A non-empty label renames a held basket; hold is ignored. Stock moves by the difference between the old and new lines.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param order body CreateOrder true "New order lines"
+// @Success 200 {object} dto.DataResponse[Order] "Order updated"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or product not available"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order or product not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not open or held, already has payments, or not enough stock"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/items [put]
+func (h *orderHandler) UpdateOrderItems(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	var req CreateOrder
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	order, customErr := h.orderRepository.UpdateOrderItems(id, userID, &req)
+	if customErr != nil {
+		fmt.Printf("UpdateOrderItems: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[Order]{Data: *order})
+}
+
+// @Summary Hold an order
+// @Description Parks an open order that has no payments under a label such as "table 4" so the cashier can serve the next customer. Held orders do not count as sales until they are resumed and paid.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param hold body HoldOrderRequest true "Basket label"
+// @Success 200 {object} dto.DataResponse[Order] "Order held"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format or missing label"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not open or already has payments"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/hold [post]
+func (h *orderHandler) HoldOrder(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	var req HoldOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	order, customErr := h.orderRepository.HoldOrder(id, userID, req.Label)
+	if customErr != nil {
+		fmt.Printf("HoldOrder: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[Order]{Data: *order})
+}
+
+// @Summary Resume a held order
+// @Description Moves a held order back to open so its lines can be edited and payments recorded.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param transition body OrderTransitionRequest false "Optional note"
+// @Success 200 {object} dto.DataResponse[Order] "Order resumed"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format or request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not held"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/resume [post]
+func (h *orderHandler) ResumeOrder(c *gin.Context) {
+	h.transitionOrder(c, OrderTransition{From: StatusHeld, To: StatusOpen, Reason: "Resumed"}, false)
+}
+
+// @Summary Discard a held order
+// @Description Voids a held basket the customer no longer wants. The order is kept for the audit trail but never counts as a sale, and its units are put back into stock.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param transition body OrderTransitionRequest false "Optional reason"
+// @Success 200 {object} dto.DataResponse[Order] "Order discarded"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format or request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not held"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/discard [post]
+func (h *orderHandler) DiscardOrder(c *gin.Context) {
+	h.transitionOrder(c, OrderTransition{From: StatusHeld, To: StatusVoided, Reason: "Discarded held order"}, false)
+}
+
+// @Summary Refund an order
+// @Description Fully refunds every line of a paid order that has not been refunded yet. A reason and refund tender are required; line items in the body are ignored.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param refund body CreateRefund true "Refund reason and tender"
+// @Success 200 {object} dto.DataResponse[Order] "Order refunded"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format or request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order cannot be refunded"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/refund [post]
+func (h *orderHandler) RefundOrder(c *gin.Context) {
+	fmt.Println("RefundOrder: Starting...") // Add log
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	var req CreateRefund
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	req.Items = nil
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	if _, customErr := h.orderRepository.CreateRefund(id, userID, &req); customErr != nil {
+		fmt.Printf("RefundOrder: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	order, customErr := h.orderRepository.GetOrderByID(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	fmt.Printf("RefundOrder: Order %d is now %s\n", order.ID, order.Status) // Add log
+	c.JSON(http.StatusOK, dto.DataResponse[Order]{Data: *order})
+}
+
+// @Summary Refund order lines
+// @Description Returns the given quantities of one or more order lines. The refund is linked to the original order, cannot exceed the quantities sold, moves the order to partially_refunded or refunded, and puts the returned units back into stock.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param refund body CreateRefund true "Lines, quantities, tender and reason"
+// @Success 201 {object} dto.DataResponse[Refund] "Refund created"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or quantity exceeds what was sold"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order or order line not found"
+// @Failure 409 {object} dto.MessageResponse "Order cannot be refunded"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/refunds [post]
+func (h *orderHandler) CreateRefund(c *gin.Context) {
+	fmt.Println("CreateRefund: Starting...") // Add log
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	var req CreateRefund
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	if len(req.Items) == 0 {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "At least one order line is required"})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	refund, customErr := h.orderRepository.CreateRefund(id, userID, &req)
+	if customErr != nil {
+		fmt.Printf("CreateRefund: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	fmt.Printf("CreateRefund: Refund %d created for order %d\n", refund.ID, id) // Add log
+	c.JSON(http.StatusCreated, dto.DataResponse[Refund]{Data: *refund})
+}
+
+// @Summary Record payments for an order
+// @Description Records one or more tenders (cash, card, qris, e_wallet, store_credit) against an open order. Excess cash is returned as change, and the order moves to paid once the tenders cover its total.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param payments body CreatePayments true "Tenders"
+// @Success 201 {object} dto.DataResponse[PaymentResult] "Payments recorded"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format or tenders"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not open or has nothing due"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/payments [post]
+func (h *orderHandler) RecordPayments(c *gin.Context) {
+	fmt.Println("RecordPayments: Starting...") // Add log
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	var req CreatePayments
+	if err := c.ShouldBindJSON(&req); err != nil {
+		fmt.Printf("RecordPayments: Invalid request data: %v\n", err) // Add log
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	result, customErr := h.orderRepository.RecordPayments(id, userID, &req)
+	if customErr != nil {
+		fmt.Printf("RecordPayments: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	fmt.Printf("RecordPayments: Order %d has %s due\n", id, result.AmountDue) // Add log
+	c.JSON(http.StatusCreated, dto.DataResponse[PaymentResult]{Data: *result})
+}
+
+// @Summary Get payments for an order
+// @Description Retrieves the tenders recorded against an order of the authenticated user.
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} dto.DataResponse[[]Payment] "Successfully retrieved payments"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/payments [get]
+func (h *orderHandler) GetPayments(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	payments, customErr := h.orderRepository.GetPayments(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]Payment]{Data: payments})
+}
+
+// transitionOrder handles the shared flow of the status change endpoints.
+// transition.Reason is the default used when the request gives no reason.
+func (h *orderHandler) transitionOrder(c *gin.Context, transition OrderTransition, reasonRequired bool) {
+	fmt.Printf("transitionOrder: Starting transition to %s\n", transition.To) // Add log
+	idParam := c.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	var req OrderTransitionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+			return
+		}
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if reasonRequired && req.Reason == "" {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "A reason is required to " + transitionVerb(transition.To) + " an order"})
+		return
+	}
+	if req.Reason != "" {
+		transition.Reason = req.Reason
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	order, customErr := h.orderRepository.TransitionOrder(id, userID, &transition)
+	if customErr != nil {
+		fmt.Printf("transitionOrder: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	fmt.Printf("transitionOrder: Order %d is now %s\n", order.ID, order.Status) // Add log
+	c.JSON(http.StatusOK, dto.DataResponse[Order]{Data: *order})
+}
+
+// transitionVerb returns the action name used in messages for a target status
+func transitionVerb(to OrderStatus) string {
+	switch to {
+	case StatusPaid:
+		return "pay"
+	case StatusOpen:
+		return "resume"
+	}
+	return "void"
+}
+
+// @Summary Render an order receipt
+// @Description Renders the receipt of a paid, partially refunded or refunded order with the store header, lines, discounts, service charge, tax, tenders, change and footer. format=text returns plain text, format=escpos returns raw ESC/POS printer bytes and format=html returns a printable page. Text and ESC/POS receipts are laid out for the paper size (58 or 80 mm) unless an explicit column width is given.
+// @Tags orders
+// @Produce plain
+// @Produce octet-stream
+// @Produce html
+// @Param id path int true "Order ID"
+// @Param format query string false "Receipt format" Enums(text, escpos, html) default(text)
+// @Param paper query int false "Paper width in millimetres" Enums(58, 80) default(80)
+// @Param width query int false "Characters per line, overrides paper (24-64)"
+// @Success 200 {string} string "Rendered receipt"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID, format, paper or width"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not a paid sale"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/receipt [get]
+func (h *orderHandler) GetReceipt(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	format := ReceiptFormat(c.DefaultQuery("format", string(ReceiptText)))
+	if format != ReceiptText && format != ReceiptESCPOS && format != ReceiptHTML {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid format: expected text, escpos or html"})
+		return
+	}
+
+	paper, err := strconv.Atoi(c.DefaultQuery("paper", "80"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid paper size: expected 58 or 80"})
+		return
+	}
+	width, err := receipt.WidthForPaper(paper)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid paper size: expected 58 or 80"})
+		return
+	}
+	if value := c.Query("width"); value != "" {
+		width, err = strconv.Atoi(value)
+		if err != nil || width < receipt.MinWidth || width > receipt.MaxWidth {
+			c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: fmt.Sprintf("Invalid width: expected %d to %d characters", receipt.MinWidth, receipt.MaxWidth)})
+			return
+		}
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	rendered, customErr := h.orderRepository.GetReceipt(id, userID)
+	if customErr != nil {
+		fmt.Printf("GetReceipt: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	switch format {
+	case ReceiptESCPOS:
+		c.Data(http.StatusOK, "application/octet-stream", receipt.ESCPOS(rendered, width))
+	case ReceiptHTML:
+		page, err := receipt.HTML(rendered)
+		if err != nil {
+			fmt.Printf("GetReceipt: Error rendering HTML: %v\n", err) // Add log
+			c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Failed to render receipt"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	default:
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(receipt.Text(rendered, width)))
+	}
+}
+
+// @Summary Render a kitchen ticket
+// @Description Renders the ticket the kitchen or bar prepares an order from: the order number, label and time, then each line with its quantity, variant, options, modifiers and the customer's note, without prices. format=text returns plain text and format=escpos returns raw ESC/POS printer bytes laid out for the paper size (58 or 80 mm).
+// @Tags orders
+// @Produce plain
+// @Produce octet-stream
+// @Param id path int true "Order ID"
+// @Param format query string false "Ticket format" Enums(text, escpos) default(text)
+// @Param paper query int false "Paper width in millimetres" Enums(58, 80) default(80)
+// @Success 200 {string} string "Rendered kitchen ticket"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID, format or paper"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /orders/{id}/kitchen-ticket [get]
+func (h *orderHandler) GetKitchenTicket(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	format := ReceiptFormat(c.DefaultQuery("format", string(ReceiptText)))
+	if format != ReceiptText && format != ReceiptESCPOS {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid format: expected text or escpos"})
+		return
+	}
+
+	paper, err := strconv.Atoi(c.DefaultQuery("paper", "80"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid paper size: expected 58 or 80"})
+		return
+	}
+	width, err := receipt.WidthForPaper(paper)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid paper size: expected 58 or 80"})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	order, customErr := h.orderRepository.GetOrderByID(id, userID)
+	if customErr != nil {
+		fmt.Printf("GetKitchenTicket: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	ticket := buildKitchenTicket(order)
+	if format == ReceiptESCPOS {
+		c.Data(http.StatusOK, "application/octet-stream", receipt.KitchenESCPOS(ticket, width))
+		return
+	}
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(receipt.KitchenText(ticket, width)))
+}
+
+// @Summary Email an order receipt
+// @Description Sends the HTML receipt of a paid, partially refunded or refunded order to the given address and records the send on the order.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param request body EmailReceiptRequest true "Recipient address"
+// @Success 201 {object} dto.DataResponse[ReceiptEmail] "Receipt sent"
+// @Failure 400 {object} dto.MessageResponse "Invalid order ID or email address"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Order not found"
+// @Failure 409 {object} dto.MessageResponse "Order is not a paid sale"
+// @Failure 500 {object} dto.MessageResponse "Failed to send email"
+// @Router /orders/{id}/receipt/email [post]
+func (h *orderHandler) EmailReceipt(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid order ID format"})
+		return
+	}
+
+	var req EmailReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request body: " + err.Error()})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	rendered, customErr := h.orderRepository.GetReceipt(id, userID)
+	if customErr != nil {
+		fmt.Printf("EmailReceipt: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	emailHTML, err := h.emailTemplate.GenerateReceiptEmail(rendered)
+	if err != nil {
+		fmt.Printf("EmailReceipt: Error rendering email: %v\n", err) // Add log
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Failed to render receipt"})
+		return
+	}
+	emailSubject := h.emailTemplate.GenerateReceiptSubject(rendered)
+
+	if customErr := h.emailSender.Send(emailHTML, emailSubject, []string{req.Email}); customErr != nil {
+		fmt.Printf("EmailReceipt: Error sending email: %s\n", customErr.Message()) // Add log
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Failed to send email"})
+		return
+	}
+
+	sent, customErr := h.orderRepository.RecordReceiptEmail(id, userID, req.Email)
+	if customErr != nil {
+		fmt.Printf("EmailReceipt: Error recording send: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DataResponse[ReceiptEmail]{Data: *sent})
+}
+
+// @Summary Upload offline sales
+// @Description Stores a batch of orders made on a terminal while it was offline. Each order carries a client-generated UUID and the terminal's sale time, which is kept as the order time. Orders already uploaded are reported as duplicates, invalid ones as rejected with a message, and the rest as accepted; tenders that cover the total mark the order paid, and orders with nothing due are paid without tenders.
+// @Tags sync
+// @Accept json
+// @Produce json
+// @Param batch body SyncOrders true "Offline orders"
+// @Success 200 {object} dto.DataResponse[[]SyncResult] "Per-order results, in request order"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /sync/orders [post]
+func (h *orderHandler) SyncOrders(c *gin.Context) {
+	var req SyncOrders
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Retrieve userID from authentication context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	results, customErr := h.orderRepository.SyncOrders(&req, userID)
+	if customErr != nil {
+		fmt.Printf("SyncOrders: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]SyncResult]{Data: results})
+}
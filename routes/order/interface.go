@@ -1,11 +1,26 @@
 package order
 
-import "github.com/yantology/simple-pos/pkg/customerror"
+import (
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/receipt"
+)
 
 // OrderRepository interface for order data operations
 type OrderRepository interface {
-	GetOrders(userID int) ([]*Order, *customerror.CustomError)
+	GetOrders(userID int, filter *OrderFilter) (*OrderPage, *customerror.CustomError)
+	GetHeldOrders(userID int) ([]*Order, *customerror.CustomError)
 	GetOrderByID(id int, userID int) (*Order, *customerror.CustomError)
 	CreateOrder(order *CreateOrder, userID int) (*Order, *customerror.CustomError)
+	SyncOrders(batch *SyncOrders, userID int) ([]SyncResult, *customerror.CustomError)
+	UpdateOrderItems(id int, userID int, order *CreateOrder) (*Order, *customerror.CustomError)
+	HoldOrder(id int, userID int, label string) (*Order, *customerror.CustomError)
 	DeleteOrder(id int, userID int) *customerror.CustomError
+	TransitionOrder(id int, userID int, transition *OrderTransition) (*Order, *customerror.CustomError)
+	RecordPayments(id int, userID int, request *CreatePayments) (*PaymentResult, *customerror.CustomError)
+	GetPayments(id int, userID int) ([]Payment, *customerror.CustomError)
+	CreateRefund(id int, userID int, request *CreateRefund) (*Refund, *customerror.CustomError)
+	GetReceipt(id int, userID int) (*receipt.Receipt, *customerror.CustomError)
+	RecordReceiptEmail(id int, userID int, email string) (*ReceiptEmail, *customerror.CustomError)
+	ExportOrders(userID int, filter *OrderFilter, write func(*ExportedOrder) error) *customerror.CustomError
+	ExportOrderItems(userID int, filter *OrderFilter, write func(*ExportedOrderItem) error) *customerror.CustomError
 }
@@ -0,0 +1,36 @@
+package order
+
+import (
+	"github.com/yantology/simple-pos/pkg/receipt"
+)
+
+// EmailTemplateInterface defines methods for generating receipt emails
+type EmailTemplateInterface interface {
+	GenerateReceiptSubject(r *receipt.Receipt) string
+	GenerateReceiptEmail(r *receipt.Receipt) (string, error)
+}
+
+type emailTemplate struct{}
+
+// NewEmailTemplate creates a new receipt email template generator
+func NewEmailTemplate() EmailTemplateInterface {
+	return &emailTemplate{}
+}
+
+// GenerateReceiptSubject creates the subject line of a receipt email
+func (e *emailTemplate) GenerateReceiptSubject(r *receipt.Receipt) string {
+	if r.Header.Name == "" {
+		return "Your receipt - " + r.Number
+	}
+	return "Your receipt from " + r.Header.Name + " - " + r.Number
+}
+
+// GenerateReceiptEmail creates the email body, the same HTML receipt served by
+// GET /orders/:id/receipt?format=html
+func (e *emailTemplate) GenerateReceiptEmail(r *receipt.Receipt) (string, error) {
+	page, err := receipt.HTML(r)
+	if err != nil {
+		return "", err
+	}
+	return string(page), nil
+}
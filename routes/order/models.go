@@ -1,39 +1,359 @@
 package order
 
-import "time"
+import (
+	"time"
 
-// Product represents a product within an order
-type Product struct {
-	ID         int    `json:"id"` // Changed from string to int
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/promo"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+// OrderItem represents a single line of an order stored in order_items.
+// Name, category and price are snapshotted at sale time; ProductID becomes
+// nil when the product is later deleted.
+type OrderItem struct {
+	ID         int    `json:"id"`
+	OrderID    int    `json:"order_id"`
+	ProductID  *int   `json:"product_id"`
 	Name       string `json:"name"`
-	Quantity   int    `json:"quantity"`
-	Price      int    `json:"price"`
+	CategoryID *int   `json:"category_id"`
 	Category   string `json:"category"`
-	TotalPrice int    `json:"total_price"`
+	// Quantity is in Unit, such as 0.35 kg; Price is per unit
+	Quantity   quantity.Quantity `json:"quantity" example:"0.35"`
+	Unit       quantity.Unit     `json:"unit" example:"kg"`
+	Price      money.Money       `json:"price"`
+	TotalPrice money.Money       `json:"total_price"`
+	// DiscountAmount is the line's own discounts plus its share of
+	// order-level discounts
+	DiscountAmount money.Money `json:"discount_amount"`
+	// TaxClass, ServiceCharge and TaxAmount are the line's tax treatment and
+	// its share of the order's service charge and tax
+	TaxClass      tax.Class   `json:"tax_class" example:"standard"`
+	ServiceCharge money.Money `json:"service_charge"`
+	TaxAmount     money.Money `json:"tax_amount"`
+	// RefundedQuantity is how much of this line was returned
+	RefundedQuantity quantity.Quantity `json:"refunded_quantity"`
+	// VariantID and Variant identify the variant sold. Options are the
+	// chosen options, whose price deltas are included in Price.
+	VariantID *int            `json:"variant_id"`
+	Variant   string          `json:"variant" example:"Large Hot"`
+	Options   []option.Choice `json:"options"`
+	// Modifiers are the chosen add-ons, whose price deltas are also included
+	// in Price. Note is the customer's instruction for the kitchen.
+	Modifiers []option.Choice `json:"modifiers"`
+	Note      string          `json:"note" example:"Less ice, please"`
 }
 
-// Order represents the structure of an order in the database
+// Order represents the structure of an order in the database.
+// Total is the grand total: subtotal minus discounts, plus service charge,
+// plus tax when prices are tax-exclusive, plus rounding.
 type Order struct {
-	ID        int       `json:"id"` // Changed from string to int
-	Total     float64   `json:"total"`
-	Product   []Product `json:"product"` // Reverted back to []Product
-	UserID    int       `json:"user_id"` // Changed from string to int
+	ID int `json:"id"` // Changed from string to int
+	// OrderNumber is the number quoted by customers and staff, unique per user
+	OrderNumber string `json:"order_number" example:"OUT1-20261017-0042"`
+	OutletCode  string `json:"outlet_code" example:"OUT1"`
+	// ClientID is the terminal's UUID of an order synced from offline
+	ClientID      *string     `json:"client_id,omitempty" example:"0b6f4a52-2f4e-4c59-9d55-3f7a0c2b8e11"`
+	Subtotal      money.Money `json:"subtotal"`
+	DiscountTotal money.Money `json:"discount_total"`
+	ServiceCharge money.Money `json:"service_charge"`
+	TaxTotal      money.Money `json:"tax_total"`
+	Rounding      money.Money `json:"rounding"`
+	Total         money.Money `json:"total"`
+	// TaxRate, TaxInclusive and ServiceChargeRate are the store settings
+	// the order was priced with
+	TaxRate           float64     `json:"tax_rate" example:"11"`
+	TaxInclusive      bool        `json:"tax_inclusive" example:"false"`
+	ServiceChargeRate float64     `json:"service_charge_rate" example:"5"`
+	Status            OrderStatus `json:"status" example:"open"`
+	// Label names a held basket, such as "table 4"
+	Label          string             `json:"label" example:""`
+	RefundedAmount money.Money        `json:"refunded_amount"`
+	Items          []OrderItem        `json:"items"`
+	Discounts      []OrderDiscount    `json:"discounts,omitempty"`
+	Payments       []Payment          `json:"payments,omitempty"`
+	Refunds        []Refund           `json:"refunds,omitempty"`
+	StatusHistory  []OrderStatusEvent `json:"status_history,omitempty"`
+	ReceiptEmails  []ReceiptEmail     `json:"receipt_emails,omitempty"`
+	UserID         int                `json:"user_id"` // Changed from string to int
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	// PaidAt is when the order was paid in full; sales count on that day
+	PaidAt *time.Time `json:"paid_at,omitempty"`
+}
+
+// OrderDiscount records a discount applied to an order. OrderItemID is nil
+// for discounts on the whole order.
+type OrderDiscount struct {
+	ID          int          `json:"id"`
+	OrderItemID *int         `json:"order_item_id"`
+	PromotionID *int         `json:"promotion_id"`
+	Source      promo.Source `json:"source" example:"promotion"`
+	Name        string       `json:"name" example:"Happy hour coffee"`
+	Reason      string       `json:"reason" example:""`
+	Amount      money.Money  `json:"amount"`
+
+	// line is the index of the discounted line while the order is priced
+	line int
+}
+
+// ManualDiscount is a discount entered by the cashier on a line or the whole order
+type ManualDiscount struct {
+	Type   promo.Type `json:"type" binding:"required,oneof=percentage fixed" example:"percentage"`
+	Value  float64    `json:"value" binding:"required,gt=0" example:"10"`
+	Reason string     `json:"reason" binding:"required" example:"Regular customer"`
+}
+
+// OrderStatusEvent records a single status change of an order
+type OrderStatusEvent struct {
+	ID          int         `json:"id"`
+	FromStatus  OrderStatus `json:"from_status" example:"open"`
+	ToStatus    OrderStatus `json:"to_status" example:"paid"`
+	Reason      string      `json:"reason" example:"Customer changed their mind"`
+	PerformedBy int         `json:"performed_by" example:"1"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// OrderTransitionRequest carries the reason for a status change
+type OrderTransitionRequest struct {
+	Reason string `json:"reason" example:"Wrong item rung up"`
+}
+
+// OrderTransition describes a status change requested by a user. From, when
+// set, is the status the order must currently be in.
+type OrderTransition struct {
+	From   OrderStatus
+	To     OrderStatus
+	Reason string
+}
+
+// Payment represents a single tender recorded against an order
+type Payment struct {
+	ID         int           `json:"id"`
+	OrderID    int           `json:"order_id"`
+	Method     PaymentMethod `json:"method" example:"cash"`
+	Amount     money.Money   `json:"amount"`
+	Tendered   money.Money   `json:"tendered"`
+	Change     money.Money   `json:"change"`
+	Reference  string        `json:"reference" example:""`
+	ReceivedBy int           `json:"received_by" example:"1"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// TenderRequest represents one tender handed over by the customer
+type TenderRequest struct {
+	Method    PaymentMethod `json:"method" binding:"required" example:"cash"`
+	Amount    money.Money   `json:"amount" binding:"money,required,gt=0"`
+	Reference string        `json:"reference" example:""`
+}
+
+// CreatePayments represents the tenders to record against an order
+type CreatePayments struct {
+	Tenders []TenderRequest `json:"tenders" binding:"required,min=1,dive"`
+}
+
+// PaymentResult summarises an order's payment state after recording tenders
+type PaymentResult struct {
+	Order      *Order      `json:"order"`
+	Payments   []Payment   `json:"payments"`
+	AmountPaid money.Money `json:"amount_paid"`
+	AmountDue  money.Money `json:"amount_due"`
+	Change     money.Money `json:"change"`
+}
+
+// Refund represents money returned to the customer for one or more order lines
+type Refund struct {
+	ID         int           `json:"id"`
+	OrderID    int           `json:"order_id"`
+	Amount     money.Money   `json:"amount"`
+	Method     PaymentMethod `json:"method" example:"cash"`
+	Reference  string        `json:"reference" example:""`
+	Reason     string        `json:"reason" example:"Damaged item"`
+	RefundedBy int           `json:"refunded_by" example:"1"`
+	Items      []RefundItem  `json:"items"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// RefundItem records the quantity of an order line returned in a refund.
+// Amount is what was given back: the net line value refunded plus its
+// service charge, plus its tax when prices exclude tax.
+type RefundItem struct {
+	ID            int               `json:"id"`
+	OrderItemID   int               `json:"order_item_id" example:"1"`
+	Quantity      quantity.Quantity `json:"quantity" example:"1"`
+	Amount        money.Money       `json:"amount"`
+	NetAmount     money.Money       `json:"net_amount"`
+	ServiceCharge money.Money       `json:"service_charge"`
+	TaxAmount     money.Money       `json:"tax_amount"`
+}
+
+// RefundItemRequest references an order line and the quantity to return
+type RefundItemRequest struct {
+	OrderItemID int               `json:"order_item_id" binding:"required,gt=0" example:"1"`
+	Quantity    quantity.Quantity `json:"quantity" binding:"required,gt=0" example:"1"`
+}
+
+// RefundTender describes how the refund is paid back to the customer
+type RefundTender struct {
+	Method    PaymentMethod `json:"method" binding:"required" example:"cash"`
+	Reference string        `json:"reference" example:""`
+}
+
+// CreateRefund represents a refund request. When Items is empty every line
+// that has not been refunded yet is returned in full.
+type CreateRefund struct {
+	Items  []RefundItemRequest `json:"items" binding:"omitempty,dive"`
+	Tender RefundTender        `json:"tender" binding:"required"`
+	Reason string              `json:"reason" binding:"required" example:"Damaged item"`
+}
+
+// ReceiptEmail records a receipt emailed to a customer
+type ReceiptEmail struct {
+	ID        int       `json:"id"`
+	OrderID   int       `json:"order_id"`
+	Email     string    `json:"email" example:"customer@example.com"`
+	SentBy    int       `json:"sent_by" example:"1"`
 	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// CreateOrder represents the data needed to create a new order
-// Note: Still accepts a single Product. Modify if API should accept multiple.
+// EmailReceiptRequest is the address a receipt is sent to
+type EmailReceiptRequest struct {
+	Email string `json:"email" binding:"required,email" example:"customer@example.com"`
+}
+
+// CreateOrderItem represents a single product line requested by the client.
+// Only the product, variant, option and modifier references, the quantity
+// and the note are accepted; name, category and price are resolved by the
+// server from the catalog. Products sold by kg, l or m take quantities of up
+// to three decimals. Instead of a quantity, a line can carry the barcode a
+// scale printed for it, which gives the weight or the price of the line.
+type CreateOrderItem struct {
+	ProductID int `json:"product_id" binding:"required,gt=0" example:"1"`
+	// VariantID is required for products that have variants
+	VariantID *int `json:"variant_id,omitempty" binding:"omitempty,gt=0" example:"3"`
+	// OptionIDs are the options chosen from the product's option groups
+	OptionIDs []int `json:"option_ids,omitempty" binding:"omitempty,max=50"`
+	// ModifierIDs are the modifiers chosen from the modifier lists attached
+	// to the product or its category
+	ModifierIDs []int             `json:"modifier_ids,omitempty" binding:"omitempty,max=50"`
+	Note        string            `json:"note,omitempty" binding:"max=200" example:"Less ice, please"`
+	Quantity    quantity.Quantity `json:"quantity" binding:"required_without=ScaleCode,excluded_with=ScaleCode" example:"2"`
+	// ScaleCode is an EAN-13 printed by one of the store's scales whose item
+	// code is the product's or variant's SKU or one of its barcodes
+	ScaleCode string          `json:"scale_code,omitempty" binding:"omitempty,len=13,numeric" example:"2112345003504"`
+	Discount  *ManualDiscount `json:"discount,omitempty"`
+}
+
+// CreateOrder represents the data needed to create a new order. Active
+// promotions are applied automatically; Discount is an optional manual
+// discount on the whole order. With Hold set the basket is parked under
+// Label instead of being left open for payment.
 type CreateOrder struct {
-	Total   float64   `json:"total"`
-	Product []Product `json:"product"`
+	Items    []CreateOrderItem `json:"items" binding:"required,min=1,dive"`
+	Discount *ManualDiscount   `json:"discount,omitempty"`
+	Hold     bool              `json:"hold" example:"false"`
+	Label    string            `json:"label" binding:"max=100" example:"Table 4"`
+}
+
+// HoldOrderRequest carries the label a basket is parked under
+type HoldOrderRequest struct {
+	Label string `json:"label" binding:"required,max=100" example:"Customer in fitting room"`
+}
+
+// SyncOrder is a sale made on a terminal while it was offline. ClientID is
+// generated by the terminal and makes uploading the same sale twice harmless;
+// CreatedAt is the terminal's sale time. Tenders, when given, are recorded
+// as paid at the sale time; sales with nothing due are paid without them.
+type SyncOrder struct {
+	ClientID  string            `json:"client_id" binding:"required,uuid" example:"0b6f4a52-2f4e-4c59-9d55-3f7a0c2b8e11"`
+	CreatedAt time.Time         `json:"created_at" binding:"required" example:"2026-10-17T09:30:00+07:00"`
+	Items     []CreateOrderItem `json:"items" binding:"required,min=1,dive"`
+	Discount  *ManualDiscount   `json:"discount,omitempty"`
+	Tenders   []TenderRequest   `json:"tenders" binding:"omitempty,dive"`
+}
+
+// SyncOrders is a batch of offline sales uploaded by a terminal
+type SyncOrders struct {
+	Orders []SyncOrder `json:"orders" binding:"required,min=1,max=100,dive"`
+}
+
+// SyncStatus is the outcome of one synced order
+type SyncStatus string
+
+const (
+	SyncAccepted  SyncStatus = "accepted"
+	SyncDuplicate SyncStatus = "duplicate"
+	SyncRejected  SyncStatus = "rejected"
+)
+
+// SyncResult reports what happened to one order of a sync batch. OrderID and
+// OrderNumber identify the stored order for accepted and duplicate orders;
+// Message explains rejections.
+type SyncResult struct {
+	ClientID    string      `json:"client_id" example:"0b6f4a52-2f4e-4c59-9d55-3f7a0c2b8e11"`
+	Status      SyncStatus  `json:"status" example:"accepted"`
+	OrderID     int         `json:"order_id,omitempty" example:"42"`
+	OrderNumber string      `json:"order_number,omitempty" example:"OUT1-20261017-0042"`
+	OrderStatus OrderStatus `json:"order_status,omitempty" example:"paid"`
+	Message     string      `json:"message,omitempty" example:""`
+}
+
+// catalogProduct is the server-side view of a product used to price an order
+type catalogProduct struct {
+	ID           int
+	Name         string
+	Price        money.Money
+	IsAvailable  bool
+	CategoryID   int
+	CategoryName string
+	TaxClass     tax.Class
+	// Unit is what Price is per; PriceRounding rounds the amount of decimal
+	// quantities to the currency's minor unit
+	Unit          quantity.Unit
+	PriceRounding tax.RoundingMode
+	// Codes maps the SKUs and barcodes of the product and its variants to the
+	// variant they belong to, 0 for the product's own. They are only loaded
+	// to match the item codes of scale barcodes.
+	Codes map[string]int
+	// Variants are the product's sellable variants by ID; products with
+	// variants are sold by variant
+	Variants map[int]*catalogVariant
+	Groups   []option.Group
+	// Modifiers are the modifier lists attached to the product or its category
+	Modifiers []option.Group
+}
+
+// catalogVariant is the server-side view of a variant used to price an order
+type catalogVariant struct {
+	ID          int
+	Name        string
+	Price       money.Money
+	IsAvailable bool
 }
 
 // OrderResponse represents the data returned after creating an order
 type OrderResponse struct {
-	ID        int       `json:"id"` // Changed from string to int
-	Total     float64   `json:"total"`
-	Product   []Product `json:"product"` // Reverted back to []Product
-	UserID    int       `json:"user_id"` // Changed from string to int
-	CreatedAt time.Time `json:"created_at"`
+	ID        int         `json:"id"` // Changed from string to int
+	Total     money.Money `json:"total"`
+	Items     []OrderItem `json:"items"`
+	UserID    int         `json:"user_id"` // Changed from string to int
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// ExportedOrder is one row of the order export. PaymentMethods lists the
+// distinct methods the order was paid with.
+type ExportedOrder struct {
+	Order
+	PaymentMethods []PaymentMethod
+}
+
+// ExportedOrderItem is one row of the order line export
+type ExportedOrderItem struct {
+	OrderItem
+	OrderNumber string
+	OrderStatus OrderStatus
+	OrderedAt   time.Time
+	PaidAt      *time.Time
 }
@@ -0,0 +1,105 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/money"
+)
+
+// PaymentMethod identifies how a tender was paid
+type PaymentMethod string
+
+const (
+	PaymentCash        PaymentMethod = "cash"
+	PaymentCard        PaymentMethod = "card"
+	PaymentQRIS        PaymentMethod = "qris"
+	PaymentEWallet     PaymentMethod = "e_wallet"
+	PaymentStoreCredit PaymentMethod = "store_credit"
+)
+
+// IsValid reports whether the payment method is supported
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentCash, PaymentCard, PaymentQRIS, PaymentEWallet, PaymentStoreCredit:
+		return true
+	}
+	return false
+}
+
+// Label returns the name of the payment method printed on receipts
+func (m PaymentMethod) Label() string {
+	switch m {
+	case PaymentCash:
+		return "Cash"
+	case PaymentCard:
+		return "Card"
+	case PaymentQRIS:
+		return "QRIS"
+	case PaymentEWallet:
+		return "E-Wallet"
+	case PaymentStoreCredit:
+		return "Store Credit"
+	}
+	return string(m)
+}
+
+// applyTenders splits the tendered amounts over the amount still due.
+// Non-cash tenders are applied first and may not exceed what is due; cash
+// covers the remainder and any excess cash is returned as change.
+func applyTenders(due money.Money, tenders []TenderRequest) ([]Payment, money.Money, *customerror.CustomError) {
+	if len(tenders) == 0 {
+		return nil, money.Money{}, customerror.NewCustomError(nil, "At least one tender is required", http.StatusBadRequest)
+	}
+	if !due.IsPositive() {
+		return nil, money.Money{}, customerror.NewCustomError(nil, "Order has no amount due", http.StatusConflict)
+	}
+
+	payments := make([]Payment, len(tenders))
+	remaining := due
+	for i, tender := range tenders {
+		if !tender.Method.IsValid() {
+			return nil, money.Money{}, customerror.NewCustomError(nil, fmt.Sprintf("Unsupported payment method: %s", tender.Method), http.StatusBadRequest)
+		}
+		if !tender.Amount.IsPositive() {
+			return nil, money.Money{}, customerror.NewCustomError(nil, "Tender amount must be greater than zero", http.StatusBadRequest)
+		}
+		if tender.Amount.Currency != due.Currency {
+			return nil, money.Money{}, customerror.NewCustomError(nil, fmt.Sprintf("Tender currency %s does not match the order currency %s", tender.Amount.Currency, due.Currency), http.StatusBadRequest)
+		}
+		if tender.Method == PaymentCash {
+			continue
+		}
+		if tender.Amount.Amount > remaining.Amount {
+			return nil, money.Money{}, customerror.NewCustomError(nil, fmt.Sprintf("A %s tender cannot exceed the amount due (%s)", tender.Method, remaining), http.StatusBadRequest)
+		}
+		payments[i] = Payment{Method: tender.Method, Amount: tender.Amount, Tendered: tender.Amount, Change: money.New(0, due.Currency), Reference: tender.Reference}
+		remaining = remaining.Sub(tender.Amount)
+	}
+
+	change := money.New(0, due.Currency)
+	for i, tender := range tenders {
+		if tender.Method != PaymentCash {
+			continue
+		}
+		if remaining.IsZero() {
+			return nil, money.Money{}, customerror.NewCustomError(nil, "Cash tender is not needed; the amount due is already covered", http.StatusBadRequest)
+		}
+		applied := money.New(min(tender.Amount.Amount, remaining.Amount), due.Currency)
+		payments[i] = Payment{Method: PaymentCash, Amount: applied, Tendered: tender.Amount, Change: tender.Amount.Sub(applied), Reference: tender.Reference}
+		remaining = remaining.Sub(applied)
+		change = change.Add(payments[i].Change)
+	}
+
+	return payments, change, nil
+}
+
+// amountDue returns how much of an order total is not yet covered by payments
+func amountDue(total money.Money, paid money.Money) money.Money {
+	due := total.Sub(paid)
+	if due.IsNegative() {
+		return money.New(0, total.Currency)
+	}
+	return due
+}
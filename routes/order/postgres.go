@@ -1,13 +1,26 @@
 package order
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/barcode"
 	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/ordernumber"
+	"github.com/yantology/simple-pos/pkg/promo"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/receipt"
+	"github.com/yantology/simple-pos/pkg/tax"
 )
 
 type postgresRepository struct {
@@ -20,58 +33,136 @@ func NewPostgresRepository(db *sql.DB) OrderRepository {
 	return &postgresRepository{db: db}
 }
 
-// GetOrders returns all orders for a specific user
-func (r *postgresRepository) GetOrders(userID int) ([]*Order, *customerror.CustomError) { // Changed userID to int
+// orderColumns lists the orders columns read by scanOrder
+const orderColumns = `id, order_number, outlet_code, client_id, subtotal, discount_total, service_charge, tax_total, rounding, total, tax_rate, tax_inclusive,
+	service_charge_rate, status, label, refunded_amount, user_id, created_at, updated_at, paid_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanOrder reads an order selected with orderColumns
+func scanOrder(row rowScanner, order *Order) error {
+	return row.Scan(
+		&order.ID,
+		&order.OrderNumber,
+		&order.OutletCode,
+		&order.ClientID,
+		&order.Subtotal,
+		&order.DiscountTotal,
+		&order.ServiceCharge,
+		&order.TaxTotal,
+		&order.Rounding,
+		&order.Total,
+		&order.TaxRate,
+		&order.TaxInclusive,
+		&order.ServiceChargeRate,
+		&order.Status,
+		&order.Label,
+		&order.RefundedAmount,
+		&order.UserID,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.PaidAt,
+	)
+}
+
+// GetOrders returns one page of a user's orders matching the filter, newest first
+func (r *postgresRepository) GetOrders(userID int, filter *OrderFilter) (*OrderPage, *customerror.CustomError) { // Changed userID to int
 	fmt.Printf("Repository.GetOrders: Fetching orders for user %d\n", userID) // Add log
+	where, args := filter.where(userID)
+	// One extra row tells whether another page follows
+	args = append(args, filter.Limit+1)
 	query := `
-        SELECT id, total, product, user_id, created_at, updated_at
+        SELECT ` + orderColumns + `
         FROM orders
-        WHERE user_id = $1
-        ORDER BY created_at DESC
+        WHERE ` + where + `
+        ORDER BY created_at DESC, id DESC
+        LIMIT $` + strconv.Itoa(len(args))
+
+	orders, customErr := r.listOrders(query, args...)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	page := &OrderPage{Orders: orders}
+	if len(orders) > filter.Limit {
+		page.Orders = orders[:filter.Limit]
+		last := page.Orders[len(page.Orders)-1]
+		page.NextCursor = orderCursor{CreatedAt: last.CreatedAt, ID: last.ID}.encode()
+	}
+
+	fmt.Printf("Repository.GetOrders: Successfully fetched %d orders for user %d\n", len(page.Orders), userID) // Add log
+	return page, nil
+}
+
+// GetHeldOrders returns the parked baskets of a user, most recently touched first
+func (r *postgresRepository) GetHeldOrders(userID int) ([]*Order, *customerror.CustomError) {
+	fmt.Printf("Repository.GetHeldOrders: Fetching held orders for user %d\n", userID) // Add log
+	query := `
+        SELECT ` + orderColumns + `
+        FROM orders
+        WHERE user_id = $1 AND status = $2
+        ORDER BY updated_at DESC
     `
 
-	fmt.Println("Repository.GetOrders: Executing query") // Add log
-	rows, err := r.db.Query(query, userID)
+	orders, customErr := r.listOrders(query, userID, StatusHeld)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	fmt.Printf("Repository.GetHeldOrders: Successfully fetched %d held orders for user %d\n", len(orders), userID) // Add log
+	return orders, nil
+}
+
+// listOrders runs a query selecting orderColumns and loads the lines of
+// every order it returns
+func (r *postgresRepository) listOrders(query string, args ...any) ([]*Order, *customerror.CustomError) {
+	// Orders and their items are read from the same snapshot
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		fmt.Printf("Repository.listOrders: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	fmt.Println("Repository.listOrders: Executing query") // Add log
+	rows, err := tx.Query(query, args...)
 	if err != nil {
-		fmt.Printf("Repository.GetOrders: Database query error: %v\n", err) // Add log
+		fmt.Printf("Repository.listOrders: Database query error: %v\n", err) // Add log
 		return nil, customerror.NewPostgresError(err)
 	}
 	defer rows.Close()
 
 	var orders []*Order
+	var orderIDs []int64
 
-	fmt.Println("Repository.GetOrders: Processing rows") // Add log
+	fmt.Println("Repository.listOrders: Processing rows") // Add log
 	for rows.Next() {
-		var productJSON []byte
 		var order Order
-		fmt.Println("Repository.GetOrders: Scanning row") // Add log
-
-		// ... scan data dari database ke variabel, termasuk productJSON ...
-		if err := rows.Scan(&order.ID, &order.Total, &productJSON, &order.UserID, &order.CreatedAt, &order.UpdatedAt); err != nil {
-			fmt.Printf("Repository.GetOrders: Error scanning row: %v\n", err) // Add log
+		if err := scanOrder(rows, &order); err != nil {
+			fmt.Printf("Repository.listOrders: Error scanning row: %v\n", err) // Add log
 			return nil, customerror.NewPostgresError(err)
 		}
-
-		// Print order ID for debugging
-		fmt.Printf("Repository.GetOrders: Processing order ID: %v\n", order.ID)
-
-		// Print raw productJSON for debugging
-		fmt.Printf("Repository.GetOrders: Raw productJSON: %s\n", string(productJSON))
-
-		// Langsung unmarshal dari byte slice ke slice Product di dalam struct Order
-		if err := json.Unmarshal(productJSON, &order.Product); err != nil {
-			fmt.Printf("Repository.GetOrders: Error unmarshaling product JSON: %v\n", err) // Add log
-			return nil, customerror.NewCustomError(err, "failed to unmarshal product data", http.StatusInternalServerError)
-		}
 		orders = append(orders, &order)
+		orderIDs = append(orderIDs, int64(order.ID))
 	}
 
 	if err = rows.Err(); err != nil {
-		fmt.Printf("Repository.GetOrders: Error iterating rows: %v\n", err) // Add log
+		fmt.Printf("Repository.listOrders: Error iterating rows: %v\n", err) // Add log
 		return nil, customerror.NewPostgresError(err)
 	}
+	rows.Close()
+
+	items, customErr := r.getOrderItems(tx, orderIDs)
+	if customErr != nil {
+		return nil, customErr
+	}
+	for _, order := range orders {
+		order.Items = items[order.ID]
+	}
 
-	fmt.Printf("Repository.GetOrders: Successfully fetched %d orders for user %d\n", len(orders), userID) // Add log
 	return orders, nil
 }
 
@@ -79,15 +170,21 @@ func (r *postgresRepository) GetOrders(userID int) ([]*Order, *customerror.Custo
 func (r *postgresRepository) GetOrderByID(id int, userID int) (*Order, *customerror.CustomError) { // Changed userID to int
 	fmt.Printf("Repository.GetOrderByID: Fetching order %d for user %d\n", id, userID) // Add log
 	query := `
-        SELECT id, total, product, user_id, created_at, updated_at
+        SELECT ` + orderColumns + `
         FROM orders
         WHERE id = $1 AND user_id = $2
     `
 
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		fmt.Printf("Repository.GetOrderByID: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
 	var order Order
-	var productJSON []byte
 	fmt.Println("Repository.GetOrderByID: Executing query row") // Add log
-	err := r.db.QueryRow(query, id, userID).Scan(&order.ID, &order.Total, &productJSON, &order.UserID, &order.CreatedAt, &order.UpdatedAt)
+	err = scanOrder(tx.QueryRow(query, id, userID), &order)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			fmt.Printf("Repository.GetOrderByID: Order %d not found or user %d not authorized\n", id, userID) // Add log
@@ -97,91 +194,1687 @@ func (r *postgresRepository) GetOrderByID(id int, userID int) (*Order, *customer
 		return nil, customerror.NewPostgresError(err)
 	}
 
-	// Langsung unmarshal dari byte slice ke slice Product di dalam struct Order
-	if err := json.Unmarshal(productJSON, &order.Product); err != nil {
-		fmt.Printf("Repository.GetOrderByID: Error unmarshaling product JSON: %v\n", err) // Add log
-		return nil, customerror.NewCustomError(err, "failed to unmarshal product data", http.StatusInternalServerError)
+	items, customErr := r.getOrderItems(tx, []int64{int64(order.ID)})
+	if customErr != nil {
+		return nil, customErr
+	}
+	order.Items = items[order.ID]
+
+	order.Discounts, customErr = r.getDiscounts(tx, order.ID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	order.ReceiptEmails, customErr = r.getReceiptEmails(tx, order.ID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	order.Payments, customErr = r.getPayments(tx, order.ID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	order.Refunds, customErr = r.getRefunds(tx, order.ID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	order.StatusHistory, customErr = r.getStatusHistory(tx, order.ID)
+	if customErr != nil {
+		return nil, customErr
 	}
 
 	fmt.Printf("Repository.GetOrderByID: Successfully fetched order %d for user %d\n", id, userID) // Add log
 	return &order, nil
 }
 
-// CreateOrder creates a new order for the given user
-func (r *postgresRepository) CreateOrder(orderData *CreateOrder, userID int) (*Order, *customerror.CustomError) { // Changed userID to int
-	fmt.Printf("Repository.CreateOrder: Starting to create order for user %d\n", userID)
+// getOrderItems loads the lines of the given orders, grouped by order ID
+func (r *postgresRepository) getOrderItems(tx *sql.Tx, orderIDs []int64) (map[int][]OrderItem, *customerror.CustomError) {
+	items := make(map[int][]OrderItem, len(orderIDs))
+	if len(orderIDs) == 0 {
+		return items, nil
+	}
+
 	query := `
-        INSERT INTO orders (total, product, user_id, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5)
-        RETURNING id, total, product, user_id, created_at, updated_at
+        SELECT oi.id, oi.order_id, oi.product_id, oi.name, oi.category_id, oi.category, oi.quantity, oi.price,
+               oi.total_price, oi.discount_amount, oi.tax_class, oi.service_charge, oi.tax_amount,
+               COALESCE((SELECT SUM(ri.quantity) FROM refund_items ri WHERE ri.order_item_id = oi.id), 0),
+               oi.variant_id, oi.variant, oi.options, oi.modifiers, oi.note, oi.unit
+        FROM order_items oi
+        WHERE oi.order_id = ANY($1)
+        ORDER BY oi.order_id, oi.id
     `
 
-	now := time.Now()
-	var newOrder Order
-	var productJSON []byte
+	rows, err := tx.Query(query, pq.Array(orderIDs))
+	if err != nil {
+		fmt.Printf("Repository.getOrderItems: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item OrderItem
+		var productID, categoryID sql.NullInt64
+		var options, modifiers []byte
+		if err := rows.Scan(&item.ID, &item.OrderID, &productID, &item.Name, &categoryID, &item.Category, &item.Quantity, &item.Price, &item.TotalPrice, &item.DiscountAmount,
+			&item.TaxClass, &item.ServiceCharge, &item.TaxAmount, &item.RefundedQuantity, &item.VariantID, &item.Variant, &options, &modifiers, &item.Note, &item.Unit); err != nil {
+			fmt.Printf("Repository.getOrderItems: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		if err := json.Unmarshal(options, &item.Options); err != nil {
+			fmt.Printf("Repository.getOrderItems: Error decoding options: %v\n", err) // Add log
+			return nil, customerror.NewCustomError(err, "Failed to read order line options", http.StatusInternalServerError)
+		}
+		if err := json.Unmarshal(modifiers, &item.Modifiers); err != nil {
+			fmt.Printf("Repository.getOrderItems: Error decoding modifiers: %v\n", err) // Add log
+			return nil, customerror.NewCustomError(err, "Failed to read order line modifiers", http.StatusInternalServerError)
+		}
+		if productID.Valid {
+			id := int(productID.Int64)
+			item.ProductID = &id
+		}
+		if categoryID.Valid {
+			id := int(categoryID.Int64)
+			item.CategoryID = &id
+		}
+		items[item.OrderID] = append(items[item.OrderID], item)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getOrderItems: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return items, nil
+}
 
-	fmt.Printf("Repository.CreateOrder: Marshaling product data: %+v\n", orderData.Product)
-	productBytes, err := json.Marshal(orderData.Product) // Already a slice of Product
+// insertOrderItems stores the priced lines of a newly created order
+func (r *postgresRepository) insertOrderItems(tx *sql.Tx, orderID int, lines []OrderItem) ([]OrderItem, *customerror.CustomError) {
+	query := `
+        INSERT INTO order_items (order_id, product_id, name, category_id, category, quantity, price, total_price, discount_amount,
+                                 tax_class, service_charge, tax_amount, variant_id, variant, options, modifiers, note, unit)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+        RETURNING id
+    `
+
+	stmt, err := tx.Prepare(query)
 	if err != nil {
-		fmt.Printf("Repository.CreateOrder: Error marshaling product data: %v\n", err)
-		return nil, customerror.NewCustomError(err, "failed to marshal product data for insertion", http.StatusInternalServerError)
+		fmt.Printf("Repository.insertOrderItems: Error preparing statement: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
 	}
+	defer stmt.Close()
 
-	fmt.Printf("Repository.CreateOrder: Executing database query with total: %v\n", orderData.Total)
-	err = r.db.QueryRow(
-		query,
-		orderData.Total,
-		productBytes,
-		userID,
-		now,
-		now,
-	).Scan(
-		&newOrder.ID,
-		&newOrder.Total,
-		&productJSON,
-		&newOrder.UserID,
-		&newOrder.CreatedAt,
-		&newOrder.UpdatedAt,
-	)
+	inserted := make([]OrderItem, 0, len(lines))
+	for _, line := range lines {
+		line.OrderID = orderID
+		if line.Options == nil {
+			line.Options = []option.Choice{}
+		}
+		if line.Modifiers == nil {
+			line.Modifiers = []option.Choice{}
+		}
+		options, err := json.Marshal(line.Options)
+		if err != nil {
+			return nil, customerror.NewCustomError(err, "Failed to store order line options", http.StatusInternalServerError)
+		}
+		modifiers, err := json.Marshal(line.Modifiers)
+		if err != nil {
+			return nil, customerror.NewCustomError(err, "Failed to store order line modifiers", http.StatusInternalServerError)
+		}
+		if err := stmt.QueryRow(orderID, line.ProductID, line.Name, line.CategoryID, line.Category, line.Quantity, line.Price, line.TotalPrice, line.DiscountAmount,
+			line.TaxClass, line.ServiceCharge, line.TaxAmount, line.VariantID, line.Variant, options, modifiers, line.Note, line.Unit).Scan(&line.ID); err != nil {
+			fmt.Printf("Repository.insertOrderItems: Database error: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		inserted = append(inserted, line)
+	}
+
+	return inserted, nil
+}
+
+// getCatalogProducts loads the caller's products referenced by the order items.
+// Rows are locked for share so prices cannot change while the order is priced.
+// Products owned by other users are never returned.
+func (r *postgresRepository) getCatalogProducts(tx *sql.Tx, items []CreateOrderItem, userID int) (map[int]*catalogProduct, *customerror.CustomError) {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, int64(item.ProductID))
+	}
+
+	query := `
+        SELECT p.id, p.name, p.price, p.is_available, c.id, c.name, p.tax_class, p.unit, p.price_rounding
+        FROM products p
+        JOIN categories c ON c.id = p.category_id
+        WHERE p.id = ANY($1) AND p.user_id = $2
+        FOR SHARE OF p
+    `
+
+	rows, err := tx.Query(query, pq.Array(ids), userID)
+	if err != nil {
+		fmt.Printf("Repository.getCatalogProducts: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	catalog := make(map[int]*catalogProduct, len(ids))
+	for rows.Next() {
+		var product catalogProduct
+		if err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.IsAvailable, &product.CategoryID, &product.CategoryName, &product.TaxClass, &product.Unit, &product.PriceRounding); err != nil {
+			fmt.Printf("Repository.getCatalogProducts: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		catalog[product.ID] = &product
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getCatalogProducts: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	rows.Close()
 
+	if customErr := r.getCatalogVariants(tx, catalog, ids); customErr != nil {
+		return nil, customErr
+	}
+
+	groups, err := option.Load(tx, ids)
 	if err != nil {
-		fmt.Printf("Repository.CreateOrder: Database error: %v\n", err)
+		fmt.Printf("Repository.getCatalogProducts: Error loading option groups: %v\n", err) // Add log
 		return nil, customerror.NewPostgresError(err)
 	}
-	fmt.Printf("Repository.CreateOrder: Order created successfully with ID: %v\n", newOrder.ID)
+	for productID, productGroups := range groups {
+		if product, ok := catalog[productID]; ok {
+			product.Groups = productGroups
+		}
+	}
 
-	fmt.Println("Repository.CreateOrder: Unmarshaling returned product data")
-	if err := json.Unmarshal(productJSON, &newOrder.Product); err != nil {
-		fmt.Printf("Repository.CreateOrder: Error unmarshaling product data: %v\n", err)
-		return nil, customerror.NewCustomError(err, "failed to unmarshal returned product data", http.StatusInternalServerError)
+	if customErr := r.getCatalogModifiers(tx, catalog, userID); customErr != nil {
+		return nil, customErr
 	}
 
-	fmt.Printf("Repository.CreateOrder: Successfully completed, returning order with ID: %v\n", newOrder.ID)
-	return &newOrder, nil
+	if hasScaleCode(items) {
+		if customErr := r.getCatalogCodes(tx, catalog, ids); customErr != nil {
+			return nil, customErr
+		}
+	}
+
+	return catalog, nil
 }
 
-// DeleteOrder deletes an order by ID, checking ownership
-func (r *postgresRepository) DeleteOrder(id int, userID int) *customerror.CustomError { // Changed userID to int
-	fmt.Printf("Repository.DeleteOrder: Attempting to delete order %d for user %d\n", id, userID) // Add log
-	query := `DELETE FROM orders WHERE id = $1 AND user_id = $2`
-	fmt.Println("Repository.DeleteOrder: Executing delete query") // Add log
-	result, err := r.db.Exec(query, id, userID)
+// getCatalogCodes adds the SKUs and barcodes of the catalog products and
+// their variants, which scale barcodes name as their item code
+func (r *postgresRepository) getCatalogCodes(tx *sql.Tx, catalog map[int]*catalogProduct, ids []int64) *customerror.CustomError {
+	query := `
+        SELECT product_id, COALESCE(variant_id, 0), code FROM product_barcodes WHERE product_id = ANY($1)
+        UNION ALL
+        SELECT id, 0, sku FROM products WHERE id = ANY($1) AND sku IS NOT NULL
+        UNION ALL
+        SELECT product_id, id, sku FROM product_variants WHERE product_id = ANY($1) AND sku IS NOT NULL
+    `
+	rows, err := tx.Query(query, pq.Array(ids))
 	if err != nil {
-		fmt.Printf("Repository.DeleteOrder: Database exec error: %v\n", err) // Add log
+		fmt.Printf("Repository.getCatalogCodes: Database query error: %v\n", err) // Add log
 		return customerror.NewPostgresError(err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	for rows.Next() {
+		var productID, variantID int
+		var code string
+		if err := rows.Scan(&productID, &variantID, &code); err != nil {
+			fmt.Printf("Repository.getCatalogCodes: Error scanning row: %v\n", err) // Add log
+			return customerror.NewPostgresError(err)
+		}
+		product, ok := catalog[productID]
+		if !ok {
+			continue
+		}
+		if product.Codes == nil {
+			product.Codes = make(map[string]int)
+		}
+		product.Codes[code] = variantID
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getCatalogCodes: Error iterating rows: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// hasScaleCode reports whether any line is sold by a scale barcode
+func hasScaleCode(items []CreateOrderItem) bool {
+	return slices.ContainsFunc(items, func(item CreateOrderItem) bool { return item.ScaleCode != "" })
+}
+
+// getCatalogModifiers adds the modifier lists attached to the catalog
+// products or their categories. A list attached to both a product and its
+// category is offered once.
+func (r *postgresRepository) getCatalogModifiers(tx *sql.Tx, catalog map[int]*catalogProduct, userID int) *customerror.CustomError {
+	productIDs := make([]int64, 0, len(catalog))
+	categoryIDs := make([]int64, 0, len(catalog))
+	for _, product := range catalog {
+		productIDs = append(productIDs, int64(product.ID))
+		categoryIDs = append(categoryIDs, int64(product.CategoryID))
+	}
+
+	query := `
+        SELECT l.id, l.name, l.required, l.min_select, l.max_select, l.product_ids, l.category_ids,
+               m.id, m.name, m.price_delta, m.is_available
+        FROM modifier_lists l
+        JOIN modifiers m ON m.list_id = l.id
+        WHERE l.user_id = $1 AND (l.product_ids && $2::INTEGER[] OR l.category_ids && $3::INTEGER[])
+        ORDER BY l.position, l.id, m.position, m.id
+    `
+	rows, err := tx.Query(query, userID, pq.Array(productIDs), pq.Array(categoryIDs))
 	if err != nil {
-		fmt.Printf("Repository.DeleteOrder: Error getting rows affected: %v\n", err) // Add log
-		return customerror.NewCustomError(err, "failed to check rows affected", http.StatusInternalServerError)
+		fmt.Printf("Repository.getCatalogModifiers: Database query error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
 	}
+	defer rows.Close()
 
-	if rowsAffected == 0 {
-		fmt.Printf("Repository.DeleteOrder: Order %d not found or user %d not authorized to delete\n", id, userID) // Add log
-		return customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d not found or user not authorized to delete", id), http.StatusNotFound)
+	// lists are the matching modifier lists with the products and categories
+	// they are attached to
+	type attachedList struct {
+		group      option.Group
+		products   pq.Int64Array
+		categories pq.Int64Array
+	}
+	var lists []attachedList
+	for rows.Next() {
+		var list attachedList
+		var modifier option.Option
+		if err := rows.Scan(&list.group.ID, &list.group.Name, &list.group.Required, &list.group.MinSelect, &list.group.MaxSelect, &list.products, &list.categories,
+			&modifier.ID, &modifier.Name, &modifier.PriceDelta, &modifier.IsAvailable); err != nil {
+			fmt.Printf("Repository.getCatalogModifiers: Error scanning row: %v\n", err) // Add log
+			return customerror.NewPostgresError(err)
+		}
+		if len(lists) == 0 || lists[len(lists)-1].group.ID != list.group.ID {
+			lists = append(lists, list)
+		}
+		last := &lists[len(lists)-1]
+		last.group.Options = append(last.group.Options, modifier)
 	}
 
-	fmt.Printf("Repository.DeleteOrder: Successfully deleted order %d for user %d\n", id, userID) // Add log
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getCatalogModifiers: Error iterating rows: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+
+	for _, product := range catalog {
+		for _, list := range lists {
+			if slices.Contains(list.products, int64(product.ID)) || slices.Contains(list.categories, int64(product.CategoryID)) {
+				product.Modifiers = append(product.Modifiers, list.group)
+			}
+		}
+	}
+	return nil
+}
+
+// getCatalogVariants adds the variants of the catalog products, locked for
+// share like the products themselves
+func (r *postgresRepository) getCatalogVariants(tx *sql.Tx, catalog map[int]*catalogProduct, ids []int64) *customerror.CustomError {
+	query := `
+        SELECT id, product_id, name, price, is_available
+        FROM product_variants
+        WHERE product_id = ANY($1)
+        FOR SHARE
+    `
+	rows, err := tx.Query(query, pq.Array(ids))
+	if err != nil {
+		fmt.Printf("Repository.getCatalogVariants: Database query error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var variant catalogVariant
+		var productID int
+		if err := rows.Scan(&variant.ID, &productID, &variant.Name, &variant.Price, &variant.IsAvailable); err != nil {
+			fmt.Printf("Repository.getCatalogVariants: Error scanning row: %v\n", err) // Add log
+			return customerror.NewPostgresError(err)
+		}
+		product, ok := catalog[productID]
+		if !ok {
+			continue
+		}
+		if product.Variants == nil {
+			product.Variants = make(map[int]*catalogVariant)
+		}
+		product.Variants[variant.ID] = &variant
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getCatalogVariants: Error iterating rows: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// getTaxSettings loads the user's tax, service charge and rounding settings.
+// Users without saved settings pay no tax or service charge.
+func (r *postgresRepository) getTaxSettings(tx *sql.Tx, userID int) (tax.Settings, *customerror.CustomError) {
+	query := `
+        SELECT tax_rate, tax_inclusive, service_charge_rate, rounding_mode, rounding_unit
+        FROM store_settings
+        WHERE user_id = $1
+    `
+
+	var settings tax.Settings
+	err := tx.QueryRow(query, userID).Scan(&settings.Rate, &settings.Inclusive, &settings.ServiceChargeRate, &settings.RoundingMode, &settings.RoundingUnit)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return tax.Settings{RoundingMode: tax.RoundNone}, nil
+		}
+		fmt.Printf("Repository.getTaxSettings: Database error: %v\n", err) // Add log
+		return tax.Settings{}, customerror.NewPostgresError(err)
+	}
+	return settings, nil
+}
+
+// getActivePromotions loads the user's enabled promotions whose date range
+// includes at. Weekday and time-of-day windows are checked by the evaluator.
+func (r *postgresRepository) getActivePromotions(tx *sql.Tx, userID int, at time.Time) ([]promo.Promotion, *customerror.CustomError) {
+	query := `
+        SELECT id, name, type, scope, percent, amount, buy_quantity, get_quantity, min_spend,
+               product_ids, category_ids, starts_at, ends_at, days_of_week,
+               to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI')
+        FROM promotions
+        WHERE user_id = $1 AND is_active
+          AND (starts_at IS NULL OR starts_at <= $2)
+          AND (ends_at IS NULL OR ends_at >= $2)
+    `
+
+	rows, err := tx.Query(query, userID, at)
+	if err != nil {
+		fmt.Printf("Repository.getActivePromotions: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var promotions []promo.Promotion
+	for rows.Next() {
+		var p promo.Promotion
+		var productIDs, categoryIDs, daysOfWeek pq.Int64Array
+		var startsAt, endsAt sql.NullTime
+		var startTime, endTime sql.NullString
+		if err := rows.Scan(&p.ID, &p.Name, &p.Type, &p.Scope, &p.Percent, &p.Amount, &p.BuyQuantity, &p.GetQuantity, &p.MinSpend,
+			&productIDs, &categoryIDs, &startsAt, &endsAt, &daysOfWeek, &startTime, &endTime); err != nil {
+			fmt.Printf("Repository.getActivePromotions: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+
+		for _, id := range productIDs {
+			p.ProductIDs = append(p.ProductIDs, int(id))
+		}
+		for _, id := range categoryIDs {
+			p.CategoryIDs = append(p.CategoryIDs, int(id))
+		}
+		for _, day := range daysOfWeek {
+			p.DaysOfWeek = append(p.DaysOfWeek, time.Weekday(day))
+		}
+		if startsAt.Valid {
+			p.StartsAt = &startsAt.Time
+		}
+		if endsAt.Valid {
+			p.EndsAt = &endsAt.Time
+		}
+		if startTime.Valid && endTime.Valid {
+			start, startErr := promo.ParseClock(startTime.String)
+			end, endErr := promo.ParseClock(endTime.String)
+			if startErr == nil && endErr == nil {
+				p.Window = &promo.TimeWindow{Start: start, End: end}
+			}
+		}
+		promotions = append(promotions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getActivePromotions: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return promotions, nil
+}
+
+// insertOrderDiscounts stores the discounts applied to a new order, linking
+// line discounts to the inserted order items
+func (r *postgresRepository) insertOrderDiscounts(tx *sql.Tx, orderID int, items []OrderItem, discounts []OrderDiscount) ([]OrderDiscount, *customerror.CustomError) {
+	query := `
+        INSERT INTO order_discounts (order_id, order_item_id, promotion_id, source, name, reason, amount)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `
+
+	inserted := make([]OrderDiscount, 0, len(discounts))
+	for _, discount := range discounts {
+		if discount.line >= 0 && discount.line < len(items) {
+			itemID := items[discount.line].ID
+			discount.OrderItemID = &itemID
+		}
+		err := tx.QueryRow(query, orderID, discount.OrderItemID, discount.PromotionID, discount.Source, discount.Name, discount.Reason, discount.Amount).Scan(&discount.ID)
+		if err != nil {
+			fmt.Printf("Repository.insertOrderDiscounts: Database error: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		inserted = append(inserted, discount)
+	}
+
+	return inserted, nil
+}
+
+// getDiscounts loads the discounts applied to an order
+func (r *postgresRepository) getDiscounts(tx *sql.Tx, orderID int) ([]OrderDiscount, *customerror.CustomError) {
+	query := `
+        SELECT id, order_item_id, promotion_id, source, name, reason, amount
+        FROM order_discounts
+        WHERE order_id = $1
+        ORDER BY id
+    `
+
+	rows, err := tx.Query(query, orderID)
+	if err != nil {
+		fmt.Printf("Repository.getDiscounts: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var discounts []OrderDiscount
+	for rows.Next() {
+		var discount OrderDiscount
+		var orderItemID, promotionID sql.NullInt64
+		if err := rows.Scan(&discount.ID, &orderItemID, &promotionID, &discount.Source, &discount.Name, &discount.Reason, &discount.Amount); err != nil {
+			fmt.Printf("Repository.getDiscounts: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		if orderItemID.Valid {
+			id := int(orderItemID.Int64)
+			discount.OrderItemID = &id
+		}
+		if promotionID.Valid {
+			id := int(promotionID.Int64)
+			discount.PromotionID = &id
+		}
+		discounts = append(discounts, discount)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getDiscounts: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return discounts, nil
+}
+
+// CreateOrder prices the requested items from the products table, applies
+// active promotions and manual discounts, and creates a new order for the
+// given user
+func (r *postgresRepository) CreateOrder(orderData *CreateOrder, userID int) (*Order, *customerror.CustomError) { // Changed userID to int
+	fmt.Printf("Repository.CreateOrder: Starting to create order for user %d\n", userID)
+	if orderData == nil {
+		return nil, customerror.NewCustomError(nil, "orderData is nil", http.StatusBadRequest)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.CreateOrder: Error starting transaction: %v\n", err)
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	newOrder, customErr := r.createOrder(tx, orderData, userID, time.Now(), nil)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.CreateOrder: Error committing transaction: %v\n", err)
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.CreateOrder: Successfully completed, returning order with ID: %v\n", newOrder.ID)
+	return newOrder, nil
+}
+
+// createOrder prices and inserts an order sold at the given time. clientID
+// is the terminal's UUID of an order synced from offline, nil otherwise.
+func (r *postgresRepository) createOrder(tx *sql.Tx, orderData *CreateOrder, userID int, at time.Time, clientID *string) (*Order, *customerror.CustomError) {
+	status := StatusOpen
+	if orderData.Hold {
+		if strings.TrimSpace(orderData.Label) == "" {
+			return nil, customerror.NewCustomError(nil, "A label is required to hold an order", http.StatusBadRequest)
+		}
+		status = StatusHeld
+	}
+
+	priced, customErr := r.priceRequest(tx, orderData, userID, at)
+	if customErr != nil {
+		fmt.Printf("Repository.createOrder: Pricing failed: %s\n", customErr.Message())
+		return nil, customErr
+	}
+
+	// Numbered last so the sequence row is locked as briefly as possible
+	number, customErr := r.nextOrderNumber(tx, userID, at)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	query := `
+        INSERT INTO orders (order_number, outlet_code, client_id, subtotal, discount_total, service_charge, tax_total, rounding, total,
+                            tax_rate, tax_inclusive, service_charge_rate, status, label, user_id, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+        RETURNING ` + orderColumns
+
+	var newOrder Order
+
+	fmt.Printf("Repository.createOrder: Executing database query with computed total: %v\n", priced.Total)
+	err := scanOrder(tx.QueryRow(
+		query,
+		number.Number,
+		number.OutletCode,
+		clientID,
+		priced.Subtotal,
+		priced.DiscountTotal,
+		priced.ServiceCharge,
+		priced.TaxTotal,
+		priced.Rounding,
+		priced.Total,
+		priced.Settings.Rate,
+		priced.Settings.Inclusive,
+		priced.Settings.ServiceChargeRate,
+		status,
+		strings.TrimSpace(orderData.Label),
+		userID,
+		at,
+		at,
+	), &newOrder)
+
+	if err != nil {
+		fmt.Printf("Repository.createOrder: Database error: %v\n", err)
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.createOrder: Inserting %d order items\n", len(priced.Lines))
+	newOrder.Items, customErr = r.insertOrderItems(tx, newOrder.ID, priced.Lines)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	newOrder.Discounts, customErr = r.insertOrderDiscounts(tx, newOrder.ID, newOrder.Items, priced.Discounts)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	changes := map[stockKey]quantity.Quantity{}
+	addStockChanges(changes, newOrder.Items, -1)
+	if customErr := r.recordOrderStock(tx, userID, newOrder.ID, changes, clientID != nil); customErr != nil {
+		return nil, customErr
+	}
+
+	return &newOrder, nil
+}
+
+// maxClockSkew is how far in the future a terminal's sale time may be
+const maxClockSkew = 5 * time.Minute
+
+// SyncOrders stores a batch of sales made offline. Each order is stored in its
+// own transaction with the terminal's sale time, so one rejected order does
+// not hold back the rest. Orders whose client ID was already uploaded are
+// reported as duplicates instead of being stored again.
+func (r *postgresRepository) SyncOrders(batch *SyncOrders, userID int) ([]SyncResult, *customerror.CustomError) {
+	fmt.Printf("Repository.SyncOrders: Syncing %d orders for user %d\n", len(batch.Orders), userID) // Add log
+	results := make([]SyncResult, 0, len(batch.Orders))
+	for i := range batch.Orders {
+		result, customErr := r.syncOrder(&batch.Orders[i], userID)
+		if customErr != nil {
+			// Server errors abort the batch; the terminal retries the rest
+			if customErr.Code() >= http.StatusInternalServerError {
+				return nil, customErr
+			}
+			result = &SyncResult{ClientID: batch.Orders[i].ClientID, Status: SyncRejected, Message: customErr.Message()}
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// syncOrder stores one offline sale and records its tenders
+func (r *postgresRepository) syncOrder(data *SyncOrder, userID int) (*SyncResult, *customerror.CustomError) {
+	if data.CreatedAt.After(time.Now().Add(maxClockSkew)) {
+		return nil, customerror.NewCustomError(nil, "Sale time is in the future", http.StatusBadRequest)
+	}
+	for _, tender := range data.Tenders {
+		if !tender.Method.IsValid() {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Unsupported payment method: %s", tender.Method), http.StatusBadRequest)
+		}
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.syncOrder: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	// Concurrent uploads of the same order wait here, then see the committed row
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, fmt.Sprintf("sync:%d:%s", userID, data.ClientID)); err != nil {
+		fmt.Printf("Repository.syncOrder: Error locking client ID: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	existing := SyncResult{ClientID: data.ClientID, Status: SyncDuplicate}
+	err = tx.QueryRow(`SELECT id, order_number, status FROM orders WHERE user_id = $1 AND client_id = $2`, userID, data.ClientID).
+		Scan(&existing.OrderID, &existing.OrderNumber, &existing.OrderStatus)
+	if err == nil {
+		return &existing, nil
+	}
+	if err != sql.ErrNoRows {
+		fmt.Printf("Repository.syncOrder: Error checking client ID: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	// Order times are stored without a zone in server time, like online orders
+	at := data.CreatedAt.In(time.Local)
+	order, customErr := r.createOrder(tx, &CreateOrder{Items: data.Items, Discount: data.Discount}, userID, at, &data.ClientID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	// A fully discounted sale has nothing due, so its tenders are not recorded
+	paidInFull := order.Total.IsZero()
+	if !paidInFull && len(data.Tenders) > 0 {
+		payments, _, customErr := applyTenders(order.Total, data.Tenders)
+		if customErr != nil {
+			return nil, customErr
+		}
+		if customErr := r.insertPayments(tx, order.ID, userID, payments, at); customErr != nil {
+			return nil, customErr
+		}
+
+		paid := money.Money{}
+		for _, payment := range payments {
+			paid = paid.Add(payment.Amount)
+		}
+		paidInFull = amountDue(order.Total, paid).IsZero()
+	}
+	if paidInFull {
+		if customErr := r.setOrderStatus(tx, order.ID, userID, order.Status, StatusPaid, "Paid offline"); customErr != nil {
+			return nil, customErr
+		}
+		if customErr := r.setPaidAt(tx, order.ID, at); customErr != nil {
+			return nil, customErr
+		}
+		order.Status = StatusPaid
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.syncOrder: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.syncOrder: Stored offline order %s as %s\n", data.ClientID, order.OrderNumber) // Add log
+	return &SyncResult{
+		ClientID:    data.ClientID,
+		Status:      SyncAccepted,
+		OrderID:     order.ID,
+		OrderNumber: order.OrderNumber,
+		OrderStatus: order.Status,
+	}, nil
+}
+
+// orderNumber is a generated order number and the outlet it belongs to
+type orderNumber struct {
+	Number     string
+	OutletCode string
+}
+
+// nextOrderNumber takes the next number in the outlet's sequence for the
+// business day of at. The sequence row stays locked until the transaction
+// ends, so concurrent orders are numbered one after another and a rolled
+// back order releases its number instead of leaving a gap.
+func (r *postgresRepository) nextOrderNumber(tx *sql.Tx, userID int, at time.Time) (*orderNumber, *customerror.CustomError) {
+	outlet, format := ordernumber.DefaultOutlet, ordernumber.DefaultFormat
+	err := tx.QueryRow(`SELECT outlet_code, order_number_format FROM store_settings WHERE user_id = $1`, userID).Scan(&outlet, &format)
+	if err != nil && err != sql.ErrNoRows {
+		fmt.Printf("Repository.nextOrderNumber: Error loading settings: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	query := `
+        INSERT INTO order_number_sequences (user_id, outlet_code, business_date, last_value)
+        VALUES ($1, $2, $3, 1)
+        ON CONFLICT (user_id, outlet_code, business_date)
+        DO UPDATE SET last_value = order_number_sequences.last_value + 1
+        RETURNING last_value
+    `
+	var seq int
+	if err := tx.QueryRow(query, userID, outlet, at.Format("2006-01-02")).Scan(&seq); err != nil {
+		fmt.Printf("Repository.nextOrderNumber: Error advancing sequence: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return &orderNumber{Number: ordernumber.Format(format, outlet, at, seq), OutletCode: outlet}, nil
+}
+
+// priceRequest prices requested lines against the user's catalog, active
+// promotions and store settings at the given time
+func (r *postgresRepository) priceRequest(tx *sql.Tx, orderData *CreateOrder, userID int, at time.Time) (*pricedOrder, *customerror.CustomError) {
+	catalog, customErr := r.getCatalogProducts(tx, orderData.Items, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	promotions, customErr := r.getActivePromotions(tx, userID, at)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	settings, customErr := r.getTaxSettings(tx, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	scales := barcode.DefaultScaleLayout
+	if hasScaleCode(orderData.Items) {
+		if scales, customErr = barcode.LoadScaleLayout(tx, userID); customErr != nil {
+			return nil, customErr
+		}
+	}
+
+	return priceOrder(orderData, catalog, promotions, settings, scales, at)
+}
+
+// UpdateOrderItems replaces the lines of an open or held order and reprices it
+// with the promotions and settings in effect now. A non-empty label renames
+// the basket. Orders that already took a payment cannot be edited.
+func (r *postgresRepository) UpdateOrderItems(id int, userID int, orderData *CreateOrder) (*Order, *customerror.CustomError) {
+	fmt.Printf("Repository.UpdateOrderItems: Replacing lines of order %d for user %d\n", id, userID) // Add log
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.UpdateOrderItems: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	state, customErr := r.lockOrder(tx, id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if !state.Status.isEditable() {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d is %s and its lines cannot be changed", id, state.Status), http.StatusConflict)
+	}
+
+	paid, customErr := r.getAmountPaid(tx, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if !paid.IsZero() {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d already has payments and its lines cannot be changed", id), http.StatusConflict)
+	}
+
+	now := time.Now()
+	priced, customErr := r.priceRequest(tx, orderData, userID, now)
+	if customErr != nil {
+		fmt.Printf("Repository.UpdateOrderItems: Pricing failed: %s\n", customErr.Message()) // Add log
+		return nil, customErr
+	}
+
+	previous, customErr := r.getOrderItems(tx, []int64{int64(id)})
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	// Line-level discounts cascade with their lines
+	if _, err := tx.Exec(`DELETE FROM order_discounts WHERE order_id = $1`, id); err != nil {
+		fmt.Printf("Repository.UpdateOrderItems: Error deleting discounts: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	if _, err := tx.Exec(`DELETE FROM order_items WHERE order_id = $1`, id); err != nil {
+		fmt.Printf("Repository.UpdateOrderItems: Error deleting lines: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	query := `
+        UPDATE orders
+        SET subtotal = $1, discount_total = $2, service_charge = $3, tax_total = $4, rounding = $5, total = $6,
+            tax_rate = $7, tax_inclusive = $8, service_charge_rate = $9,
+            label = COALESCE(NULLIF($10, ''), label), updated_at = $11
+        WHERE id = $12
+    `
+	if _, err := tx.Exec(
+		query,
+		priced.Subtotal,
+		priced.DiscountTotal,
+		priced.ServiceCharge,
+		priced.TaxTotal,
+		priced.Rounding,
+		priced.Total,
+		priced.Settings.Rate,
+		priced.Settings.Inclusive,
+		priced.Settings.ServiceChargeRate,
+		strings.TrimSpace(orderData.Label),
+		now,
+		id,
+	); err != nil {
+		fmt.Printf("Repository.UpdateOrderItems: Database exec error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	items, customErr := r.insertOrderItems(tx, id, priced.Lines)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if _, customErr := r.insertOrderDiscounts(tx, id, items, priced.Discounts); customErr != nil {
+		return nil, customErr
+	}
+
+	// Only the difference between the old and new lines moves stock
+	changes := map[stockKey]quantity.Quantity{}
+	addStockChanges(changes, previous[id], 1)
+	addStockChanges(changes, items, -1)
+	if customErr := r.recordOrderStock(tx, userID, id, changes, false); customErr != nil {
+		return nil, customErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.UpdateOrderItems: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.UpdateOrderItems: Order %d now has %d lines totalling %s\n", id, len(items), priced.Total) // Add log
+	return r.GetOrderByID(id, userID)
+}
+
+// HoldOrder parks an open order without payments under a label so the
+// cashier can serve the next customer
+func (r *postgresRepository) HoldOrder(id int, userID int, label string) (*Order, *customerror.CustomError) {
+	fmt.Printf("Repository.HoldOrder: Holding order %d for user %d\n", id, userID) // Add log
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return nil, customerror.NewCustomError(nil, "A label is required to hold an order", http.StatusBadRequest)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.HoldOrder: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	state, customErr := r.lockOrder(tx, id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	paid, customErr := r.getAmountPaid(tx, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if !paid.IsZero() {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d already has payments and cannot be held", id), http.StatusConflict)
+	}
+
+	if customErr := r.setOrderStatus(tx, id, userID, state.Status, StatusHeld, label); customErr != nil {
+		return nil, customErr
+	}
+	if _, err := tx.Exec(`UPDATE orders SET label = $1 WHERE id = $2`, label, id); err != nil {
+		fmt.Printf("Repository.HoldOrder: Database exec error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.HoldOrder: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.HoldOrder: Order %d held as %q\n", id, label) // Add log
+	return r.GetOrderByID(id, userID)
+}
+
+// getStatusHistory loads the status changes of an order, oldest first
+func (r *postgresRepository) getStatusHistory(tx *sql.Tx, orderID int) ([]OrderStatusEvent, *customerror.CustomError) {
+	query := `
+        SELECT id, from_status, to_status, reason, performed_by, created_at
+        FROM order_status_events
+        WHERE order_id = $1
+        ORDER BY created_at, id
+    `
+
+	rows, err := tx.Query(query, orderID)
+	if err != nil {
+		fmt.Printf("Repository.getStatusHistory: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var events []OrderStatusEvent
+	for rows.Next() {
+		var event OrderStatusEvent
+		if err := rows.Scan(&event.ID, &event.FromStatus, &event.ToStatus, &event.Reason, &event.PerformedBy, &event.CreatedAt); err != nil {
+			fmt.Printf("Repository.getStatusHistory: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getStatusHistory: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return events, nil
+}
+
+// orderState is the locked, mutable state of an order inside a transaction
+type orderState struct {
+	Status         OrderStatus
+	Total          money.Money
+	TaxInclusive   bool
+	RefundedAmount money.Money
+}
+
+// lockOrder locks an order row owned by the user and returns its current state
+func (r *postgresRepository) lockOrder(tx *sql.Tx, id int, userID int) (*orderState, *customerror.CustomError) {
+	var state orderState
+	err := tx.QueryRow(`SELECT status, total, tax_inclusive, refunded_amount FROM orders WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID).
+		Scan(&state.Status, &state.Total, &state.TaxInclusive, &state.RefundedAmount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(err, fmt.Sprintf("Order with ID %d not found or user not authorized", id), http.StatusNotFound)
+		}
+		fmt.Printf("Repository.lockOrder: Database scan error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return &state, nil
+}
+
+// setOrderStatus moves a locked order to a new status and records who did it and why
+func (r *postgresRepository) setOrderStatus(tx *sql.Tx, id int, userID int, from OrderStatus, to OrderStatus, reason string) *customerror.CustomError {
+	if !CanTransition(from, to) {
+		return customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d cannot move from %s to %s", id, from, to), http.StatusConflict)
+	}
+
+	if _, err := tx.Exec(`UPDATE orders SET status = $1 WHERE id = $2`, to, id); err != nil {
+		fmt.Printf("Repository.setOrderStatus: Database exec error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	if to == StatusPaid {
+		if customErr := r.setPaidAt(tx, id, time.Now()); customErr != nil {
+			return customErr
+		}
+	}
+
+	query := `
+        INSERT INTO order_status_events (order_id, from_status, to_status, reason, performed_by)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+	if _, err := tx.Exec(query, id, from, to, reason, userID); err != nil {
+		fmt.Printf("Repository.setOrderStatus: Error recording status event: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+
+	return nil
+}
+
+// setPaidAt records when an order was paid in full; sales reports count the
+// order on that day
+func (r *postgresRepository) setPaidAt(tx *sql.Tx, id int, at time.Time) *customerror.CustomError {
+	if _, err := tx.Exec(`UPDATE orders SET paid_at = $1 WHERE id = $2`, at, id); err != nil {
+		fmt.Printf("Repository.setPaidAt: Database exec error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// TransitionOrder moves an order to a new status, enforcing the allowed transitions
+func (r *postgresRepository) TransitionOrder(id int, userID int, transition *OrderTransition) (*Order, *customerror.CustomError) {
+	fmt.Printf("Repository.TransitionOrder: Moving order %d to %s for user %d\n", id, transition.To, userID) // Add log
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.TransitionOrder: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	state, customErr := r.lockOrder(tx, id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	from := state.Status
+	if transition.From != "" && from != transition.From {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d is %s, not %s", id, from, transition.From), http.StatusConflict)
+	}
+
+	paid, customErr := r.getAmountPaid(tx, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+	switch transition.To {
+	case StatusPaid:
+		if due := amountDue(state.Total, paid); due.IsPositive() {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d still has %s due; record payments first", id, due), http.StatusConflict)
+		}
+	case StatusVoided:
+		if paid.IsPositive() {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d already has payments and cannot be voided", id), http.StatusConflict)
+		}
+	}
+
+	if customErr := r.setOrderStatus(tx, id, userID, from, transition.To, transition.Reason); customErr != nil {
+		return nil, customErr
+	}
+
+	if transition.To == StatusVoided {
+		if customErr := r.restoreOrderStock(tx, id, userID); customErr != nil {
+			return nil, customErr
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.TransitionOrder: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.TransitionOrder: Order %d moved from %s to %s\n", id, from, transition.To) // Add log
+	return r.GetOrderByID(id, userID)
+}
+
+// getAmountPaid returns the sum of the payments applied to an order
+func (r *postgresRepository) getAmountPaid(tx *sql.Tx, orderID int) (money.Money, *customerror.CustomError) {
+	var paid money.Money
+	if err := tx.QueryRow(`SELECT COALESCE(SUM(amount), 0) FROM payments WHERE order_id = $1`, orderID).Scan(&paid); err != nil {
+		fmt.Printf("Repository.getAmountPaid: Database scan error: %v\n", err) // Add log
+		return money.Money{}, customerror.NewPostgresError(err)
+	}
+	return paid, nil
+}
+
+// getPayments loads the tenders recorded against an order, oldest first
+func (r *postgresRepository) getPayments(tx *sql.Tx, orderID int) ([]Payment, *customerror.CustomError) {
+	query := `
+        SELECT id, order_id, method, amount, tendered, change, reference, received_by, created_at
+        FROM payments
+        WHERE order_id = $1
+        ORDER BY created_at, id
+    `
+
+	rows, err := tx.Query(query, orderID)
+	if err != nil {
+		fmt.Printf("Repository.getPayments: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var payments []Payment
+	for rows.Next() {
+		var payment Payment
+		if err := rows.Scan(&payment.ID, &payment.OrderID, &payment.Method, &payment.Amount, &payment.Tendered, &payment.Change, &payment.Reference, &payment.ReceivedBy, &payment.CreatedAt); err != nil {
+			fmt.Printf("Repository.getPayments: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		payments = append(payments, payment)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getPayments: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return payments, nil
+}
+
+// RecordPayments records one or more tenders against an open order and marks
+// the order as paid once the tendered amounts cover its total
+func (r *postgresRepository) RecordPayments(id int, userID int, request *CreatePayments) (*PaymentResult, *customerror.CustomError) {
+	fmt.Printf("Repository.RecordPayments: Recording %d tenders on order %d for user %d\n", len(request.Tenders), id, userID) // Add log
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.RecordPayments: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	state, customErr := r.lockOrder(tx, id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if state.Status != StatusOpen {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d is %s and cannot take payments", id, state.Status), http.StatusConflict)
+	}
+
+	paid, customErr := r.getAmountPaid(tx, id)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	payments, change, customErr := applyTenders(amountDue(state.Total, paid), request.Tenders)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	if customErr := r.insertPayments(tx, id, userID, payments, time.Now()); customErr != nil {
+		return nil, customErr
+	}
+	for _, payment := range payments {
+		paid = paid.Add(payment.Amount)
+	}
+
+	due := amountDue(state.Total, paid)
+	if due.IsZero() {
+		if customErr := r.setOrderStatus(tx, id, userID, state.Status, StatusPaid, "Paid in full"); customErr != nil {
+			return nil, customErr
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.RecordPayments: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	order, customErr := r.GetOrderByID(id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	fmt.Printf("Repository.RecordPayments: Order %d paid %s, due %s, change %s\n", id, paid, due, change) // Add log
+	return &PaymentResult{
+		Order:      order,
+		Payments:   payments,
+		AmountPaid: paid,
+		AmountDue:  due,
+		Change:     change,
+	}, nil
+}
+
+// insertPayments stores tenders received at the given time, filling in their IDs
+func (r *postgresRepository) insertPayments(tx *sql.Tx, orderID int, userID int, payments []Payment, at time.Time) *customerror.CustomError {
+	query := `
+        INSERT INTO payments (order_id, method, amount, tendered, change, reference, received_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING id, order_id, created_at
+    `
+	for i := range payments {
+		payment := &payments[i]
+		payment.ReceivedBy = userID
+		err := tx.QueryRow(query, orderID, payment.Method, payment.Amount, payment.Tendered, payment.Change, payment.Reference, userID, at).
+			Scan(&payment.ID, &payment.OrderID, &payment.CreatedAt)
+		if err != nil {
+			fmt.Printf("Repository.insertPayments: Database error: %v\n", err) // Add log
+			return customerror.NewPostgresError(err)
+		}
+	}
+	return nil
+}
+
+// GetPayments returns the tenders recorded against an order, checking ownership
+func (r *postgresRepository) GetPayments(id int, userID int) ([]Payment, *customerror.CustomError) {
+	order, customErr := r.GetOrderByID(id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if order.Payments == nil {
+		return []Payment{}, nil
+	}
+	return order.Payments, nil
+}
+
+// getRefunds loads the refunds of an order with their lines, oldest first
+func (r *postgresRepository) getRefunds(tx *sql.Tx, orderID int) ([]Refund, *customerror.CustomError) {
+	query := `
+        SELECT id, order_id, amount, method, reference, reason, refunded_by, created_at
+        FROM refunds
+        WHERE order_id = $1
+        ORDER BY created_at, id
+    `
+
+	rows, err := tx.Query(query, orderID)
+	if err != nil {
+		fmt.Printf("Repository.getRefunds: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var refunds []Refund
+	index := make(map[int]int)
+	for rows.Next() {
+		var refund Refund
+		if err := rows.Scan(&refund.ID, &refund.OrderID, &refund.Amount, &refund.Method, &refund.Reference, &refund.Reason, &refund.RefundedBy, &refund.CreatedAt); err != nil {
+			fmt.Printf("Repository.getRefunds: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		index[refund.ID] = len(refunds)
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getRefunds: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	rows.Close()
+
+	if len(refunds) == 0 {
+		return nil, nil
+	}
+
+	itemQuery := `
+        SELECT ri.id, ri.refund_id, ri.order_item_id, ri.quantity, ri.amount, ri.net_amount, ri.service_charge, ri.tax_amount
+        FROM refund_items ri
+        JOIN refunds rf ON rf.id = ri.refund_id
+        WHERE rf.order_id = $1
+        ORDER BY ri.id
+    `
+	itemRows, err := tx.Query(itemQuery, orderID)
+	if err != nil {
+		fmt.Printf("Repository.getRefunds: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item RefundItem
+		var refundID int
+		if err := itemRows.Scan(&item.ID, &refundID, &item.OrderItemID, &item.Quantity, &item.Amount, &item.NetAmount, &item.ServiceCharge, &item.TaxAmount); err != nil {
+			fmt.Printf("Repository.getRefunds: Error scanning refund item: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		refund := &refunds[index[refundID]]
+		refund.Items = append(refund.Items, item)
+	}
+	if err := itemRows.Err(); err != nil {
+		fmt.Printf("Repository.getRefunds: Error iterating refund items: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return refunds, nil
+}
+
+// CreateRefund returns some or all lines of a paid order. It prevents refunding
+// more than was sold and updates the order's refunded amount and status.
+func (r *postgresRepository) CreateRefund(id int, userID int, request *CreateRefund) (*Refund, *customerror.CustomError) {
+	fmt.Printf("Repository.CreateRefund: Refunding order %d for user %d\n", id, userID) // Add log
+	if !request.Tender.Method.IsValid() {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Unsupported refund method: %s", request.Tender.Method), http.StatusBadRequest)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.CreateRefund: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	state, customErr := r.lockOrder(tx, id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if state.Status != StatusPaid && state.Status != StatusPartiallyRefunded {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d is %s and cannot be refunded", id, state.Status), http.StatusConflict)
+	}
+
+	items, customErr := r.getOrderItems(tx, []int64{int64(id)})
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	lines, amount, customErr := buildRefundLines(items[id], request.Items, state.TaxInclusive)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	// The refund that returns the last units also returns the order's cash
+	// rounding, so a fully refunded order always refunds exactly its total
+	fullyRefunded := isFullyRefunded(items[id], lines)
+	if fullyRefunded {
+		amount = state.Total.Sub(state.RefundedAmount)
+	}
+
+	refund := Refund{
+		OrderID:    id,
+		Amount:     amount,
+		Method:     request.Tender.Method,
+		Reference:  request.Tender.Reference,
+		Reason:     request.Reason,
+		RefundedBy: userID,
+		// Stamped with the same clock as orders and payments, so the refund
+		// counts on the right business day
+		CreatedAt: time.Now(),
+	}
+	query := `
+        INSERT INTO refunds (order_id, amount, method, reference, reason, refunded_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `
+	if err := tx.QueryRow(query, id, amount, refund.Method, refund.Reference, refund.Reason, userID, refund.CreatedAt).Scan(&refund.ID); err != nil {
+		fmt.Printf("Repository.CreateRefund: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	itemQuery := `
+        INSERT INTO refund_items (refund_id, order_item_id, quantity, amount, net_amount, service_charge, tax_amount)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `
+	for _, line := range lines {
+		if err := tx.QueryRow(itemQuery, refund.ID, line.OrderItemID, line.Quantity, line.Amount, line.NetAmount, line.ServiceCharge, line.TaxAmount).Scan(&line.ID); err != nil {
+			fmt.Printf("Repository.CreateRefund: Error inserting refund item: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		refund.Items = append(refund.Items, line)
+	}
+
+	if customErr := r.recordRefundStock(tx, userID, items[id], &refund); customErr != nil {
+		return nil, customErr
+	}
+
+	if _, err := tx.Exec(`UPDATE orders SET refunded_amount = refunded_amount + $1 WHERE id = $2`, amount, id); err != nil {
+		fmt.Printf("Repository.CreateRefund: Error updating refunded amount: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	to := StatusPartiallyRefunded
+	if fullyRefunded {
+		to = StatusRefunded
+	}
+	if customErr := r.setOrderStatus(tx, id, userID, state.Status, to, request.Reason); customErr != nil {
+		return nil, customErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.CreateRefund: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.CreateRefund: Refunded %s on order %d, order is now %s\n", amount, id, to) // Add log
+	return &refund, nil
+}
+
+// getStoreProfile loads the store details printed on the user's receipts
+func (r *postgresRepository) getStoreProfile(userID int) (storeProfile, *customerror.CustomError) {
+	query := `
+        SELECT store_name, store_address, store_phone, tax_id, receipt_footer
+        FROM store_settings
+        WHERE user_id = $1
+    `
+
+	var store storeProfile
+	err := r.db.QueryRow(query, userID).Scan(&store.Header.Name, &store.Header.Address, &store.Header.Phone, &store.Header.TaxID, &store.Footer)
+	if err != nil && err != sql.ErrNoRows {
+		fmt.Printf("Repository.getStoreProfile: Database error: %v\n", err) // Add log
+		return storeProfile{}, customerror.NewPostgresError(err)
+	}
+	return store, nil
+}
+
+// GetReceipt builds the receipt of a sale owned by the user. Open, held and
+// voided orders have no receipt.
+func (r *postgresRepository) GetReceipt(id int, userID int) (*receipt.Receipt, *customerror.CustomError) {
+	fmt.Printf("Repository.GetReceipt: Building receipt for order %d of user %d\n", id, userID) // Add log
+	order, customErr := r.GetOrderByID(id, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	if !order.Status.IsSale() {
+		fmt.Printf("Repository.GetReceipt: Order %d is %s and has no receipt\n", id, order.Status) // Add log
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d is %s; only paid orders have a receipt", id, order.Status), http.StatusConflict)
+	}
+
+	store, customErr := r.getStoreProfile(userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	return buildReceipt(order, store), nil
+}
+
+// getReceiptEmails loads the receipt emails sent for an order, oldest first
+func (r *postgresRepository) getReceiptEmails(tx *sql.Tx, orderID int) ([]ReceiptEmail, *customerror.CustomError) {
+	query := `
+        SELECT id, order_id, email, COALESCE(sent_by, 0), created_at
+        FROM receipt_emails
+        WHERE order_id = $1
+        ORDER BY created_at, id
+    `
+
+	rows, err := tx.Query(query, orderID)
+	if err != nil {
+		fmt.Printf("Repository.getReceiptEmails: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	var emails []ReceiptEmail
+	for rows.Next() {
+		var email ReceiptEmail
+		if err := rows.Scan(&email.ID, &email.OrderID, &email.Email, &email.SentBy, &email.CreatedAt); err != nil {
+			fmt.Printf("Repository.getReceiptEmails: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		emails = append(emails, email)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.getReceiptEmails: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return emails, nil
+}
+
+// RecordReceiptEmail records that the receipt of an order owned by the user
+// was emailed to the given address
+func (r *postgresRepository) RecordReceiptEmail(id int, userID int, email string) (*ReceiptEmail, *customerror.CustomError) {
+	query := `
+        INSERT INTO receipt_emails (order_id, email, sent_by)
+        SELECT id, $3, $2 FROM orders WHERE id = $1 AND user_id = $2
+        RETURNING id, order_id, email, sent_by, created_at
+    `
+
+	var sent ReceiptEmail
+	err := r.db.QueryRow(query, id, userID, email).Scan(&sent.ID, &sent.OrderID, &sent.Email, &sent.SentBy, &sent.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(err, fmt.Sprintf("Order with ID %d not found or user not authorized", id), http.StatusNotFound)
+		}
+		fmt.Printf("Repository.RecordReceiptEmail: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.RecordReceiptEmail: Receipt of order %d sent to %s\n", id, email) // Add log
+	return &sent, nil
+}
+
+// DeleteOrder deletes an open order by ID, checking ownership.
+// Paid, voided and refunded orders are sales history and cannot be deleted.
+func (r *postgresRepository) DeleteOrder(id int, userID int) *customerror.CustomError { // Changed userID to int
+	fmt.Printf("Repository.DeleteOrder: Attempting to delete order %d for user %d\n", id, userID) // Add log
+	tx, err := r.db.Begin()
+	if err != nil {
+		fmt.Printf("Repository.DeleteOrder: Error starting transaction: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	state, customErr := r.lockOrder(tx, id, userID)
+	if customErr != nil {
+		return customErr
+	}
+	if status := state.Status; status != StatusOpen {
+		fmt.Printf("Repository.DeleteOrder: Order %d is %s and cannot be deleted\n", id, status) // Add log
+		return customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d is %s and cannot be deleted; void or refund it instead", id, status), http.StatusConflict)
+	}
+
+	// Payments cascade with the order, so deleting it would lose the tenders taken
+	paid, customErr := r.getAmountPaid(tx, id)
+	if customErr != nil {
+		return customErr
+	}
+	if !paid.IsZero() {
+		fmt.Printf("Repository.DeleteOrder: Order %d already has payments and cannot be deleted\n", id) // Add log
+		return customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d already has payments and cannot be deleted", id), http.StatusConflict)
+	}
+
+	if customErr := r.restoreOrderStock(tx, id, userID); customErr != nil {
+		return customErr
+	}
+
+	query := `DELETE FROM orders WHERE id = $1 AND user_id = $2 AND status = $3`
+	fmt.Println("Repository.DeleteOrder: Executing delete query") // Add log
+	result, err := tx.Exec(query, id, userID, StatusOpen)
+	if err != nil {
+		fmt.Printf("Repository.DeleteOrder: Database exec error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		fmt.Printf("Repository.DeleteOrder: Error getting rows affected: %v\n", err) // Add log
+		return customerror.NewCustomError(err, "failed to check rows affected", http.StatusInternalServerError)
+	}
+
+	if rowsAffected == 0 {
+		fmt.Printf("Repository.DeleteOrder: Order %d not found or user %d not authorized to delete\n", id, userID) // Add log
+		return customerror.NewCustomError(nil, fmt.Sprintf("Order with ID %d not found or user not authorized to delete", id), http.StatusNotFound)
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.DeleteOrder: Error committing transaction: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.DeleteOrder: Successfully deleted order %d for user %d\n", id, userID) // Add log
+	return nil
+}
+
+// exportScanner reads extra columns selected after orderColumns
+type exportScanner struct {
+	rows  *sql.Rows
+	extra []any
+}
+
+func (s exportScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.extra...)...)
+}
+
+// ExportOrders streams every order matching the filter to write, newest
+// first. The filter's cursor and limit are ignored.
+func (r *postgresRepository) ExportOrders(userID int, filter *OrderFilter, write func(*ExportedOrder) error) *customerror.CustomError {
+	fmt.Printf("Repository.ExportOrders: Exporting orders for user %d\n", userID) // Add log
+	exportFilter := *filter
+	exportFilter.Cursor = nil
+	where, args := exportFilter.where(userID)
+	query := `
+        SELECT ` + orderColumns + `,
+            ARRAY(SELECT DISTINCT p.method FROM payments p WHERE p.order_id = orders.id ORDER BY p.method)
+        FROM orders
+        WHERE ` + where + `
+        ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		fmt.Printf("Repository.ExportOrders: Database query error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var exported ExportedOrder
+		var methods pq.StringArray
+		if err := scanOrder(exportScanner{rows: rows, extra: []any{&methods}}, &exported.Order); err != nil {
+			fmt.Printf("Repository.ExportOrders: Error scanning row: %v\n", err) // Add log
+			return customerror.NewPostgresError(err)
+		}
+		for _, method := range methods {
+			exported.PaymentMethods = append(exported.PaymentMethods, PaymentMethod(method))
+		}
+		if err := write(&exported); err != nil {
+			fmt.Printf("Repository.ExportOrders: Error writing row: %v\n", err) // Add log
+			return customerror.NewCustomError(err, "Failed to write export", http.StatusInternalServerError)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.ExportOrders: Error iterating rows: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.ExportOrders: Exported %d orders for user %d\n", count, userID) // Add log
+	return nil
+}
+
+// ExportOrderItems streams the lines of every order matching the filter to
+// write, newest order first. The filter's cursor and limit are ignored.
+func (r *postgresRepository) ExportOrderItems(userID int, filter *OrderFilter, write func(*ExportedOrderItem) error) *customerror.CustomError {
+	fmt.Printf("Repository.ExportOrderItems: Exporting order lines for user %d\n", userID) // Add log
+	exportFilter := *filter
+	exportFilter.Cursor = nil
+	where, args := exportFilter.where(userID)
+	query := `
+        SELECT o.order_number, o.status, o.created_at, o.paid_at,
+            oi.id, oi.order_id, oi.product_id, oi.name, oi.category_id, oi.category, oi.quantity, oi.price, oi.total_price,
+            oi.discount_amount, oi.tax_class, oi.service_charge, oi.tax_amount,
+            COALESCE((SELECT SUM(ri.quantity) FROM refund_items ri WHERE ri.order_item_id = oi.id), 0),
+            oi.variant, oi.options, oi.modifiers, oi.note, oi.unit
+        FROM order_items oi
+        JOIN orders o ON o.id = oi.order_id
+        WHERE oi.order_id IN (SELECT id FROM orders WHERE ` + where + `)
+        ORDER BY o.created_at DESC, o.id DESC, oi.id`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		fmt.Printf("Repository.ExportOrderItems: Database query error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var item ExportedOrderItem
+		var options, modifiers []byte
+		if err := rows.Scan(
+			&item.OrderNumber,
+			&item.OrderStatus,
+			&item.OrderedAt,
+			&item.PaidAt,
+			&item.ID,
+			&item.OrderID,
+			&item.ProductID,
+			&item.Name,
+			&item.CategoryID,
+			&item.Category,
+			&item.Quantity,
+			&item.Price,
+			&item.TotalPrice,
+			&item.DiscountAmount,
+			&item.TaxClass,
+			&item.ServiceCharge,
+			&item.TaxAmount,
+			&item.RefundedQuantity,
+			&item.Variant,
+			&options,
+			&modifiers,
+			&item.Note,
+			&item.Unit,
+		); err != nil {
+			fmt.Printf("Repository.ExportOrderItems: Error scanning row: %v\n", err) // Add log
+			return customerror.NewPostgresError(err)
+		}
+		if err := json.Unmarshal(options, &item.Options); err != nil {
+			return customerror.NewCustomError(err, "Failed to read order line options", http.StatusInternalServerError)
+		}
+		if err := json.Unmarshal(modifiers, &item.Modifiers); err != nil {
+			return customerror.NewCustomError(err, "Failed to read order line modifiers", http.StatusInternalServerError)
+		}
+		if err := write(&item); err != nil {
+			fmt.Printf("Repository.ExportOrderItems: Error writing row: %v\n", err) // Add log
+			return customerror.NewCustomError(err, "Failed to write export", http.StatusInternalServerError)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.ExportOrderItems: Error iterating rows: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.ExportOrderItems: Exported %d order lines for user %d\n", count, userID) // Add log
 	return nil
 }
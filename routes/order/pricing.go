@@ -0,0 +1,242 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/barcode"
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/promo"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+// pricedOrder is the server-computed content of a new order
+type pricedOrder struct {
+	Lines         []OrderItem
+	Discounts     []OrderDiscount
+	Subtotal      money.Money
+	DiscountTotal money.Money
+	ServiceCharge money.Money
+	TaxTotal      money.Money
+	Rounding      money.Money
+	Total         money.Money
+	Settings      tax.Settings
+}
+
+// priceOrder resolves the requested items against the caller's catalog,
+// applies active promotions and manual discounts, adds service charge and
+// tax, and returns the snapshotted lines with the computed totals. Prices
+// always come from the catalog, never from the client.
+func priceOrder(request *CreateOrder, catalog map[int]*catalogProduct, promotions []promo.Promotion, settings tax.Settings, scales barcode.ScaleLayout, at time.Time) (*pricedOrder, *customerror.CustomError) {
+	lines, customErr := priceOrderLines(request.Items, catalog, scales)
+	if customErr != nil {
+		return nil, customErr
+	}
+
+	// Catalog prices share the store currency; the engines work in its minor units
+	currency := lines[0].Price.Currency
+	promoLines := make([]promo.Line, len(lines))
+	for i, line := range lines {
+		promoLines[i] = promoLine(line)
+		if manual := request.Items[i].Discount; manual != nil {
+			rule, customErr := manualRule(manual)
+			if customErr != nil {
+				return nil, customErr
+			}
+			promoLines[i].Manual = rule
+		}
+	}
+
+	var orderManual *promo.Manual
+	if request.Discount != nil {
+		if orderManual, customErr = manualRule(request.Discount); customErr != nil {
+			return nil, customErr
+		}
+	}
+
+	result := promo.Evaluate(promoLines, promotions, orderManual, at)
+	taxLines := make([]tax.Line, len(lines))
+	for i := range lines {
+		lines[i].DiscountAmount = money.New(result.LineDiscounts[i], currency)
+		taxLines[i] = tax.Line{Amount: lines[i].TotalPrice.Sub(lines[i].DiscountAmount).Amount, Class: lines[i].TaxClass}
+	}
+
+	breakdown := tax.Compute(taxLines, settings)
+	for i := range lines {
+		lines[i].ServiceCharge = money.New(breakdown.LineServiceCharges[i], currency)
+		lines[i].TaxAmount = money.New(breakdown.LineTaxes[i], currency)
+	}
+
+	discounts := make([]OrderDiscount, 0, len(result.Discounts))
+	for _, applied := range result.Discounts {
+		discount := OrderDiscount{
+			Source: applied.Source,
+			Name:   applied.Name,
+			Reason: applied.Reason,
+			Amount: money.New(applied.Amount, currency),
+			line:   applied.Line,
+		}
+		if applied.PromotionID != 0 {
+			promotionID := applied.PromotionID
+			discount.PromotionID = &promotionID
+		}
+		discounts = append(discounts, discount)
+	}
+
+	return &pricedOrder{
+		Lines:         lines,
+		Discounts:     discounts,
+		Subtotal:      money.New(result.Subtotal, currency),
+		DiscountTotal: money.New(result.DiscountTotal, currency),
+		ServiceCharge: money.New(breakdown.ServiceCharge, currency),
+		TaxTotal:      money.New(breakdown.Tax, currency),
+		Rounding:      money.New(breakdown.Rounding, currency),
+		Total:         money.New(breakdown.Total, currency),
+		Settings:      settings,
+	}, nil
+}
+
+// promoLine returns the line the promotion engine evaluates. A weighed line,
+// or one priced by its scale barcode, counts as a single item of its total.
+func promoLine(line OrderItem) promo.Line {
+	evaluated := promo.Line{ProductID: *line.ProductID, CategoryID: *line.CategoryID, UnitPrice: line.Price.Amount}
+	units := int64(line.Quantity / quantity.Scale)
+	if line.Quantity.IsWhole() && line.Price.Mul(units) == line.TotalPrice {
+		evaluated.Quantity = int(units)
+	} else {
+		evaluated.UnitPrice, evaluated.Quantity = line.TotalPrice.Amount, 1
+	}
+	return evaluated
+}
+
+// priceOrderLines resolves the requested items against the caller's catalog
+// and returns the undiscounted, snapshotted order lines. Lines are priced by
+// their variant, if any, plus the price deltas of the chosen options and
+// modifiers, times the quantity rounded by the product's price rounding, or
+// at the price a scale barcode embeds.
+func priceOrderLines(items []CreateOrderItem, catalog map[int]*catalogProduct, scales barcode.ScaleLayout) ([]OrderItem, *customerror.CustomError) {
+	if len(items) == 0 {
+		return nil, customerror.NewCustomError(nil, "Order must contain at least one item", http.StatusBadRequest)
+	}
+
+	lines := make([]OrderItem, 0, len(items))
+	for _, item := range items {
+		if item.ScaleCode == "" && item.Quantity <= 0 {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Quantity for product %d must be greater than zero", item.ProductID), http.StatusBadRequest)
+		}
+
+		product, ok := catalog[item.ProductID]
+		if !ok {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Product with ID %d not found", item.ProductID), http.StatusNotFound)
+		}
+		if !product.IsAvailable {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Product with ID %d is not available", item.ProductID), http.StatusBadRequest)
+		}
+
+		productID := product.ID
+		categoryID := product.CategoryID
+		line := OrderItem{
+			ProductID:  &productID,
+			Name:       product.Name,
+			CategoryID: &categoryID,
+			Category:   product.CategoryName,
+			TaxClass:   product.TaxClass,
+			Quantity:   item.Quantity,
+			Unit:       product.Unit,
+			Price:      product.Price,
+		}
+
+		switch {
+		case item.VariantID != nil:
+			variant, ok := product.Variants[*item.VariantID]
+			if !ok {
+				return nil, customerror.NewCustomError(nil, fmt.Sprintf("Variant with ID %d not found for product %d", *item.VariantID, item.ProductID), http.StatusNotFound)
+			}
+			if !variant.IsAvailable {
+				return nil, customerror.NewCustomError(nil, fmt.Sprintf("%s %s is not available", product.Name, variant.Name), http.StatusBadRequest)
+			}
+			variantID := variant.ID
+			line.VariantID, line.Variant, line.Price = &variantID, variant.Name, variant.Price
+		case len(product.Variants) > 0:
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Choose a variant of %s", product.Name), http.StatusBadRequest)
+		}
+
+		choices, err := option.Select(product.Groups, item.OptionIDs)
+		if err != nil {
+			return nil, customerror.NewCustomError(err, fmt.Sprintf("Invalid options for %s: %v", product.Name, err), http.StatusBadRequest)
+		}
+		modifiers, err := option.Select(product.Modifiers, item.ModifierIDs)
+		if err != nil {
+			return nil, customerror.NewCustomError(err, fmt.Sprintf("Invalid modifiers for %s: %v", product.Name, err), http.StatusBadRequest)
+		}
+		line.Options, line.Modifiers = choices, modifiers
+		line.Note = strings.TrimSpace(item.Note)
+		line.Price = line.Price.Add(option.Total(choices, line.Price.Currency)).Add(option.Total(modifiers, line.Price.Currency))
+		if line.Price.IsNegative() {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("Options and modifiers of %s make its price negative", product.Name), http.StatusBadRequest)
+		}
+		line.TotalPrice = lineTotal(line.Price, line.Quantity, product.PriceRounding)
+
+		if item.ScaleCode != "" {
+			if customErr := readScaleCode(&line, item, product, scales); customErr != nil {
+				return nil, customErr
+			}
+		}
+		if err := product.Unit.Check(line.Quantity); err != nil {
+			return nil, customerror.NewCustomError(err, fmt.Sprintf("Invalid quantity for %s: %v", product.Name, err), http.StatusBadRequest)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// lineTotal returns the price of a quantity, rounding the amount of decimal
+// quantities to the currency's minor unit
+func lineTotal(price money.Money, amount quantity.Quantity, rounding tax.RoundingMode) money.Money {
+	if rounding == "" || rounding == tax.RoundNone {
+		rounding = tax.RoundNearest
+	}
+	total := tax.Round(price.Amount*int64(amount), rounding, quantity.Scale) / quantity.Scale
+	return money.New(total, price.Currency)
+}
+
+// readScaleCode sets the quantity of a line from its scale barcode, and its
+// total from barcodes that embed the price. The barcode's item code must be
+// the SKU or a barcode of the product, or of the variant sold.
+func readScaleCode(line *OrderItem, item CreateOrderItem, product *catalogProduct, scales barcode.ScaleLayout) *customerror.CustomError {
+	scale, ok := scales.Parse(item.ScaleCode)
+	if !ok {
+		return customerror.NewCustomError(nil, fmt.Sprintf("%s is not a scale barcode of this store", item.ScaleCode), http.StatusBadRequest)
+	}
+	variantID, ok := product.Codes[scale.ItemCode]
+	if !ok || (variantID != 0 && (line.VariantID == nil || *line.VariantID != variantID)) {
+		return customerror.NewCustomError(nil, fmt.Sprintf("Scale barcode %s is not one of %s", item.ScaleCode, product.Name), http.StatusBadRequest)
+	}
+
+	amount, err := scale.Quantity(product.Unit, line.Price.Amount)
+	if err != nil {
+		return customerror.NewCustomError(err, fmt.Sprintf("Invalid scale barcode for %s: %v", product.Name, err), http.StatusBadRequest)
+	}
+	line.Quantity = amount
+	if scale.Kind == barcode.ScalePrice {
+		line.TotalPrice = money.New(scale.Value, line.Price.Currency)
+	} else {
+		line.TotalPrice = lineTotal(line.Price, amount, product.PriceRounding)
+	}
+	return nil
+}
+
+// manualRule converts and validates a cashier discount
+func manualRule(discount *ManualDiscount) (*promo.Manual, *customerror.CustomError) {
+	rule := &promo.Manual{Type: discount.Type, Value: discount.Value, Reason: discount.Reason}
+	if err := rule.Validate(); err != nil {
+		return nil, customerror.NewCustomError(err, "Invalid manual discount: "+err.Error(), http.StatusBadRequest)
+	}
+	return rule, nil
+}
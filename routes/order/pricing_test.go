@@ -0,0 +1,236 @@
+package order
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/barcode"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+func idr(amount int64) money.Money {
+	return money.New(amount, money.DefaultCurrency)
+}
+
+func intPtr(value int) *int {
+	return &value
+}
+
+// scaleCode returns an EAN-13 scale barcode with its check digit
+func scaleCode(prefix string, itemCode string, value string) string {
+	code := prefix + itemCode + value
+	return code + strconv.Itoa(barcode.CheckDigit(code))
+}
+
+// testCatalog returns products sold by the item, some with variants,
+// options and modifiers, and products sold by weight
+func testCatalog() map[int]*catalogProduct {
+	return map[int]*catalogProduct{
+		1: {
+			ID: 1, Name: "Latte", Price: idr(20000), IsAvailable: true, Unit: quantity.Each,
+			Groups: []option.Group{{ID: 1, Name: "Size", Required: true, MaxSelect: 1, Options: []option.Option{
+				{ID: 10, Name: "Regular", IsAvailable: true},
+				{ID: 11, Name: "Large", PriceDelta: idr(5000), IsAvailable: true},
+			}}},
+			Modifiers: []option.Group{{ID: 2, Name: "Extras", Options: []option.Option{
+				{ID: 20, Name: "Extra shot", PriceDelta: idr(3000), IsAvailable: true},
+				{ID: 21, Name: "Own cup", PriceDelta: idr(-30000), IsAvailable: true},
+			}}},
+		},
+		2: {
+			ID: 2, Name: "Tea", Price: idr(12000), IsAvailable: true, Unit: quantity.Each,
+			Variants: map[int]*catalogVariant{
+				30: {ID: 30, Name: "Hot", Price: idr(15000), IsAvailable: true},
+				31: {ID: 31, Name: "Jumbo", Price: idr(25000)},
+			},
+		},
+		3: {
+			ID: 3, Name: "Rice", Price: idr(65000), IsAvailable: true, Unit: quantity.Kilogram,
+			PriceRounding: tax.RoundNearest, Codes: map[string]int{"12345": 0},
+		},
+		4: {ID: 4, Name: "Cheese", Price: idr(65), IsAvailable: true, Unit: quantity.Gram},
+		5: {ID: 5, Name: "Sold out", Price: idr(10000), Unit: quantity.Each},
+		6: {ID: 6, Name: "Cake", Price: idr(22750), IsAvailable: true, Unit: quantity.Each, Codes: map[string]int{"54321": 0}},
+		7: {
+			ID: 7, Name: "Juice", Price: idr(30000), IsAvailable: true, Unit: quantity.Each,
+			Codes:    map[string]int{"77777": 70},
+			Variants: map[int]*catalogVariant{70: {ID: 70, Name: "Orange", Price: idr(30000), IsAvailable: true}},
+		},
+	}
+}
+
+func TestPriceOrderLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      []CreateOrderItem
+		wantPrice  int64
+		wantTotal  int64
+		wantStatus int
+	}{
+		{
+			name:      "options and modifiers",
+			items:     []CreateOrderItem{{ProductID: 1, Quantity: quantity.FromInt(2), OptionIDs: []int{11}, ModifierIDs: []int{20}}},
+			wantPrice: 28000,
+			wantTotal: 56000,
+		},
+		{
+			name:      "variant price",
+			items:     []CreateOrderItem{{ProductID: 2, VariantID: intPtr(30), Quantity: quantity.FromInt(3)}},
+			wantPrice: 15000,
+			wantTotal: 45000,
+		},
+		{name: "no items", wantStatus: http.StatusBadRequest},
+		{
+			name:       "zero quantity",
+			items:      []CreateOrderItem{{ProductID: 1, OptionIDs: []int{10}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "negative quantity",
+			items:      []CreateOrderItem{{ProductID: 1, Quantity: quantity.FromInt(-1), OptionIDs: []int{10}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown product",
+			items:      []CreateOrderItem{{ProductID: 9, Quantity: quantity.FromInt(1)}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unavailable product",
+			items:      []CreateOrderItem{{ProductID: 5, Quantity: quantity.FromInt(1)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "required option missing",
+			items:      []CreateOrderItem{{ProductID: 1, Quantity: quantity.FromInt(1)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "variant missing",
+			items:      []CreateOrderItem{{ProductID: 2, Quantity: quantity.FromInt(1)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown variant",
+			items:      []CreateOrderItem{{ProductID: 2, VariantID: intPtr(99), Quantity: quantity.FromInt(1)}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "unavailable variant",
+			items:      []CreateOrderItem{{ProductID: 2, VariantID: intPtr(31), Quantity: quantity.FromInt(1)}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "decimal kilograms",
+			items:     []CreateOrderItem{{ProductID: 3, Quantity: 350}},
+			wantPrice: 65000,
+			wantTotal: 22750,
+		},
+		{
+			name:       "decimal items",
+			items:      []CreateOrderItem{{ProductID: 1, Quantity: 1500, OptionIDs: []int{10}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "decimal grams",
+			items:      []CreateOrderItem{{ProductID: 4, Quantity: 350500}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "weight scale barcode",
+			items:     []CreateOrderItem{{ProductID: 3, ScaleCode: scaleCode("21", "12345", "00350")}},
+			wantPrice: 65000,
+			wantTotal: 22750,
+		},
+		{
+			name:       "modifiers make the price negative",
+			items:      []CreateOrderItem{{ProductID: 1, Quantity: quantity.FromInt(1), OptionIDs: []int{10}, ModifierIDs: []int{21}}},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, customErr := priceOrderLines(tt.items, testCatalog(), barcode.DefaultScaleLayout)
+			if tt.wantStatus != 0 {
+				if assert.NotNil(t, customErr) {
+					assert.Equal(t, tt.wantStatus, customErr.Code())
+				}
+				return
+			}
+			if assert.Nil(t, customErr) && assert.Len(t, lines, 1) {
+				assert.Equal(t, idr(tt.wantPrice), lines[0].Price)
+				assert.Equal(t, idr(tt.wantTotal), lines[0].TotalPrice)
+			}
+		})
+	}
+}
+
+func TestLineTotal(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   quantity.Quantity
+		rounding tax.RoundingMode
+		want     int64
+	}{
+		{name: "whole quantity", amount: quantity.FromInt(3), want: 29997},
+		{name: "nearest by default", amount: 333, want: 3330},
+		{name: "no rounding rounds to nearest", amount: 333, rounding: tax.RoundNone, want: 3330},
+		{name: "up", amount: 333, rounding: tax.RoundUp, want: 3330},
+		{name: "down", amount: 333, rounding: tax.RoundDown, want: 3329},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, idr(tt.want), lineTotal(idr(9999), tt.amount, tt.rounding))
+		})
+	}
+}
+
+func TestReadScaleCode(t *testing.T) {
+	catalog := testCatalog()
+	tests := []struct {
+		name         string
+		product      int
+		variantID    *int
+		code         string
+		wantQuantity quantity.Quantity
+		wantTotal    int64
+		wantErr      bool
+	}{
+		{name: "weight", product: 3, code: scaleCode("21", "12345", "00350"), wantQuantity: 350, wantTotal: 22750},
+		{name: "price per kg", product: 3, code: scaleCode("26", "12345", "22750"), wantQuantity: 350, wantTotal: 22750},
+		{name: "price of an item", product: 6, code: scaleCode("26", "54321", "22750"), wantQuantity: quantity.FromInt(1), wantTotal: 22750},
+		{name: "variant code", product: 7, variantID: intPtr(70), code: scaleCode("26", "77777", "30000"), wantQuantity: quantity.FromInt(1), wantTotal: 30000},
+		{name: "variant code without the variant", product: 7, code: scaleCode("26", "77777", "30000"), wantErr: true},
+		{name: "no weight", product: 3, code: scaleCode("21", "12345", "00000"), wantErr: true},
+		{name: "no price", product: 6, code: scaleCode("26", "54321", "00000"), wantErr: true},
+		{name: "weight of an item", product: 6, code: scaleCode("21", "54321", "00350"), wantErr: true},
+		{name: "code of another product", product: 3, code: scaleCode("21", "54321", "00350"), wantErr: true},
+		{name: "not a scale prefix", product: 3, code: scaleCode("50", "12345", "00350"), wantErr: true},
+		{name: "wrong check digit", product: 3, code: "2112345003500", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := catalog[tt.product]
+			line := OrderItem{VariantID: tt.variantID, Price: product.Price}
+			customErr := readScaleCode(&line, CreateOrderItem{ScaleCode: tt.code}, product, barcode.DefaultScaleLayout)
+			if tt.wantErr {
+				if assert.NotNil(t, customErr) {
+					assert.Equal(t, http.StatusBadRequest, customErr.Code())
+				}
+				return
+			}
+			if assert.Nil(t, customErr) {
+				assert.Equal(t, tt.wantQuantity, line.Quantity)
+				assert.Equal(t, idr(tt.wantTotal), line.TotalPrice)
+			}
+		})
+	}
+}
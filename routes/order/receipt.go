@@ -0,0 +1,154 @@
+package order
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/receipt"
+)
+
+// ReceiptFormat is the output format of a rendered receipt
+type ReceiptFormat string
+
+const (
+	ReceiptText   ReceiptFormat = "text"
+	ReceiptESCPOS ReceiptFormat = "escpos"
+	ReceiptHTML   ReceiptFormat = "html"
+)
+
+// storeProfile is the store information printed on receipts
+type storeProfile struct {
+	Header receipt.Header
+	Footer string
+}
+
+// buildReceipt converts an order into the receipt printed for it. Line
+// discounts are shown under their line and order discounts under the
+// subtotal.
+func buildReceipt(order *Order, store storeProfile) *receipt.Receipt {
+	r := &receipt.Receipt{
+		Header:             store.Header,
+		Number:             order.OrderNumber,
+		Date:               order.CreatedAt,
+		Subtotal:           order.Subtotal,
+		ServiceChargeLabel: "Service " + formatPercent(order.ServiceChargeRate),
+		ServiceCharge:      order.ServiceCharge,
+		TaxLabel:           "PPN " + formatPercent(order.TaxRate),
+		TaxInclusive:       order.TaxInclusive,
+		Tax:                order.TaxTotal,
+		Rounding:           order.Rounding,
+		Total:              order.Total,
+		Footer:             store.Footer,
+	}
+	if order.Status != StatusPaid {
+		r.Status = receiptStatus(order.Status)
+	}
+
+	lineDiscounts := make(map[int][]receipt.Adjustment)
+	for _, discount := range order.Discounts {
+		adjustment := receipt.Adjustment{Label: discountLabel(discount), Amount: discount.Amount}
+		if discount.OrderItemID == nil {
+			r.Discounts = append(r.Discounts, adjustment)
+			continue
+		}
+		lineDiscounts[*discount.OrderItemID] = append(lineDiscounts[*discount.OrderItemID], adjustment)
+	}
+
+	for _, item := range order.Items {
+		r.Lines = append(r.Lines, receipt.Line{
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			Unit:      item.Unit,
+			UnitPrice: item.Price,
+			Total:     item.TotalPrice,
+			Details:   lineDetails(item),
+			Discounts: lineDiscounts[item.ID],
+		})
+	}
+
+	r.Change = money.New(0, order.Total.Currency)
+	for _, payment := range order.Payments {
+		r.Tenders = append(r.Tenders, receipt.Tender{
+			Method:   payment.Method.Label(),
+			Amount:   payment.Amount,
+			Tendered: payment.Tendered,
+			Change:   payment.Change,
+		})
+		r.Change = r.Change.Add(payment.Change)
+	}
+
+	return r
+}
+
+// lineDetails lists the variant, chosen options and modifiers and the note
+// printed under a line. Choices that change the price show their price delta.
+func lineDetails(item OrderItem) []string {
+	var details []string
+	if item.Variant != "" {
+		details = append(details, item.Variant)
+	}
+	for _, choices := range [][]option.Choice{item.Options, item.Modifiers} {
+		for _, choice := range choices {
+			detail := choice.Group + ": " + choice.Name
+			if choice.PriceDelta.IsPositive() {
+				detail += " +" + choice.PriceDelta.Format()
+			} else if choice.PriceDelta.IsNegative() {
+				detail += " " + choice.PriceDelta.Format()
+			}
+			details = append(details, detail)
+		}
+	}
+	if item.Note != "" {
+		details = append(details, "Note: "+item.Note)
+	}
+	return details
+}
+
+// buildKitchenTicket converts an order into the ticket sent to the kitchen:
+// each line with its variant, options, modifiers and note, without prices
+func buildKitchenTicket(order *Order) *receipt.KitchenTicket {
+	ticket := &receipt.KitchenTicket{Number: order.OrderNumber, Label: order.Label, Date: order.CreatedAt}
+	for _, item := range order.Items {
+		line := receipt.KitchenLine{Name: item.Name, Quantity: item.Quantity, Unit: item.Unit, Note: item.Note}
+		if item.Variant != "" {
+			line.Details = append(line.Details, item.Variant)
+		}
+		for _, choices := range [][]option.Choice{item.Options, item.Modifiers} {
+			for _, choice := range choices {
+				line.Details = append(line.Details, choice.Group+": "+choice.Name)
+			}
+		}
+		ticket.Lines = append(ticket.Lines, line)
+	}
+	return ticket
+}
+
+// optionSummary joins the chosen options of a line, such as "Size: Large, Ice: Less"
+func optionSummary(choices []option.Choice) string {
+	parts := make([]string, len(choices))
+	for i, choice := range choices {
+		parts[i] = choice.Group + ": " + choice.Name
+	}
+	return strings.Join(parts, ", ")
+}
+
+// receiptStatus is the banner printed on receipts of sales that were refunded
+func receiptStatus(status OrderStatus) string {
+	return strings.ReplaceAll(string(status), "_", " ")
+}
+
+// discountLabel names a discount on the receipt, adding the cashier's reason
+// to manual discounts
+func discountLabel(discount OrderDiscount) string {
+	if discount.Reason != "" && discount.Reason != discount.Name {
+		return discount.Name + " (" + discount.Reason + ")"
+	}
+	return discount.Name
+}
+
+// formatPercent formats a rate such as 11 or 2.5 as "11%" or "2.5%"
+func formatPercent(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64) + "%"
+}
@@ -0,0 +1,90 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+// buildRefundLines validates the requested return quantities against the
+// order lines and returns the refund lines with the total amount to refund.
+// An empty request refunds everything that has not been refunded yet.
+func buildRefundLines(items []OrderItem, requested []RefundItemRequest, taxInclusive bool) ([]RefundItem, money.Money, *customerror.CustomError) {
+	lines := make(map[int]*OrderItem, len(items))
+	for i := range items {
+		lines[items[i].ID] = &items[i]
+	}
+
+	if len(requested) == 0 {
+		for _, item := range items {
+			if remaining := item.Quantity - item.RefundedQuantity; remaining > 0 {
+				requested = append(requested, RefundItemRequest{OrderItemID: item.ID, Quantity: remaining})
+			}
+		}
+		if len(requested) == 0 {
+			return nil, money.Money{}, customerror.NewCustomError(nil, "Every line of this order has already been refunded", http.StatusConflict)
+		}
+	}
+
+	refundLines := make([]RefundItem, 0, len(requested))
+	pending := make(map[int]quantity.Quantity, len(requested))
+	var amount money.Money
+	for _, req := range requested {
+		line, ok := lines[req.OrderItemID]
+		if !ok {
+			return nil, money.Money{}, customerror.NewCustomError(nil, fmt.Sprintf("Order line with ID %d not found on this order", req.OrderItemID), http.StatusNotFound)
+		}
+		if req.Quantity <= 0 {
+			return nil, money.Money{}, customerror.NewCustomError(nil, fmt.Sprintf("Refund quantity for line %d must be greater than zero", req.OrderItemID), http.StatusBadRequest)
+		}
+		if err := line.Unit.Check(req.Quantity); err != nil {
+			return nil, money.Money{}, customerror.NewCustomError(err, fmt.Sprintf("Invalid refund quantity for line %d: %v", req.OrderItemID, err), http.StatusBadRequest)
+		}
+
+		pending[line.ID] += req.Quantity
+		if remaining := line.Quantity - line.RefundedQuantity; pending[line.ID] > remaining {
+			return nil, money.Money{}, customerror.NewCustomError(nil, fmt.Sprintf("Cannot refund %s of line %d; only %s left to refund", pending[line.ID], line.ID, remaining), http.StatusBadRequest)
+		}
+
+		// Refund each part of what was paid for the line pro rata; computing
+		// it from the cumulative quantity makes the last unit absorb any rounding
+		before := line.RefundedQuantity + pending[line.ID] - req.Quantity
+		share := func(part money.Money) money.Money {
+			sold, after := int64(line.Quantity), int64(before+req.Quantity)
+			return money.New(part.Amount*after/sold-part.Amount*int64(before)/sold, part.Currency)
+		}
+		refundLine := RefundItem{
+			OrderItemID:   line.ID,
+			Quantity:      req.Quantity,
+			NetAmount:     share(line.TotalPrice.Sub(line.DiscountAmount)),
+			ServiceCharge: share(line.ServiceCharge),
+			TaxAmount:     share(line.TaxAmount),
+		}
+		refundLine.Amount = refundLine.NetAmount.Add(refundLine.ServiceCharge)
+		if !taxInclusive {
+			refundLine.Amount = refundLine.Amount.Add(refundLine.TaxAmount)
+		}
+		refundLines = append(refundLines, refundLine)
+		amount = amount.Add(refundLine.Amount)
+	}
+
+	return refundLines, amount, nil
+}
+
+// isFullyRefunded reports whether every line will be refunded once the pending
+// refund lines are applied
+func isFullyRefunded(items []OrderItem, refundLines []RefundItem) bool {
+	pending := make(map[int]quantity.Quantity, len(refundLines))
+	for _, line := range refundLines {
+		pending[line.OrderItemID] += line.Quantity
+	}
+	for _, item := range items {
+		if item.RefundedQuantity+pending[item.ID] < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
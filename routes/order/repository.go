@@ -2,6 +2,7 @@ package order
 
 import (
 	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/receipt"
 )
 
 type orderRepository struct {
@@ -15,9 +16,29 @@ func NewOrderRepository(dbRepo OrderRepository) OrderRepository {
 	}
 }
 
-// GetOrders returns all orders for a specific user
-func (r *orderRepository) GetOrders(userID int) ([]*Order, *customerror.CustomError) {
-	return r.dbRepo.GetOrders(userID)
+// GetOrders returns one page of a user's orders matching the filter
+func (r *orderRepository) GetOrders(userID int, filter *OrderFilter) (*OrderPage, *customerror.CustomError) {
+	return r.dbRepo.GetOrders(userID, filter)
+}
+
+// SyncOrders stores a batch of offline sales, skipping ones already uploaded
+func (r *orderRepository) SyncOrders(batch *SyncOrders, userID int) ([]SyncResult, *customerror.CustomError) {
+	return r.dbRepo.SyncOrders(batch, userID)
+}
+
+// GetHeldOrders returns the parked baskets of a specific user
+func (r *orderRepository) GetHeldOrders(userID int) ([]*Order, *customerror.CustomError) {
+	return r.dbRepo.GetHeldOrders(userID)
+}
+
+// UpdateOrderItems replaces and reprices the lines of an open or held order
+func (r *orderRepository) UpdateOrderItems(id int, userID int, order *CreateOrder) (*Order, *customerror.CustomError) {
+	return r.dbRepo.UpdateOrderItems(id, userID, order)
+}
+
+// HoldOrder parks an open order under a label
+func (r *orderRepository) HoldOrder(id int, userID int, label string) (*Order, *customerror.CustomError) {
+	return r.dbRepo.HoldOrder(id, userID, label)
 }
 
 // GetOrderByID returns a specific order by ID, checking ownership
@@ -25,12 +46,52 @@ func (r *orderRepository) GetOrderByID(id int, userID int) (*Order, *customerror
 	return r.dbRepo.GetOrderByID(id, userID)
 }
 
-// CreateOrder creates a new order for the given user
+// CreateOrder prices and creates a new order for the given user
 func (r *orderRepository) CreateOrder(order *CreateOrder, userID int) (*Order, *customerror.CustomError) {
 	return r.dbRepo.CreateOrder(order, userID)
 }
 
-// DeleteOrder deletes an order by ID, checking ownership
+// DeleteOrder deletes an open order by ID, checking ownership
 func (r *orderRepository) DeleteOrder(id int, userID int) *customerror.CustomError {
 	return r.dbRepo.DeleteOrder(id, userID)
 }
+
+// TransitionOrder moves an order to a new status, checking ownership
+func (r *orderRepository) TransitionOrder(id int, userID int, transition *OrderTransition) (*Order, *customerror.CustomError) {
+	return r.dbRepo.TransitionOrder(id, userID, transition)
+}
+
+// RecordPayments records tenders against an order, checking ownership
+func (r *orderRepository) RecordPayments(id int, userID int, request *CreatePayments) (*PaymentResult, *customerror.CustomError) {
+	return r.dbRepo.RecordPayments(id, userID, request)
+}
+
+// GetPayments returns the tenders recorded against an order, checking ownership
+func (r *orderRepository) GetPayments(id int, userID int) ([]Payment, *customerror.CustomError) {
+	return r.dbRepo.GetPayments(id, userID)
+}
+
+// CreateRefund refunds some or all lines of an order, checking ownership
+func (r *orderRepository) CreateRefund(id int, userID int, request *CreateRefund) (*Refund, *customerror.CustomError) {
+	return r.dbRepo.CreateRefund(id, userID, request)
+}
+
+// GetReceipt builds the receipt of an order, checking ownership
+func (r *orderRepository) GetReceipt(id int, userID int) (*receipt.Receipt, *customerror.CustomError) {
+	return r.dbRepo.GetReceipt(id, userID)
+}
+
+// RecordReceiptEmail records that an order's receipt was emailed, checking ownership
+func (r *orderRepository) RecordReceiptEmail(id int, userID int, email string) (*ReceiptEmail, *customerror.CustomError) {
+	return r.dbRepo.RecordReceiptEmail(id, userID, email)
+}
+
+// ExportOrders streams the user's orders matching the filter
+func (r *orderRepository) ExportOrders(userID int, filter *OrderFilter, write func(*ExportedOrder) error) *customerror.CustomError {
+	return r.dbRepo.ExportOrders(userID, filter, write)
+}
+
+// ExportOrderItems streams the lines of the user's orders matching the filter
+func (r *orderRepository) ExportOrderItems(userID int, filter *OrderFilter, write func(*ExportedOrderItem) error) *customerror.CustomError {
+	return r.dbRepo.ExportOrderItems(userID, filter, write)
+}
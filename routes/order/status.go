@@ -0,0 +1,61 @@
+package order
+
+// OrderStatus is the lifecycle state of an order
+type OrderStatus string
+
+const (
+	StatusOpen              OrderStatus = "open"
+	StatusHeld              OrderStatus = "held"
+	StatusPaid              OrderStatus = "paid"
+	StatusVoided            OrderStatus = "voided"
+	StatusPartiallyRefunded OrderStatus = "partially_refunded"
+	StatusRefunded          OrderStatus = "refunded"
+)
+
+// allowedTransitions lists the states an order may move to from each state.
+// Held orders are parked baskets that are resumed to open or discarded.
+// Voided and refunded orders are final.
+var allowedTransitions = map[OrderStatus][]OrderStatus{
+	StatusOpen:              {StatusPaid, StatusVoided, StatusHeld},
+	StatusHeld:              {StatusOpen, StatusVoided},
+	StatusPaid:              {StatusPartiallyRefunded, StatusRefunded},
+	StatusPartiallyRefunded: {StatusPartiallyRefunded, StatusRefunded},
+}
+
+// CanTransition reports whether an order may move from one status to another
+func CanTransition(from, to OrderStatus) bool {
+	for _, allowed := range allowedTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid reports whether the status is a known order status
+func (s OrderStatus) IsValid() bool {
+	switch s {
+	case StatusOpen, StatusHeld, StatusPaid, StatusVoided, StatusPartiallyRefunded, StatusRefunded:
+		return true
+	}
+	return false
+}
+
+// SaleStatuses are the statuses of orders that count as sales. Open and held
+// baskets are not sales until they are paid, and voided orders never are.
+var SaleStatuses = []OrderStatus{StatusPaid, StatusPartiallyRefunded, StatusRefunded}
+
+// IsSale reports whether orders in this status count as sales
+func (s OrderStatus) IsSale() bool {
+	for _, status := range SaleStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// isEditable reports whether the lines of an order in this status may still change
+func (s OrderStatus) isEditable() bool {
+	return s == StatusOpen || s == StatusHeld
+}
@@ -0,0 +1,129 @@
+package order
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/stock"
+)
+
+// stockKey identifies the stock a line draws from: the product's own, or
+// that of one of its variants when VariantID is not zero
+type stockKey struct {
+	ProductID int
+	VariantID int
+}
+
+// lineStockKey returns the stock a line draws from. Lines of deleted
+// products draw from none.
+func lineStockKey(line OrderItem) (stockKey, bool) {
+	if line.ProductID == nil {
+		return stockKey{}, false
+	}
+	key := stockKey{ProductID: *line.ProductID}
+	if line.VariantID != nil {
+		key.VariantID = *line.VariantID
+	}
+	return key, true
+}
+
+// movement returns a ledger movement of the key's stock
+func (k stockKey) movement(movementType stock.MovementType, amount quantity.Quantity, userID int) stock.Movement {
+	movement := stock.Movement{ProductID: k.ProductID, Type: movementType, Quantity: amount, CreatedBy: userID}
+	if k.VariantID != 0 {
+		variantID := k.VariantID
+		movement.VariantID = &variantID
+	}
+	return movement
+}
+
+// addStockChanges adds the quantities of the lines to the stock changes,
+// negated when sign is negative
+func addStockChanges(changes map[stockKey]quantity.Quantity, lines []OrderItem, sign int) {
+	for _, line := range lines {
+		if key, ok := lineStockKey(line); ok {
+			change := line.Quantity
+			if sign < 0 {
+				change = -change
+			}
+			changes[key] += change
+		}
+	}
+}
+
+// recordOrderStock records the stock changes of an order. Quantities taken
+// out of stock are sales and quantities put back are voids. Offline sales already
+// happened, so they may always take stock below zero.
+func (r *postgresRepository) recordOrderStock(tx *sql.Tx, userID int, orderID int, changes map[stockKey]quantity.Quantity, offline bool) *customerror.CustomError {
+	movements := make([]stock.Movement, 0, len(changes))
+	needsStock := false
+	for key, change := range changes {
+		movementType := stock.MovementVoid
+		if change < 0 {
+			movementType = stock.MovementSale
+			needsStock = true
+		}
+		movement := key.movement(movementType, change, userID)
+		movement.OrderID = &orderID
+		movements = append(movements, movement)
+	}
+
+	allowNegative := offline || !needsStock
+	if !allowNegative {
+		var customErr *customerror.CustomError
+		if allowNegative, customErr = stock.AllowNegative(tx, userID); customErr != nil {
+			fmt.Printf("Repository.recordOrderStock: Error loading stock settings: %s\n", customErr.Original()) // Add log
+			return customErr
+		}
+	}
+
+	recorded, customErr := stock.Record(tx, userID, movements, allowNegative)
+	if customErr != nil {
+		fmt.Printf("Repository.recordOrderStock: Stock not recorded for order %d: %s\n", orderID, customErr.Message()) // Add log
+		return customErr
+	}
+	fmt.Printf("Repository.recordOrderStock: Recorded %d stock movements for order %d\n", len(recorded), orderID) // Add log
+	return nil
+}
+
+// restoreOrderStock puts everything sold on an unpaid order back into stock when
+// the order is voided or deleted
+func (r *postgresRepository) restoreOrderStock(tx *sql.Tx, id int, userID int) *customerror.CustomError {
+	items, customErr := r.getOrderItems(tx, []int64{int64(id)})
+	if customErr != nil {
+		return customErr
+	}
+	changes := map[stockKey]quantity.Quantity{}
+	addStockChanges(changes, items[id], 1)
+	return r.recordOrderStock(tx, userID, id, changes, false)
+}
+
+// recordRefundStock puts what was returned in a refund back into stock
+func (r *postgresRepository) recordRefundStock(tx *sql.Tx, userID int, items []OrderItem, refund *Refund) *customerror.CustomError {
+	keys := make(map[int]stockKey, len(items))
+	for _, item := range items {
+		if key, ok := lineStockKey(item); ok {
+			keys[item.ID] = key
+		}
+	}
+	changes := map[stockKey]quantity.Quantity{}
+	for _, line := range refund.Items {
+		if key, ok := keys[line.OrderItemID]; ok {
+			changes[key] += line.Quantity
+		}
+	}
+
+	movements := make([]stock.Movement, 0, len(changes))
+	for key, change := range changes {
+		movement := key.movement(stock.MovementRefund, change, userID)
+		movement.OrderID, movement.RefundID, movement.Reason = &refund.OrderID, &refund.ID, refund.Reason
+		movements = append(movements, movement)
+	}
+	if _, customErr := stock.Record(tx, userID, movements, true); customErr != nil {
+		fmt.Printf("Repository.recordRefundStock: Error restocking refund %d: %s\n", refund.ID, customErr.Original()) // Add log
+		return customErr
+	}
+	return nil
+}
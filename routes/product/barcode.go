@@ -0,0 +1,120 @@
+package product
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+)
+
+// @Summary Get barcodes of a product
+// @Description Retrieves the barcodes of a product and its variants. UPC-A codes are listed in their 13-digit EAN form.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} dto.DataResponse[[]Barcode] "Successfully retrieved barcodes"
+// @Failure 400 {object} dto.MessageResponse "Invalid product ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/barcodes [get]
+func (h *Handler) GetBarcodes(c *gin.Context) {
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+
+	barcodes, customErr := h.repository.GetBarcodes(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]Barcode]{Data: barcodes})
+}
+
+// @Summary Replace barcodes of a product
+// @Description Replaces every barcode of a product and its variants. EAN-13, EAN-8 and UPC-A codes must carry a valid check digit; other codes are stored as internal codes. A code can only belong to one product or variant of the store, and cannot be another item's SKU.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param barcodes body SaveBarcodes true "Barcodes of the product and its variants"
+// @Success 200 {object} dto.DataResponse[[]Barcode] "Barcodes saved"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data, code or ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product or variant not found"
+// @Failure 409 {object} dto.MessageResponse "Code already used by another product"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/barcodes [put]
+func (h *Handler) ReplaceBarcodes(c *gin.Context) {
+	var request SaveBarcodes
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	barcodes, err := request.barcodes()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid barcode: " + err.Error()})
+		return
+	}
+
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+
+	barcodes, customErr := h.repository.ReplaceBarcodes(id, userID, barcodes)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]Barcode]{Data: barcodes})
+}
+
+// @Summary Look up a scanned code
+// @Description Resolves a scanned barcode or SKU to the product, and the variant when the code is one of a variant. Barcodes take precedence over SKUs, and UPC-A and EAN-13 forms of the same code match each other. Codes printed by the store's scales resolve to the product named by their item code, with the weighed quantity and, for price codes, the price.
+// @Tags products
+// @Produce json
+// @Param code query string true "Scanned barcode or SKU"
+// @Success 200 {object} dto.DataResponse[LookupResult] "Product found"
+// @Failure 400 {object} dto.MessageResponse "Missing code"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "No product or variant has the code"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/lookup [get]
+func (h *Handler) LookupProduct(c *gin.Context) {
+	code := strings.TrimSpace(c.Query("code"))
+	if code == "" || len(code) > 64 {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "code must be between 1 and 64 characters"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	result, customErr := h.repository.Lookup(userID, code)
+	if customErr != nil {
+		if customErr.Code() != http.StatusNotFound {
+			fmt.Printf("LookupProduct: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		}
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*LookupResult]{Data: result})
+}
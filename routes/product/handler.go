@@ -29,10 +29,23 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 	router.PUT("/:id", h.UpdateProduct)
 	router.DELETE("/:id", h.DeleteProduct)
 	router.GET("/category/:categoryID", h.GetProductsByCategoryID)
+	router.POST("/import", h.ImportProducts)
+	router.GET("/lookup", h.LookupProduct)
+	router.POST("/labels", h.RenderLabels)
+	router.GET("/:id/stock/movements", h.GetStockMovements)
+	router.POST("/:id/stock/movements", h.RecordStockMovement)
+	router.GET("/:id/options", h.GetOptionGroups)
+	router.PUT("/:id/options", h.ReplaceOptionGroups)
+	router.GET("/:id/variants", h.GetVariants)
+	router.POST("/:id/variants", h.CreateVariant)
+	router.PUT("/:id/variants/:variantID", h.UpdateVariant)
+	router.DELETE("/:id/variants/:variantID", h.DeleteVariant)
+	router.GET("/:id/barcodes", h.GetBarcodes)
+	router.PUT("/:id/barcodes", h.ReplaceBarcodes)
 }
 
 // @Summary Create a new product
-// @Description Creates a new product associated with the authenticated user.
+// @Description Creates a new product associated with the authenticated user. Products sold by weight or measure set unit to kg, g, l or m; their price is per unit and line totals are rounded by price_rounding.
 // @Tags products
 // @Accept json
 // @Produce json
@@ -40,6 +53,7 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
 // @Success 201 {object} Product "Product created successfully"
 // @Failure 400 {object} dto.MessageResponse "Invalid request data"
 // @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 409 {object} dto.MessageResponse "SKU already used by another product"
 // @Failure 500 {object} dto.MessageResponse "Internal Server Error"
 // @Router /products [post]
 func (h *Handler) CreateProduct(c *gin.Context) {
@@ -115,6 +129,7 @@ func (h *Handler) GetAllProducts(c *gin.Context) {
 // @Failure 400 {object} dto.MessageResponse "Invalid request data or ID format"
 // @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context or not owner"
 // @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 409 {object} dto.MessageResponse "SKU already used by another product"
 // @Failure 500 {object} dto.MessageResponse "Internal Server Error"
 // @Router /products/{id} [put]
 func (h *Handler) UpdateProduct(c *gin.Context) {
@@ -0,0 +1,259 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+	"github.com/yantology/simple-pos/pkg/export"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+const (
+	// maxImportSize is the largest import file accepted, in bytes
+	maxImportSize = 5 << 20
+	// maxImportRows is the largest number of products imported at once
+	maxImportRows = 5000
+)
+
+// importColumns maps the accepted header names to the import fields
+var importColumns = map[string]string{
+	"name":          "name",
+	"product":       "name",
+	"price":         "price",
+	"category":      "category",
+	"category_name": "category",
+	"available":     "available",
+	"is_available":  "available",
+	"availability":  "available",
+	"sku":           "sku",
+	"tax_class":     "tax_class",
+	"unit":          "unit",
+}
+
+// requiredImportColumns must appear in the header of every import file
+var requiredImportColumns = []string{"name", "price", "category"}
+
+// parseAvailability reads a yes/no cell. An empty cell means available.
+func parseAvailability(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "true", "yes", "y", "1", "available":
+		return true, nil
+	case "false", "no", "n", "0", "unavailable":
+		return false, nil
+	default:
+		return false, fmt.Errorf("availability must be yes or no, got %q", value)
+	}
+}
+
+// parseImportFile reads and validates the rows of an import file. Rows that
+// fail validation are returned as failed results instead of rows. Prices are
+// read with the locale's separators.
+func parseImportFile(r io.Reader, locale export.Locale) ([]ImportRow, []ImportRowResult, error) {
+	reader, err := export.NewCSVReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		if field, ok := importColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range requiredImportColumns {
+		if _, ok := columns[field]; !ok {
+			return nil, nil, fmt.Errorf("the header must have a %s column", field)
+		}
+	}
+
+	var rows []ImportRow
+	var failed []ImportRowResult
+	// Each product may only appear once, keyed by SKU or else by name
+	seen := map[string]int{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			failed = append(failed, ImportRowResult{Line: line, Status: ImportFailed, Errors: []string{err.Error()}})
+			continue
+		}
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		if len(rows)+len(failed) >= maxImportRows {
+			return nil, nil, fmt.Errorf("an import may have at most %d rows", maxImportRows)
+		}
+
+		row := ImportRow{Line: line, Name: cell("name"), Category: cell("category"), TaxClass: tax.Class(strings.ToLower(cell("tax_class"))),
+			Unit: quantity.Unit(strings.ToLower(cell("unit")))}
+		var problems []string
+		if row.Name == "" {
+			problems = append(problems, "name is required")
+		} else if len(row.Name) > 255 {
+			problems = append(problems, "name must be at most 255 characters")
+		}
+		if row.Category == "" {
+			problems = append(problems, "category is required")
+		} else if len(row.Category) > 255 {
+			problems = append(problems, "category must be at most 255 characters")
+		}
+		if price, err := money.Parse(locale.PlainNumber(cell("price")), money.DefaultCurrency); err != nil {
+			problems = append(problems, fmt.Sprintf("price %q is not a valid amount", cell("price")))
+		} else if !price.IsPositive() {
+			problems = append(problems, "price must be greater than zero")
+		} else {
+			row.Price = price
+		}
+		if row.IsAvailable, err = parseAvailability(cell("available")); err != nil {
+			problems = append(problems, err.Error())
+		}
+		if sku := cell("sku"); sku != "" {
+			if len(sku) > 64 {
+				problems = append(problems, "sku must be at most 64 characters")
+			}
+			row.SKU = &sku
+		}
+		if row.TaxClass != "" && row.TaxClass != tax.ClassStandard && row.TaxClass != tax.ClassExempt {
+			problems = append(problems, fmt.Sprintf("tax_class must be %s or %s", tax.ClassStandard, tax.ClassExempt))
+		}
+		switch row.Unit {
+		case "", quantity.Each, quantity.Kilogram, quantity.Gram, quantity.Litre, quantity.Metre:
+		default:
+			problems = append(problems, "unit must be each, kg, g, l or m")
+		}
+
+		key := "name:" + strings.ToLower(row.Name)
+		if row.SKU != nil {
+			key = "sku:" + *row.SKU
+		}
+		if first, ok := seen[key]; ok {
+			problems = append(problems, fmt.Sprintf("duplicate of line %d", first))
+		} else {
+			seen[key] = line
+		}
+
+		if len(problems) > 0 {
+			failed = append(failed, ImportRowResult{Line: line, Status: ImportFailed, Name: row.Name, SKU: row.SKU, Category: row.Category, Errors: problems})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, failed, nil
+}
+
+// importFile returns the uploaded CSV: the file form field of a multipart
+// upload, or else the request body itself
+func importFile(c *gin.Context) (io.ReadCloser, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("the upload must have a file field")
+		}
+		return header.Open()
+	}
+	return c.Request.Body, nil
+}
+
+// @Summary Import products from CSV
+// @Description Creates or updates products from a CSV file with the columns name, price, category, available and sku, plus an optional tax_class and unit (each, kg, g, l or m). Products are matched by SKU, or by name when the row has no SKU or no product has it; matched products are updated and the others created. Missing categories are created. Prices use the separators of the locale query parameter or the Accept-Language header, and files saved with semicolons are read as such. The file is sent as the file field of a multipart upload or as the raw request body. Each row is imported on its own: the report lists the created, updated and failed rows with the reasons for failures. With dry_run nothing is saved and the report shows what the import would do.
+// @Tags products
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Param file formData file false "CSV file"
+// @Param dry_run query bool false "Validate and report without saving"
+// @Param locale query string false "Number format of prices, such as id or en"
+// @Success 200 {object} dto.DataResponse[ImportReport] "Import report"
+// @Failure 400 {object} dto.MessageResponse "Invalid file"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 413 {object} dto.MessageResponse "File too large"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/import [post]
+func (h *Handler) ImportProducts(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "dry_run must be true or false"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	file, err := importFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid file: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	rows, failed, err := parseImportFile(file, export.RequestLocale(c.Request))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, dto.MessageResponse{Message: fmt.Sprintf("The file must be at most %d MB", maxImportSize>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid file: " + err.Error()})
+		return
+	}
+	fmt.Printf("ImportProducts: Parsed %d valid and %d invalid rows for user %d\n", len(rows), len(failed), userID) // Add log
+
+	results, customErr := h.repository.Import(userID, rows, dryRun)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	report := &ImportReport{DryRun: dryRun, Rows: append(results, failed...)}
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].Line < report.Rows[j].Line })
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		switch row.Status {
+		case ImportCreated:
+			report.Created++
+		case ImportUpdated:
+			report.Updated++
+		default:
+			report.Failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*ImportReport]{Data: report})
+}
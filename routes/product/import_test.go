@@ -0,0 +1,124 @@
+package product
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yantology/simple-pos/pkg/export"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func TestParseImportFile(t *testing.T) {
+	indonesian, _ := export.LookupLocale("id")
+	idr := func(amount int64) money.Money {
+		return money.New(amount, money.DefaultCurrency)
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		locale     export.Locale
+		wantRows   []ImportRow
+		wantFailed []ImportRowResult
+		wantErr    bool
+	}{
+		{
+			name: "header aliases",
+			file: "Product,Category_Name,Price,Is_Available,SKU,Tax_Class,Unit\nRice,Staples,65000,no,RICE-1,Exempt,KG\n",
+			wantRows: []ImportRow{{
+				Line: 2, Name: "Rice", Price: idr(65000), Category: "Staples", SKU: stringPtr("RICE-1"),
+				TaxClass: tax.ClassExempt, Unit: quantity.Kilogram,
+			}},
+		},
+		{
+			name:     "thousands separators",
+			file:     "name,price,category\nLatte,\"25,000\",Coffee\n",
+			wantRows: []ImportRow{{Line: 2, Name: "Latte", Price: idr(25000), Category: "Coffee", IsAvailable: true}},
+		},
+		{
+			name:     "semicolons and a decimal comma",
+			file:     "name;price;category\nLatte;25.000;Coffee\n",
+			locale:   indonesian,
+			wantRows: []ImportRow{{Line: 2, Name: "Latte", Price: idr(25000), Category: "Coffee", IsAvailable: true}},
+		},
+		{
+			name:     "blank lines are skipped",
+			file:     "name,price,category\n,,\nLatte,25000,Coffee\n",
+			wantRows: []ImportRow{{Line: 3, Name: "Latte", Price: idr(25000), Category: "Coffee", IsAvailable: true}},
+		},
+		{
+			name: "duplicate SKU",
+			file: "name,price,category,sku\nLatte,25000,Coffee,COF-1\nIced latte,28000,Coffee,COF-1\n",
+			wantRows: []ImportRow{
+				{Line: 2, Name: "Latte", Price: idr(25000), Category: "Coffee", IsAvailable: true, SKU: stringPtr("COF-1")},
+			},
+			wantFailed: []ImportRowResult{
+				{Line: 3, Status: ImportFailed, Name: "Iced latte", SKU: stringPtr("COF-1"), Category: "Coffee", Errors: []string{"duplicate of line 2"}},
+			},
+		},
+		{
+			name: "duplicate name without SKU",
+			file: "name,price,category\nLatte,25000,Coffee\nLATTE,26000,Coffee\n",
+			wantRows: []ImportRow{
+				{Line: 2, Name: "Latte", Price: idr(25000), Category: "Coffee", IsAvailable: true},
+			},
+			wantFailed: []ImportRowResult{
+				{Line: 3, Status: ImportFailed, Name: "LATTE", Category: "Coffee", Errors: []string{"duplicate of line 2"}},
+			},
+		},
+		{
+			name: "same name with different SKUs",
+			file: "name,price,category,sku\nLatte,25000,Coffee,COF-1\nLatte,28000,Coffee,COF-2\n",
+			wantRows: []ImportRow{
+				{Line: 2, Name: "Latte", Price: idr(25000), Category: "Coffee", IsAvailable: true, SKU: stringPtr("COF-1")},
+				{Line: 3, Name: "Latte", Price: idr(28000), Category: "Coffee", IsAvailable: true, SKU: stringPtr("COF-2")},
+			},
+		},
+		{
+			name: "invalid cells",
+			file: "name,price,category,available,tax_class,unit\n,0,,maybe,luxury,box\n",
+			wantFailed: []ImportRowResult{{Line: 2, Status: ImportFailed, Errors: []string{
+				"name is required",
+				"category is required",
+				"price must be greater than zero",
+				`availability must be yes or no, got "maybe"`,
+				"tax_class must be standard or exempt",
+				"unit must be each, kg, g, l or m",
+			}}},
+		},
+		{
+			name:   "price with more decimals than the currency",
+			file:   "name;price;category\nLatte;25,50;Coffee\n",
+			locale: indonesian,
+			wantFailed: []ImportRowResult{
+				{Line: 2, Status: ImportFailed, Name: "Latte", Category: "Coffee", Errors: []string{`price "25,50" is not a valid amount`}},
+			},
+		},
+		{name: "missing required column", file: "name,price\nLatte,25000\n", wantErr: true},
+		{name: "empty file", file: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locale := tt.locale
+			if locale.Tag == "" {
+				locale = export.DefaultLocale
+			}
+			rows, failed, err := parseImportFile(strings.NewReader(tt.file), locale)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRows, rows)
+			assert.Equal(t, tt.wantFailed, failed)
+		})
+	}
+}
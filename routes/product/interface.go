@@ -1,6 +1,11 @@
 package product
 
-import "github.com/yantology/simple-pos/pkg/customerror"
+import (
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/label"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/stock"
+)
 
 // Repository defines the interface for product data operations
 type Repository interface {
@@ -9,4 +14,29 @@ type Repository interface {
 	Update(id int, userID int, product *UpdateProduct) (*Product, *customerror.CustomError) // Changed id and userID to int
 	Delete(id int, userID int) *customerror.CustomError                                     // Changed id and userID to int
 	GetByCategoryID(categoryID int) ([]*Product, *customerror.CustomError)
+	// Export streams the user's products, optionally of one category, to write
+	Export(userID int, categoryID *int, write func(*ExportedProduct) error) *customerror.CustomError
+	// Import creates or updates the user's products from validated import
+	// rows, matching existing products by SKU or else by name
+	Import(userID int, rows []ImportRow, dryRun bool) ([]ImportRowResult, *customerror.CustomError)
+	// GetStockMovements lists a product's stock ledger, newest first, below
+	// the cursor's movement ID when it is not zero
+	GetStockMovements(id int, userID int, variantID *int, cursor int, limit int) (*StockMovementPage, *customerror.CustomError)
+	// RecordStockMovement records stock received, wasted or corrected by hand
+	RecordStockMovement(id int, userID int, request *CreateStockMovement) (*stock.Movement, *customerror.CustomError)
+	GetOptionGroups(id int, userID int) ([]option.Group, *customerror.CustomError)
+	// ReplaceOptionGroups replaces every option group of a product
+	ReplaceOptionGroups(id int, userID int, groups []option.Group) ([]option.Group, *customerror.CustomError)
+	GetVariants(id int, userID int) ([]Variant, *customerror.CustomError)
+	CreateVariant(id int, userID int, request *SaveVariant) (*Variant, *customerror.CustomError)
+	UpdateVariant(id int, variantID int, userID int, request *SaveVariant) (*Variant, *customerror.CustomError)
+	DeleteVariant(id int, variantID int, userID int) *customerror.CustomError
+	GetBarcodes(id int, userID int) ([]Barcode, *customerror.CustomError)
+	// ReplaceBarcodes replaces every barcode of a product and its variants
+	ReplaceBarcodes(id int, userID int, barcodes []Barcode) ([]Barcode, *customerror.CustomError)
+	// Lookup finds the product or variant with the given barcode or SKU
+	Lookup(userID int, code string) (*LookupResult, *customerror.CustomError)
+	// GetLabels returns the labels of the requested products and variants,
+	// each repeated for its copies
+	GetLabels(userID int, items []LabelItem, encode LabelEncode) ([]label.Label, *customerror.CustomError)
 }
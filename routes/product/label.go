@@ -0,0 +1,85 @@
+package product
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+	"github.com/yantology/simple-pos/pkg/label"
+)
+
+// @Summary Render shelf and price labels
+// @Description Renders labels with the name, price and a barcode of the selected products and variants. By default the barcode encodes the first stored barcode of the product or variant (EAN-13 codes as EAN-13, others as Code128), else its SKU, else the product ID; encode=id always encodes the product ID. png and svg render a single label, pdf lays the labels out on A4 sheets in a grid and zpl returns one label per ^XA block for Zebra printers.
+// @Tags products
+// @Accept json
+// @Produce png
+// @Produce image/svg+xml
+// @Produce application/pdf
+// @Produce plain
+// @Param labels body LabelRequest true "Products, format and layout of the labels"
+// @Success 200 {file} file "Rendered labels"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data, layout or code"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product or variant not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/labels [post]
+func (h *Handler) RenderLabels(c *gin.Context) {
+	var request LabelRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	size, grid, err := request.layout()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid label layout: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	labels, customErr := h.repository.GetLabels(userID, request.Items, request.Encode)
+	if customErr != nil {
+		fmt.Printf("RenderLabels: Error from repository: %s (code: %d)\n", customErr.Message(), customErr.Code()) // Add log
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	var rendered []byte
+	var contentType string
+	switch request.Format {
+	case label.FormatPNG:
+		rendered, err = label.PNG(labels[0], size, request.DPI)
+		contentType = "image/png"
+	case label.FormatSVG:
+		rendered, err = label.SVG(labels[0], size)
+		contentType = "image/svg+xml"
+	case label.FormatPDF:
+		rendered, err = label.PDF(labels, size, grid)
+		contentType = "application/pdf"
+	case label.FormatZPL:
+		rendered, err = label.ZPL(labels, size, request.DPI)
+		contentType = "text/plain; charset=utf-8"
+	}
+	if err != nil {
+		// Layouts are validated up front, so what is left is a code the
+		// symbology cannot encode or a label too small to draw
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Cannot render labels: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="labels.%s"`, request.Format))
+	c.Data(http.StatusOK, contentType, rendered)
+}
@@ -1,18 +1,42 @@
 package product
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/barcode"
+	"github.com/yantology/simple-pos/pkg/label"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/stock"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
 
 // ProductResponse represents the product data returned in API responses
 // @Description Product model
 type Product struct {
-	ID          int       `json:"id" example:"1"` // Changed from string to int
-	Name        string    `json:"name" example:"Laptop Pro"`
-	Price       float64   `json:"price" example:"15000000"`
-	IsAvailable bool      `json:"is_available" example:"true"`
-	CategoryID  int       `json:"category_id" example:"1"` // Changed from string to int
-	UserID      int       `json:"user_id" example:"1"`     // Changed from string to int
-	CreatedAt   time.Time `json:"created_at" example:"2025-04-25T15:04:05Z07:00"`
-	UpdatedAt   time.Time `json:"updated_at" example:"2025-04-25T15:04:05Z07:00"`
+	ID          int         `json:"id" example:"1"` // Changed from string to int
+	Name        string      `json:"name" example:"Laptop Pro"`
+	Price       money.Money `json:"price"`
+	IsAvailable bool        `json:"is_available" example:"true"`
+	CategoryID  int         `json:"category_id" example:"1"` // Changed from string to int
+	TaxClass    tax.Class   `json:"tax_class" example:"standard"`
+	// SKU is the store's own product code, unique per user
+	SKU *string `json:"sku" example:"LAP-PRO-13"`
+	// StockOnHand is only kept for products that track stock
+	TrackStock  bool              `json:"track_stock" example:"true"`
+	StockOnHand quantity.Quantity `json:"stock_on_hand" example:"12"`
+	// Unit is what Price is per and stock is counted in. PriceRounding rounds
+	// the amount of decimal quantities, such as 0.35 kg, to the currency's
+	// minor unit.
+	Unit          quantity.Unit    `json:"unit" example:"each"`
+	PriceRounding tax.RoundingMode `json:"price_rounding" example:"nearest"`
+	UserID        int              `json:"user_id" example:"1"` // Changed from string to int
+	CreatedAt     time.Time        `json:"created_at" example:"2025-04-25T15:04:05Z07:00"`
+	UpdatedAt     time.Time        `json:"updated_at" example:"2025-04-25T15:04:05Z07:00"`
 }
 
 // ProductListResponse represents the response for listing products
@@ -24,17 +48,405 @@ type ProductList struct {
 // UpdateProduct defines the structure for updating a product
 // @Description Update product request model
 type UpdateProduct struct {
-	Name        string  `json:"name" binding:"required" example:"Laptop Pro X"`
-	Price       float64 `json:"price" binding:"required,gt=0" example:"16500000"`
-	IsAvailable bool    `json:"is_available" example:"false"`
-	CategoryID  int     `json:"category_id" binding:"required" example:"2"` // Changed from string to int
+	Name        string      `json:"name" binding:"required" example:"Laptop Pro X"`
+	Price       money.Money `json:"price" binding:"money,required,gt=0"`
+	IsAvailable bool        `json:"is_available" example:"false"`
+	CategoryID  int         `json:"category_id" binding:"required" example:"2"` // Changed from string to int
+	// TaxClass is standard or exempt, defaulting to standard
+	TaxClass tax.Class `json:"tax_class" binding:"omitempty,oneof=standard exempt" example:"standard"`
+	// SKU is optional; an empty SKU clears it
+	SKU string `json:"sku" binding:"max=64" example:"LAP-PRO-13"`
+	// TrackStock turns stock tracking on or off; omitted keeps the current setting
+	TrackStock *bool `json:"track_stock" example:"true"`
+	// Unit and PriceRounding keep their current setting when omitted
+	Unit          quantity.Unit    `json:"unit" binding:"omitempty,oneof=each kg g l m" example:"kg"`
+	PriceRounding tax.RoundingMode `json:"price_rounding" binding:"omitempty,oneof=nearest up down" example:"nearest"`
 }
 
 // CreateProduct defines the structure for creating a new product
 // @Description Create product request model
 type CreateProduct struct {
-	Name        string  `json:"name" binding:"required" example:"Wireless Mouse"`
-	Price       float64 `json:"price" binding:"required,gt=0" example:"250000"`
-	IsAvailable bool    `json:"is_available" example:"true"`
-	CategoryID  int     `json:"category_id" binding:"required" example:"1"` // Changed from string to int
+	Name        string      `json:"name" binding:"required" example:"Wireless Mouse"`
+	Price       money.Money `json:"price" binding:"money,required,gt=0"`
+	IsAvailable bool        `json:"is_available" example:"true"`
+	CategoryID  int         `json:"category_id" binding:"required" example:"1"` // Changed from string to int
+	// TaxClass is standard or exempt, defaulting to standard
+	TaxClass tax.Class `json:"tax_class" binding:"omitempty,oneof=standard exempt" example:"standard"`
+	// SKU is the optional store product code
+	SKU string `json:"sku" binding:"max=64" example:"LAP-PRO-13"`
+	// TrackStock counts the product's stock on hand, starting from zero;
+	// record a receiving movement to stock it
+	TrackStock bool `json:"track_stock" example:"true"`
+	// Unit is each, kg, g, l or m, defaulting to each. Price is per unit and
+	// kg, l and m are sold in quantities of up to three decimals.
+	Unit quantity.Unit `json:"unit" binding:"omitempty,oneof=each kg g l m" example:"kg"`
+	// PriceRounding is nearest, up or down, defaulting to nearest
+	PriceRounding tax.RoundingMode `json:"price_rounding" binding:"omitempty,oneof=nearest up down" example:"nearest"`
+}
+
+// skuOrNil returns the trimmed SKU, or nil when none was given
+func skuOrNil(sku string) *string {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil
+	}
+	return &sku
+}
+
+// taxClassOrDefault returns the requested tax class, or standard when none was given
+func taxClassOrDefault(class tax.Class) tax.Class {
+	if class == "" {
+		return tax.ClassStandard
+	}
+	return class
+}
+
+// unitOrDefault returns the requested unit, or each when none was given
+func unitOrDefault(unit quantity.Unit) quantity.Unit {
+	if unit == "" {
+		return quantity.Each
+	}
+	return unit
+}
+
+// roundingOrDefault returns the requested price rounding, or nearest when
+// none was given
+func roundingOrDefault(mode tax.RoundingMode) tax.RoundingMode {
+	if mode == "" {
+		return tax.RoundNearest
+	}
+	return mode
+}
+
+// ExportedProduct is one row of the product export
+type ExportedProduct struct {
+	Product
+	Category string
+}
+
+// ImportStatus is the outcome of one row of a product import
+type ImportStatus string
+
+const (
+	ImportCreated ImportStatus = "created"
+	ImportUpdated ImportStatus = "updated"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// ImportRow is a validated row of a product import. CategoryID is set by the
+// import once the category is found or created.
+type ImportRow struct {
+	Line        int
+	Name        string
+	Price       money.Money
+	Category    string
+	CategoryID  int
+	IsAvailable bool
+	SKU         *string
+	TaxClass    tax.Class
+	Unit        quantity.Unit
+}
+
+// ImportRowResult reports what happened to one row of the file. Line is the
+// row's line number in the file, counting the header as line 1.
+type ImportRowResult struct {
+	Line            int          `json:"line" example:"2"`
+	Status          ImportStatus `json:"status" example:"created"`
+	ProductID       *int         `json:"product_id,omitempty" example:"42"`
+	Name            string       `json:"name" example:"Cafe latte"`
+	SKU             *string      `json:"sku,omitempty" example:"COF-LAT"`
+	Category        string       `json:"category" example:"Coffee"`
+	CategoryCreated bool         `json:"category_created" example:"false"`
+	Errors          []string     `json:"errors,omitempty"`
+}
+
+// ImportReport summarises a product import. In a dry run nothing is saved and
+// the rows show what an import of the same file would do.
+// @Description Product import report model
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run" example:"false"`
+	Created int               `json:"created" example:"780"`
+	Updated int               `json:"updated" example:"12"`
+	Failed  int               `json:"failed" example:"8"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// CreateStockMovement records stock received, wasted or corrected by hand.
+// Receiving and waste take the amount in the product's unit, such as 12.5
+// for kilograms; an adjustment takes the signed correction, such as -2 after
+// a count found two units missing.
+// @Description Manual stock movement request model
+type CreateStockMovement struct {
+	// VariantID moves the stock of one of the product's variants
+	VariantID *int               `json:"variant_id" binding:"omitempty,gt=0" example:"3"`
+	Type      stock.MovementType `json:"type" binding:"required,oneof=adjustment receiving waste" example:"receiving"`
+	Quantity  quantity.Quantity  `json:"quantity" binding:"required" example:"24"`
+	Reason    string             `json:"reason" binding:"max=255" example:"Weekly delivery"`
+}
+
+// StockMovementPage is one page of a product's stock ledger, newest first.
+// NextCursor is empty on the last page.
+type StockMovementPage struct {
+	Movements  []stock.Movement
+	NextCursor string
+}
+
+// Variant is a sellable version of a product, such as "Large Hot", with its
+// own SKU, price and stock. Products with variants are sold by variant.
+// @Description Product variant model
+type Variant struct {
+	ID          int               `json:"id" example:"3"`
+	ProductID   int               `json:"product_id" example:"1"`
+	Name        string            `json:"name" example:"Large Hot"`
+	SKU         *string           `json:"sku" example:"LAT-L-HOT"`
+	Price       money.Money       `json:"price"`
+	IsAvailable bool              `json:"is_available" example:"true"`
+	TrackStock  bool              `json:"track_stock" example:"false"`
+	StockOnHand quantity.Quantity `json:"stock_on_hand" example:"0"`
+	Position    int               `json:"position" example:"0"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// SaveVariant defines the structure for creating or updating a variant.
+// IsAvailable defaults to true and an omitted TrackStock keeps the current
+// setting of an existing variant.
+// @Description Variant request model
+type SaveVariant struct {
+	Name        string      `json:"name" binding:"required,max=100" example:"Large Hot"`
+	SKU         string      `json:"sku" binding:"max=64" example:"LAT-L-HOT"`
+	Price       money.Money `json:"price" binding:"money,required,gt=0"`
+	IsAvailable *bool       `json:"is_available" example:"true"`
+	TrackStock  *bool       `json:"track_stock" example:"false"`
+	Position    int         `json:"position" example:"0"`
+}
+
+// SaveOption is one option of an option group. IsAvailable defaults to true.
+type SaveOption struct {
+	Name        string      `json:"name" binding:"required,max=100" example:"Large"`
+	PriceDelta  money.Money `json:"price_delta" binding:"money"`
+	IsAvailable *bool       `json:"is_available" example:"true"`
+}
+
+// SaveOptionGroup is an option group and its options in display order.
+// MaxSelect zero lets every option be chosen.
+type SaveOptionGroup struct {
+	Name      string       `json:"name" binding:"required,max=100" example:"Size"`
+	Required  bool         `json:"required" example:"true"`
+	MinSelect int          `json:"min_select" binding:"min=0" example:"1"`
+	MaxSelect int          `json:"max_select" binding:"min=0" example:"1"`
+	Options   []SaveOption `json:"options" binding:"required,min=1,dive"`
+}
+
+// SaveOptionGroups replaces every option group of a product. An empty list
+// removes them all.
+// @Description Option groups request model
+type SaveOptionGroups struct {
+	Groups []SaveOptionGroup `json:"groups" binding:"omitempty,max=20,dive"`
+}
+
+// optionGroups converts the request into validated option groups
+func (r *SaveOptionGroups) optionGroups() ([]option.Group, error) {
+	groups := make([]option.Group, 0, len(r.Groups))
+	names := make(map[string]bool, len(r.Groups))
+	for _, request := range r.Groups {
+		group := option.Group{
+			Name:      strings.TrimSpace(request.Name),
+			Required:  request.Required,
+			MinSelect: request.MinSelect,
+			MaxSelect: request.MaxSelect,
+		}
+		for _, o := range request.Options {
+			group.Options = append(group.Options, option.Option{
+				Name:        strings.TrimSpace(o.Name),
+				PriceDelta:  o.PriceDelta,
+				IsAvailable: o.IsAvailable == nil || *o.IsAvailable,
+			})
+		}
+		if err := group.Validate(); err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(group.Name)
+		if names[key] {
+			return nil, fmt.Errorf("more than one option group is named %s", group.Name)
+		}
+		names[key] = true
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// Barcode is a scannable code of a product, or of one of its variants when
+// VariantID is set. UPC-A codes are kept in their 13-digit EAN form.
+// @Description Product barcode model
+type Barcode struct {
+	ID        int          `json:"id" example:"1"`
+	Code      string       `json:"code" example:"8991002101234"`
+	Kind      barcode.Kind `json:"kind" example:"ean13"`
+	VariantID *int         `json:"variant_id" example:"3"`
+}
+
+// SaveBarcode is one code of a product or of one of its variants
+type SaveBarcode struct {
+	Code      string `json:"code" binding:"required,max=64" example:"8991002101234"`
+	VariantID *int   `json:"variant_id" binding:"omitempty,gt=0" example:"3"`
+}
+
+// SaveBarcodes replaces every barcode of a product and its variants. An
+// empty list removes them all.
+// @Description Barcodes request model
+type SaveBarcodes struct {
+	Barcodes []SaveBarcode `json:"barcodes" binding:"omitempty,max=20,dive"`
+}
+
+// barcodes validates and normalises the requested codes. EAN and UPC codes
+// must carry a valid check digit.
+func (r *SaveBarcodes) barcodes() ([]Barcode, error) {
+	barcodes := make([]Barcode, 0, len(r.Barcodes))
+	seen := make(map[string]bool, len(r.Barcodes))
+	for _, request := range r.Barcodes {
+		code, kind, err := barcode.Normalize(request.Code)
+		if err != nil {
+			return nil, err
+		}
+		if seen[code] {
+			return nil, fmt.Errorf("%s is listed more than once", request.Code)
+		}
+		seen[code] = true
+		barcodes = append(barcodes, Barcode{Code: code, Kind: kind, VariantID: request.VariantID})
+	}
+	return barcodes, nil
+}
+
+// LookupMatch is how a scanned code was found
+type LookupMatch string
+
+const (
+	MatchBarcode LookupMatch = "barcode"
+	MatchSKU     LookupMatch = "sku"
+	// MatchScale is a barcode printed by a scale, found by its item code
+	MatchScale LookupMatch = "scale"
+)
+
+// LookupResult is the product a scanned code belongs to, with the variant
+// when the code is one of a variant. Scale is set for barcodes printed by a
+// scale.
+// @Description Code lookup result model
+type LookupResult struct {
+	Product   Product       `json:"product"`
+	Variant   *Variant      `json:"variant,omitempty"`
+	MatchedBy LookupMatch   `json:"matched_by" example:"barcode"`
+	Scale     *ScaleReading `json:"scale,omitempty"`
+}
+
+// ScaleReading is what a scale barcode says about the weighed item.
+// Quantity is in the product's unit; Price is set for barcodes that embed
+// the price of the line instead of its weight.
+// @Description Scale barcode reading model
+type ScaleReading struct {
+	Kind     barcode.ScaleKind `json:"kind" example:"weight"`
+	ItemCode string            `json:"item_code" example:"12345"`
+	Quantity quantity.Quantity `json:"quantity" example:"0.35"`
+	Price    *money.Money      `json:"price,omitempty"`
+}
+
+// readScale sets the quantity and price of a scale barcode found by its
+// item code
+func (r *LookupResult) readScale(scale barcode.Scale) error {
+	price := r.Product.Price
+	if r.Variant != nil {
+		price = r.Variant.Price
+	}
+	amount, err := scale.Quantity(r.Product.Unit, price.Amount)
+	if err != nil {
+		return fmt.Errorf("cannot read the scale barcode of %s: %w", r.Product.Name, err)
+	}
+
+	r.MatchedBy = MatchScale
+	r.Scale = &ScaleReading{Kind: scale.Kind, ItemCode: scale.ItemCode, Quantity: amount}
+	if scale.Kind == barcode.ScalePrice {
+		embedded := money.New(scale.Value, price.Currency)
+		r.Scale.Price = &embedded
+	}
+	return nil
+}
+
+// LabelEncode is what the barcode of a label encodes
+type LabelEncode string
+
+const (
+	// EncodeCode prints the product's or variant's first barcode, else its
+	// SKU, else its ID
+	EncodeCode LabelEncode = "code"
+	// EncodeID always prints the product ID
+	EncodeID LabelEncode = "id"
+)
+
+// LabelItem is a product, or one of its variants, to print labels for.
+// Copies defaults to 1.
+type LabelItem struct {
+	ProductID int  `json:"product_id" binding:"required,gt=0" example:"1"`
+	VariantID *int `json:"variant_id" binding:"omitempty,gt=0" example:"3"`
+	Copies    int  `json:"copies" binding:"omitempty,min=1,max=100" example:"2"`
+}
+
+// LabelRequest selects the products to print labels for and how to lay them
+// out. Labels default to 50x30 mm at 203 dpi; PDF sheets fit as many columns
+// and rows on an A4 page as the label size allows unless they are given.
+// @Description Label request model
+type LabelRequest struct {
+	Items  []LabelItem  `json:"items" binding:"required,min=1,max=200,dive"`
+	Format label.Format `json:"format" binding:"required,oneof=png svg pdf zpl" example:"pdf"`
+	Encode LabelEncode  `json:"encode" binding:"omitempty,oneof=code id" example:"code"`
+	// WidthMM and HeightMM are the size of one label in millimetres
+	WidthMM  float64 `json:"width_mm" binding:"omitempty,min=20,max=150" example:"50"`
+	HeightMM float64 `json:"height_mm" binding:"omitempty,min=15,max=100" example:"30"`
+	// Columns, Rows and GapMM lay out PDF sheets
+	Columns int     `json:"columns" binding:"omitempty,min=1,max=10" example:"3"`
+	Rows    int     `json:"rows" binding:"omitempty,min=1,max=20" example:"9"`
+	GapMM   float64 `json:"gap_mm" binding:"omitempty,min=0,max=20" example:"2"`
+	// DPI is the resolution of PNG images and ZPL printers (203 or 300)
+	DPI int `json:"dpi" binding:"omitempty,min=72,max=600" example:"203"`
+}
+
+// layout applies the defaults of the request and checks that its size and
+// grid can be printed in the requested format
+func (r *LabelRequest) layout() (label.Size, label.Grid, error) {
+	if r.Encode == "" {
+		r.Encode = EncodeCode
+	}
+	if r.DPI == 0 {
+		r.DPI = 203
+	}
+	size := label.Size{Width: r.WidthMM, Height: r.HeightMM}
+	if size.Width == 0 {
+		size.Width = 50
+	}
+	if size.Height == 0 {
+		size.Height = 30
+	}
+
+	grid := label.FitGrid(size, r.GapMM)
+	if r.Columns != 0 {
+		grid.Columns = r.Columns
+	}
+	if r.Rows != 0 {
+		grid.Rows = r.Rows
+	}
+
+	switch r.Format {
+	case label.FormatPNG, label.FormatSVG:
+		if len(r.Items) != 1 || r.Items[0].Copies > 1 {
+			return size, grid, fmt.Errorf("%s renders a single label; use pdf or zpl for several", r.Format)
+		}
+	case label.FormatPDF:
+		if err := grid.Validate(size); err != nil {
+			return size, grid, err
+		}
+	case label.FormatZPL:
+		if r.DPI != 203 && r.DPI != 300 {
+			return size, grid, errors.New("zpl printers print at 203 or 300 dpi")
+		}
+	}
+	return size, grid, nil
 }
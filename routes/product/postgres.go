@@ -4,20 +4,33 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/barcode"
 	"github.com/yantology/simple-pos/pkg/customerror" // Import customerror
+	"github.com/yantology/simple-pos/pkg/label"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/pkg/stock"
+	"github.com/yantology/simple-pos/routes/category"
 )
 
 type PostgresRepository struct {
 	DB *sql.DB
+	// Categories creates the categories named in product imports
+	Categories category.Repository
 }
 
 // NewPostgresRepository creates a new PostgresRepository instance
-func NewPostgresRepository(db *sql.DB) Repository {
+func NewPostgresRepository(db *sql.DB, categories category.Repository) Repository {
 	fmt.Println("NewPostgresRepository: Initializing product repository") // Add log
 	return &PostgresRepository{
-		DB: db,
+		DB:         db,
+		Categories: categories,
 	}
 }
 
@@ -38,10 +51,16 @@ func (r *PostgresRepository) Create(productData *CreateProduct, userID int) (*Pr
 		return nil, customerror.NewCustomError(nil, "UserID is required to create a product", http.StatusBadRequest)
 	}
 
+	if sku := skuOrNil(productData.SKU); sku != nil {
+		if customErr := codeInUse(r.DB, userID, *sku, 0, nil); customErr != nil {
+			return nil, customErr
+		}
+	}
+
 	query := `
-		INSERT INTO products (name, price, is_available, category_id, user_id)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, name, price, is_available, category_id, user_id, created_at, updated_at
+		INSERT INTO products (name, price, is_available, category_id, tax_class, sku, track_stock, unit, price_rounding, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, name, price, is_available, category_id, tax_class, sku, track_stock, stock_on_hand, unit, price_rounding, user_id, created_at, updated_at
 	`
 
 	var product Product
@@ -52,13 +71,24 @@ func (r *PostgresRepository) Create(productData *CreateProduct, userID int) (*Pr
 		productData.Price,       // Use productData
 		productData.IsAvailable, // Use productData
 		productData.CategoryID,  // Use productData
-		userID,                  // Use UserID (int) from the parameter
+		taxClassOrDefault(productData.TaxClass),
+		skuOrNil(productData.SKU),
+		productData.TrackStock,
+		unitOrDefault(productData.Unit),
+		roundingOrDefault(productData.PriceRounding),
+		userID, // Use UserID (int) from the parameter
 	).Scan(
 		&product.ID,
 		&product.Name,
 		&product.Price,
 		&product.IsAvailable,
 		&product.CategoryID,
+		&product.TaxClass,
+		&product.SKU,
+		&product.TrackStock,
+		&product.StockOnHand,
+		&product.Unit,
+		&product.PriceRounding,
 		&product.UserID,
 		&product.CreatedAt,
 		&product.UpdatedAt,
@@ -78,7 +108,7 @@ func (r *PostgresRepository) Create(productData *CreateProduct, userID int) (*Pr
 func (r *PostgresRepository) GetAll() ([]*Product, *customerror.CustomError) { // Return *customerror.CustomError
 	fmt.Println("Repository.GetAll: Fetching all products") // Add log
 	query := `
-		SELECT id, name, price, is_available, category_id, user_id, created_at, updated_at
+		SELECT id, name, price, is_available, category_id, tax_class, sku, track_stock, stock_on_hand, unit, price_rounding, user_id, created_at, updated_at
 		FROM products
 		ORDER BY id
 	`
@@ -103,6 +133,12 @@ func (r *PostgresRepository) GetAll() ([]*Product, *customerror.CustomError) { /
 			&product.Price,
 			&product.IsAvailable,
 			&product.CategoryID,
+			&product.TaxClass,
+			&product.SKU,
+			&product.TrackStock,
+			&product.StockOnHand,
+			&product.Unit,
+			&product.PriceRounding,
 			&product.UserID,
 			&product.CreatedAt,
 			&product.UpdatedAt,
@@ -136,11 +172,19 @@ func (r *PostgresRepository) Update(id int, userID int, productUpdate *UpdatePro
 	// productID, err := strconv.Atoi(id)
 	// ... removed conversion ...
 
+	if sku := skuOrNil(productUpdate.SKU); sku != nil {
+		if customErr := codeInUse(r.DB, userID, *sku, id, nil); customErr != nil {
+			return nil, customErr
+		}
+	}
+
 	query := `
 		UPDATE products
-		SET name = $1, price = $2, is_available = $3, category_id = $4, updated_at = $5
-		WHERE id = $6 AND user_id = $7 -- Check both id and user_id
-		RETURNING id, name, price, is_available, category_id, user_id, created_at, updated_at
+		SET name = $1, price = $2, is_available = $3, category_id = $4, tax_class = $5, sku = $6,
+			track_stock = COALESCE($7, track_stock), unit = COALESCE(NULLIF($8, ''), unit),
+			price_rounding = COALESCE(NULLIF($9, ''), price_rounding), updated_at = $10
+		WHERE id = $11 AND user_id = $12 -- Check both id and user_id
+		RETURNING id, name, price, is_available, category_id, tax_class, sku, track_stock, stock_on_hand, unit, price_rounding, user_id, created_at, updated_at
 	`
 
 	var updatedProduct Product
@@ -151,6 +195,11 @@ func (r *PostgresRepository) Update(id int, userID int, productUpdate *UpdatePro
 		productUpdate.Price,       // Use productUpdate
 		productUpdate.IsAvailable, // Use productUpdate
 		productUpdate.CategoryID,  // Use productUpdate
+		taxClassOrDefault(productUpdate.TaxClass),
+		skuOrNil(productUpdate.SKU),
+		productUpdate.TrackStock,
+		productUpdate.Unit,
+		productUpdate.PriceRounding,
 		time.Now(),
 		id,     // Use id (int) directly
 		userID, // Use userID (int) directly
@@ -160,6 +209,12 @@ func (r *PostgresRepository) Update(id int, userID int, productUpdate *UpdatePro
 		&updatedProduct.Price,
 		&updatedProduct.IsAvailable,
 		&updatedProduct.CategoryID,
+		&updatedProduct.TaxClass,
+		&updatedProduct.SKU,
+		&updatedProduct.TrackStock,
+		&updatedProduct.StockOnHand,
+		&updatedProduct.Unit,
+		&updatedProduct.PriceRounding,
 		&updatedProduct.UserID,
 		&updatedProduct.CreatedAt,
 		&updatedProduct.UpdatedAt,
@@ -216,7 +271,7 @@ func (r *PostgresRepository) Delete(id int, userID int) *customerror.CustomError
 
 // GetByCategoryID retrieves all products belonging to a specific category ID
 func (r *PostgresRepository) GetByCategoryID(categoryID int) ([]*Product, *customerror.CustomError) {
-	query := `SELECT id, name, description, price, stock, category_id, user_id, created_at, updated_at FROM products WHERE category_id = $1 ORDER BY name`
+	query := `SELECT id, name, price, is_available, category_id, tax_class, sku, track_stock, stock_on_hand, unit, price_rounding, user_id, created_at, updated_at FROM products WHERE category_id = $1 ORDER BY name`
 	rows, err := r.DB.Query(query, categoryID)
 	if err != nil {
 		fmt.Printf("Repository.GetByCategoryID: Database query error: %v\n", err) // Add log
@@ -236,6 +291,12 @@ func (r *PostgresRepository) GetByCategoryID(categoryID int) ([]*Product, *custo
 			&product.Price,
 			&product.IsAvailable,
 			&product.CategoryID,
+			&product.TaxClass,
+			&product.SKU,
+			&product.TrackStock,
+			&product.StockOnHand,
+			&product.Unit,
+			&product.PriceRounding,
 			&product.UserID,
 			&product.CreatedAt,
 			&product.UpdatedAt,
@@ -257,3 +318,946 @@ func (r *PostgresRepository) GetByCategoryID(categoryID int) ([]*Product, *custo
 	fmt.Printf("Repository.GetByCategoryID: Successfully fetched %d products for category ID %d\n", len(products), categoryID) // Add log
 	return products, nil
 }
+
+// Export streams the user's products with their category names to write,
+// ordered by category and name
+func (r *PostgresRepository) Export(userID int, categoryID *int, write func(*ExportedProduct) error) *customerror.CustomError {
+	fmt.Printf("Repository.Export: Exporting products for user %d\n", userID) // Add log
+	query := `
+		SELECT p.id, p.name, p.price, p.is_available, p.category_id, p.tax_class, p.sku, p.track_stock, p.stock_on_hand, p.unit, p.price_rounding, p.user_id, p.created_at, p.updated_at, c.name
+		FROM products p
+		JOIN categories c ON c.id = p.category_id
+		WHERE p.user_id = $1 AND ($2::int IS NULL OR p.category_id = $2)
+		ORDER BY c.name, p.name, p.id
+	`
+	rows, err := r.DB.Query(query, userID, categoryID)
+	if err != nil {
+		fmt.Printf("Repository.Export: Database query error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var product ExportedProduct
+		if err := rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.Price,
+			&product.IsAvailable,
+			&product.CategoryID,
+			&product.TaxClass,
+			&product.SKU,
+			&product.TrackStock,
+			&product.StockOnHand,
+			&product.Unit,
+			&product.PriceRounding,
+			&product.UserID,
+			&product.CreatedAt,
+			&product.UpdatedAt,
+			&product.Category,
+		); err != nil {
+			fmt.Printf("Repository.Export: Error scanning row: %v\n", err) // Add log
+			return customerror.NewPostgresError(err)
+		}
+		if err := write(&product); err != nil {
+			fmt.Printf("Repository.Export: Error writing row: %v\n", err) // Add log
+			return customerror.NewCustomError(err, "Failed to write export", http.StatusInternalServerError)
+		}
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.Export: Error iterating rows: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.Export: Exported %d products for user %d\n", count, userID) // Add log
+	return nil
+}
+
+// findImportMatch returns the ID of the product an import row updates, or nil
+// when the row creates a product. Rows match by SKU first. A row with a SKU
+// no product has only matches a product without a SKU by name, so it never
+// replaces another SKU; a row without a SKU matches any product by name.
+func findImportMatch(tx *sql.Tx, userID int, row *ImportRow) (*int, error) {
+	var id int
+	if row.SKU != nil {
+		err := tx.QueryRow(`SELECT id FROM products WHERE user_id = $1 AND sku = $2`, userID, *row.SKU).Scan(&id)
+		if err == nil {
+			return &id, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	rows, err := tx.Query(`SELECT id FROM products WHERE user_id = $1 AND LOWER(name) = LOWER($2) AND ($3 OR sku IS NULL) ORDER BY id LIMIT 2`, userID, row.Name, row.SKU == nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	switch len(ids) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &ids[0], nil
+	default:
+		return nil, fmt.Errorf("several products are named %q; give the row a SKU", row.Name)
+	}
+}
+
+// importCategoryIDs maps the lower-cased names of the user's categories to
+// their IDs
+func importCategoryIDs(tx *sql.Tx, userID int) (map[string]int, *customerror.CustomError) {
+	rows, err := tx.Query(`SELECT id, name FROM categories WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		fmt.Printf("Repository.Import: Error loading categories: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	ids := map[string]int{}
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		if _, ok := ids[strings.ToLower(name)]; !ok {
+			ids[strings.ToLower(name)] = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return ids, nil
+}
+
+// importRow creates or updates the product of one import row
+func importRow(tx *sql.Tx, userID int, row *ImportRow, match *int) (int, error) {
+	var id int
+	if row.SKU != nil {
+		self := 0
+		if match != nil {
+			self = *match
+		}
+		if customErr := codeInUse(tx, userID, *row.SKU, self, nil); customErr != nil {
+			return 0, customErr
+		}
+	}
+	if match != nil {
+		// An import without a tax class or unit keeps the product's current one
+		err := tx.QueryRow(`
+			UPDATE products
+			SET name = $1, price = $2, is_available = $3, category_id = $4, tax_class = COALESCE(NULLIF($5, ''), tax_class),
+				sku = COALESCE($6, sku), unit = COALESCE(NULLIF($7, ''), unit), updated_at = $8
+			WHERE id = $9 AND user_id = $10
+			RETURNING id
+		`, row.Name, row.Price, row.IsAvailable, row.CategoryID, string(row.TaxClass), row.SKU, string(row.Unit), time.Now(), *match, userID).Scan(&id)
+		return id, err
+	}
+
+	err := tx.QueryRow(`
+		INSERT INTO products (name, price, is_available, category_id, tax_class, sku, unit, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, row.Name, row.Price, row.IsAvailable, row.CategoryID, taxClassOrDefault(row.TaxClass), row.SKU, unitOrDefault(row.Unit), userID).Scan(&id)
+	return id, err
+}
+
+// importCategory returns the ID of the category an import row names and
+// whether the row creates it. Missing categories are created in the row's
+// savepoint, so a category whose rows all fail is rolled back with them.
+func (r *PostgresRepository) importCategory(tx *sql.Tx, userID int, name string, ids map[string]int) (int, bool, error) {
+	if id, ok := ids[strings.ToLower(name)]; ok {
+		return id, false, nil
+	}
+	newCategory, customErr := r.Categories.CreateCategoryTx(tx, &category.CreateCategory{Name: name}, userID)
+	if customErr != nil {
+		return 0, false, fmt.Errorf("category %q could not be created: %s", name, customErr.Message())
+	}
+	return newCategory.ID, true, nil
+}
+
+// Import creates or updates a product for every import row in one
+// transaction, creating the categories that do not exist yet. A failing row
+// is rolled back on its own and reported, without affecting the other rows.
+// A dry run imports every row as well but rolls each one back, so the report
+// shows what the import would do without saving anything.
+func (r *PostgresRepository) Import(userID int, rows []ImportRow, dryRun bool) ([]ImportRowResult, *customerror.CustomError) {
+	fmt.Printf("Repository.Import: Importing %d rows for user %d (dry run: %t)\n", len(rows), userID, dryRun) // Add log
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	categoryIDs, customErr := importCategoryIDs(tx, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	// Lower-cased names of the categories created by rows that were imported
+	created := map[string]bool{}
+
+	results := make([]ImportRowResult, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		result := ImportRowResult{Line: row.Line, Name: row.Name, SKU: row.SKU, Category: row.Category}
+		fail := func(err error) {
+			result.Status = ImportFailed
+			message := err.Error()
+			if customErr := customerror.NewPostgresError(err); customErr.Code() != http.StatusInternalServerError {
+				message = customErr.Message()
+			}
+			result.Errors = append(result.Errors, message)
+			fmt.Printf("Repository.Import: Row %d failed: %v\n", row.Line, err) // Add log
+		}
+
+		if _, err := tx.Exec(`SAVEPOINT import_row`); err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		match, err := findImportMatch(tx, userID, row)
+		newCategory := false
+		if err == nil {
+			row.CategoryID, newCategory, err = r.importCategory(tx, userID, row.Category, categoryIDs)
+		}
+		var id int
+		if err == nil {
+			id, err = importRow(tx, userID, row, match)
+		}
+		switch {
+		case err != nil:
+			fail(err)
+		case match != nil:
+			result.Status, result.ProductID = ImportUpdated, match
+		default:
+			result.Status = ImportCreated
+			// A product created in a dry run is rolled back and has no ID
+			if !dryRun {
+				result.ProductID = &id
+			}
+		}
+
+		// A dry run rolls back every row, including the categories it created
+		if result.Status == ImportFailed || dryRun {
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT import_row`); err != nil {
+				return nil, customerror.NewPostgresError(err)
+			}
+		} else {
+			if _, err := tx.Exec(`RELEASE SAVEPOINT import_row`); err != nil {
+				return nil, customerror.NewPostgresError(err)
+			}
+			if newCategory {
+				categoryIDs[strings.ToLower(row.Category)] = row.CategoryID
+			}
+		}
+		if newCategory && result.Status != ImportFailed {
+			created[strings.ToLower(row.Category)] = true
+		}
+		results = append(results, result)
+	}
+
+	for i := range results {
+		results[i].CategoryCreated = results[i].Status != ImportFailed && created[strings.ToLower(results[i].Category)]
+	}
+
+	if !dryRun {
+		if err := tx.Commit(); err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+	}
+
+	fmt.Printf("Repository.Import: Finished import of %d rows for user %d\n", len(rows), userID) // Add log
+	return results, nil
+}
+
+// GetStockMovements retrieves one page of a product's stock ledger, newest
+// first, optionally only of one variant. Pages after the first start below
+// the cursor's movement ID.
+func (r *PostgresRepository) GetStockMovements(id int, userID int, variantID *int, cursor int, limit int) (*StockMovementPage, *customerror.CustomError) {
+	fmt.Printf("Repository.GetStockMovements: Fetching stock movements of product %d for user %d\n", id, userID) // Add log
+	if customErr := findProduct(r.DB, id, userID, false); customErr != nil {
+		return nil, customErr
+	}
+
+	query := `
+		SELECT id, product_id, variant_id, type, quantity, balance_after, order_id, refund_id, reason, created_by, created_at
+		FROM stock_movements
+		WHERE product_id = $1 AND user_id = $2 AND ($3::int IS NULL OR variant_id = $3) AND ($4 = 0 OR id < $4)
+		ORDER BY id DESC
+		LIMIT $5
+	`
+	// One extra row tells whether another page follows
+	rows, err := r.DB.Query(query, id, userID, variantID, cursor, limit+1)
+	if err != nil {
+		fmt.Printf("Repository.GetStockMovements: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	page := &StockMovementPage{Movements: []stock.Movement{}}
+	for rows.Next() {
+		var movement stock.Movement
+		if err := rows.Scan(
+			&movement.ID,
+			&movement.ProductID,
+			&movement.VariantID,
+			&movement.Type,
+			&movement.Quantity,
+			&movement.BalanceAfter,
+			&movement.OrderID,
+			&movement.RefundID,
+			&movement.Reason,
+			&movement.CreatedBy,
+			&movement.CreatedAt,
+		); err != nil {
+			fmt.Printf("Repository.GetStockMovements: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		page.Movements = append(page.Movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.GetStockMovements: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	if len(page.Movements) > limit {
+		page.Movements = page.Movements[:limit]
+		page.NextCursor = strconv.Itoa(page.Movements[limit-1].ID)
+	}
+	return page, nil
+}
+
+// RecordStockMovement applies stock received, wasted or corrected by hand to
+// a product that tracks stock. Waste and downward adjustments follow the
+// store's negative stock setting.
+func (r *PostgresRepository) RecordStockMovement(id int, userID int, request *CreateStockMovement) (*stock.Movement, *customerror.CustomError) {
+	fmt.Printf("Repository.RecordStockMovement: Recording %s of %s for product %d by user %d\n", request.Type, request.Quantity, id, userID) // Add log
+	change, err := stock.ManualChange(request.Type, request.Quantity)
+	if err != nil {
+		return nil, customerror.NewCustomError(err, err.Error(), http.StatusBadRequest)
+	}
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		fmt.Printf("Repository.RecordStockMovement: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	if customErr := findProduct(tx, id, userID, false); customErr != nil {
+		return nil, customErr
+	}
+	var trackStock bool
+	var unit quantity.Unit
+	if request.VariantID != nil {
+		err = tx.QueryRow(`
+			SELECT v.track_stock, p.unit FROM product_variants v JOIN products p ON p.id = v.product_id
+			WHERE v.id = $1 AND v.product_id = $2
+		`, *request.VariantID, id).Scan(&trackStock, &unit)
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("variant with id %d not found for product %d", *request.VariantID, id), http.StatusNotFound)
+		}
+	} else {
+		err = tx.QueryRow(`SELECT track_stock, unit FROM products WHERE id = $1`, id).Scan(&trackStock, &unit)
+	}
+	if err != nil {
+		fmt.Printf("Repository.RecordStockMovement: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	if err := unit.Check(request.Quantity); err != nil {
+		return nil, customerror.NewCustomError(err, "Invalid quantity: "+err.Error(), http.StatusBadRequest)
+	}
+	if !trackStock {
+		if request.VariantID != nil {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("variant with id %d does not track stock", *request.VariantID), http.StatusConflict)
+		}
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("product with id %d does not track stock", id), http.StatusConflict)
+	}
+
+	allowNegative, customErr := stock.AllowNegative(tx, userID)
+	if customErr != nil {
+		return nil, customErr
+	}
+	recorded, customErr := stock.Record(tx, userID, []stock.Movement{{
+		ProductID: id,
+		VariantID: request.VariantID,
+		Type:      request.Type,
+		Quantity:  change,
+		Reason:    strings.TrimSpace(request.Reason),
+		CreatedBy: userID,
+	}}, allowNegative)
+	if customErr != nil {
+		fmt.Printf("Repository.RecordStockMovement: Movement not recorded: %s\n", customErr.Message()) // Add log
+		return nil, customErr
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.RecordStockMovement: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.RecordStockMovement: Product %d now has %s in stock\n", id, recorded[0].BalanceAfter) // Add log
+	return &recorded[0], nil
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// findProduct returns a not found error unless the user owns the product.
+// With lock set the product row stays locked until the transaction ends.
+func findProduct(db queryRower, id int, userID int, lock bool) *customerror.CustomError {
+	query := `SELECT id FROM products WHERE id = $1 AND user_id = $2`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+	var found int
+	if err := db.QueryRow(query, id, userID).Scan(&found); err != nil {
+		if err == sql.ErrNoRows {
+			return customerror.NewCustomError(nil, fmt.Sprintf("product with id %d not found or user not authorized", id), http.StatusNotFound)
+		}
+		fmt.Printf("Repository.findProduct: Database error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// GetOptionGroups retrieves the option groups of a product with their
+// options, in display order
+func (r *PostgresRepository) GetOptionGroups(id int, userID int) ([]option.Group, *customerror.CustomError) {
+	fmt.Printf("Repository.GetOptionGroups: Fetching option groups of product %d for user %d\n", id, userID) // Add log
+	if customErr := findProduct(r.DB, id, userID, false); customErr != nil {
+		return nil, customErr
+	}
+	groups, err := option.Load(r.DB, []int64{int64(id)})
+	if err != nil {
+		fmt.Printf("Repository.GetOptionGroups: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	if groups[id] == nil {
+		return []option.Group{}, nil
+	}
+	return groups[id], nil
+}
+
+// ReplaceOptionGroups replaces every option group of a product. Orders keep
+// copies of the options they were sold with, so removed options do not
+// change past orders.
+func (r *PostgresRepository) ReplaceOptionGroups(id int, userID int, groups []option.Group) ([]option.Group, *customerror.CustomError) {
+	fmt.Printf("Repository.ReplaceOptionGroups: Saving %d option groups of product %d for user %d\n", len(groups), id, userID) // Add log
+	tx, err := r.DB.Begin()
+	if err != nil {
+		fmt.Printf("Repository.ReplaceOptionGroups: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	if customErr := findProduct(tx, id, userID, true); customErr != nil {
+		return nil, customErr
+	}
+	if _, err := tx.Exec(`DELETE FROM product_option_groups WHERE product_id = $1`, id); err != nil {
+		fmt.Printf("Repository.ReplaceOptionGroups: Error deleting option groups: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	groupQuery := `
+		INSERT INTO product_option_groups (product_id, name, required, min_select, max_select, position)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	optionQuery := `
+		INSERT INTO product_options (group_id, name, price_delta, is_available, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	for i := range groups {
+		group := &groups[i]
+		if err := tx.QueryRow(groupQuery, id, group.Name, group.Required, group.MinSelect, group.MaxSelect, i).Scan(&group.ID); err != nil {
+			fmt.Printf("Repository.ReplaceOptionGroups: Error inserting option group: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		for j := range group.Options {
+			o := &group.Options[j]
+			if err := tx.QueryRow(optionQuery, group.ID, o.Name, o.PriceDelta, o.IsAvailable, j).Scan(&o.ID); err != nil {
+				fmt.Printf("Repository.ReplaceOptionGroups: Error inserting option: %v\n", err) // Add log
+				return nil, customerror.NewPostgresError(err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.ReplaceOptionGroups: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return groups, nil
+}
+
+// variantColumns lists the product_variants columns read by scanVariant
+const variantColumns = `id, product_id, name, sku, price, is_available, track_stock, stock_on_hand, position, created_at, updated_at`
+
+// scanVariant reads a variant selected with variantColumns
+func scanVariant(row interface{ Scan(dest ...any) error }, variant *Variant) error {
+	return row.Scan(
+		&variant.ID,
+		&variant.ProductID,
+		&variant.Name,
+		&variant.SKU,
+		&variant.Price,
+		&variant.IsAvailable,
+		&variant.TrackStock,
+		&variant.StockOnHand,
+		&variant.Position,
+		&variant.CreatedAt,
+		&variant.UpdatedAt,
+	)
+}
+
+// GetVariants retrieves the variants of a product in display order
+func (r *PostgresRepository) GetVariants(id int, userID int) ([]Variant, *customerror.CustomError) {
+	fmt.Printf("Repository.GetVariants: Fetching variants of product %d for user %d\n", id, userID) // Add log
+	if customErr := findProduct(r.DB, id, userID, false); customErr != nil {
+		return nil, customErr
+	}
+
+	rows, err := r.DB.Query(`SELECT `+variantColumns+` FROM product_variants WHERE product_id = $1 ORDER BY position, id`, id)
+	if err != nil {
+		fmt.Printf("Repository.GetVariants: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	variants := []Variant{}
+	for rows.Next() {
+		var variant Variant
+		if err := scanVariant(rows, &variant); err != nil {
+			fmt.Printf("Repository.GetVariants: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		variants = append(variants, variant)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.GetVariants: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return variants, nil
+}
+
+// CreateVariant adds a variant to a product
+func (r *PostgresRepository) CreateVariant(id int, userID int, request *SaveVariant) (*Variant, *customerror.CustomError) {
+	fmt.Printf("Repository.CreateVariant: Adding variant '%s' to product %d for user %d\n", request.Name, id, userID) // Add log
+	if customErr := findProduct(r.DB, id, userID, false); customErr != nil {
+		return nil, customErr
+	}
+	if sku := skuOrNil(request.SKU); sku != nil {
+		if customErr := codeInUse(r.DB, userID, *sku, 0, nil); customErr != nil {
+			return nil, customErr
+		}
+	}
+
+	query := `
+		INSERT INTO product_variants (product_id, user_id, name, sku, price, is_available, track_stock, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING ` + variantColumns
+	var variant Variant
+	err := scanVariant(r.DB.QueryRow(query, id, userID, strings.TrimSpace(request.Name), skuOrNil(request.SKU), request.Price,
+		request.IsAvailable == nil || *request.IsAvailable, request.TrackStock != nil && *request.TrackStock, request.Position), &variant)
+	if err != nil {
+		fmt.Printf("Repository.CreateVariant: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	fmt.Printf("Repository.CreateVariant: Created variant %d\n", variant.ID) // Add log
+	return &variant, nil
+}
+
+// UpdateVariant modifies a variant of a product. Its stock on hand only
+// changes through stock movements.
+func (r *PostgresRepository) UpdateVariant(id int, variantID int, userID int, request *SaveVariant) (*Variant, *customerror.CustomError) {
+	fmt.Printf("Repository.UpdateVariant: Updating variant %d of product %d for user %d\n", variantID, id, userID) // Add log
+	if sku := skuOrNil(request.SKU); sku != nil {
+		if customErr := codeInUse(r.DB, userID, *sku, id, &variantID); customErr != nil {
+			return nil, customErr
+		}
+	}
+	query := `
+		UPDATE product_variants
+		SET name = $1, sku = $2, price = $3, is_available = COALESCE($4, is_available),
+			track_stock = COALESCE($5, track_stock), position = $6, updated_at = $7
+		WHERE id = $8 AND product_id = $9 AND user_id = $10
+		RETURNING ` + variantColumns
+	var variant Variant
+	err := scanVariant(r.DB.QueryRow(query, strings.TrimSpace(request.Name), skuOrNil(request.SKU), request.Price, request.IsAvailable,
+		request.TrackStock, request.Position, time.Now(), variantID, id, userID), &variant)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("variant with id %d not found for product %d", variantID, id), http.StatusNotFound)
+		}
+		fmt.Printf("Repository.UpdateVariant: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return &variant, nil
+}
+
+// DeleteVariant removes a variant of a product together with its stock
+// ledger. Past order lines keep the variant's name.
+func (r *PostgresRepository) DeleteVariant(id int, variantID int, userID int) *customerror.CustomError {
+	fmt.Printf("Repository.DeleteVariant: Deleting variant %d of product %d for user %d\n", variantID, id, userID) // Add log
+	result, err := r.DB.Exec(`DELETE FROM product_variants WHERE id = $1 AND product_id = $2 AND user_id = $3`, variantID, id, userID)
+	if err != nil {
+		fmt.Printf("Repository.DeleteVariant: Database exec error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return customerror.NewCustomError(err, "failed to get rows affected", http.StatusInternalServerError)
+	}
+	if rowsAffected == 0 {
+		return customerror.NewCustomError(nil, fmt.Sprintf("variant with id %d not found for product %d", variantID, id), http.StatusNotFound)
+	}
+	return nil
+}
+
+// querier is implemented by *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// codeInUse returns a conflict error when code is already the SKU or a
+// barcode of another product or variant of the user. The item being saved is
+// productID, or its variant variantID, and may reuse its own codes; a
+// productID of zero is a new item. Codes are compared as entered and in
+// their normalised barcode form.
+func codeInUse(db querier, userID int, code string, productID int, variantID *int) *customerror.CustomError {
+	code = strings.TrimSpace(code)
+	normalized, _, err := barcode.Normalize(code)
+	if err != nil {
+		normalized = code
+	}
+
+	rows, err := db.Query(`
+		SELECT product_id, variant_id FROM product_barcodes WHERE user_id = $1 AND code IN ($2, $3)
+		UNION ALL
+		SELECT id, NULL FROM products WHERE user_id = $1 AND sku IN ($2, $3)
+		UNION ALL
+		SELECT product_id, id FROM product_variants WHERE user_id = $1 AND sku IN ($2, $3)
+	`, userID, code, normalized)
+	if err != nil {
+		fmt.Printf("Repository.codeInUse: Database query error: %v\n", err) // Add log
+		return customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ownerID int
+		var ownerVariantID sql.NullInt64
+		if err := rows.Scan(&ownerID, &ownerVariantID); err != nil {
+			return customerror.NewPostgresError(err)
+		}
+		self := productID != 0 && ownerID == productID && ownerVariantID.Valid == (variantID != nil)
+		if self && variantID != nil {
+			self = int(ownerVariantID.Int64) == *variantID
+		}
+		if !self {
+			return customerror.NewCustomError(nil, fmt.Sprintf("Code %s is already used by another product", code), http.StatusConflict)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return customerror.NewPostgresError(err)
+	}
+	return nil
+}
+
+// GetBarcodes retrieves the barcodes of a product and its variants
+func (r *PostgresRepository) GetBarcodes(id int, userID int) ([]Barcode, *customerror.CustomError) {
+	fmt.Printf("Repository.GetBarcodes: Fetching barcodes of product %d for user %d\n", id, userID) // Add log
+	if customErr := findProduct(r.DB, id, userID, false); customErr != nil {
+		return nil, customErr
+	}
+
+	rows, err := r.DB.Query(`SELECT id, code, kind, variant_id FROM product_barcodes WHERE product_id = $1 ORDER BY id`, id)
+	if err != nil {
+		fmt.Printf("Repository.GetBarcodes: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	barcodes := []Barcode{}
+	for rows.Next() {
+		var code Barcode
+		if err := rows.Scan(&code.ID, &code.Code, &code.Kind, &code.VariantID); err != nil {
+			fmt.Printf("Repository.GetBarcodes: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		barcodes = append(barcodes, code)
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.GetBarcodes: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return barcodes, nil
+}
+
+// ReplaceBarcodes replaces every barcode of a product and its variants. A
+// code already used by another product or variant of the user is rejected.
+func (r *PostgresRepository) ReplaceBarcodes(id int, userID int, barcodes []Barcode) ([]Barcode, *customerror.CustomError) {
+	fmt.Printf("Repository.ReplaceBarcodes: Saving %d barcodes of product %d for user %d\n", len(barcodes), id, userID) // Add log
+	tx, err := r.DB.Begin()
+	if err != nil {
+		fmt.Printf("Repository.ReplaceBarcodes: Error starting transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	if customErr := findProduct(tx, id, userID, true); customErr != nil {
+		return nil, customErr
+	}
+	if _, err := tx.Exec(`DELETE FROM product_barcodes WHERE product_id = $1`, id); err != nil {
+		fmt.Printf("Repository.ReplaceBarcodes: Error deleting barcodes: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	for i := range barcodes {
+		code := &barcodes[i]
+		if code.VariantID != nil {
+			var found int
+			err := tx.QueryRow(`SELECT id FROM product_variants WHERE id = $1 AND product_id = $2`, *code.VariantID, id).Scan(&found)
+			if err == sql.ErrNoRows {
+				return nil, customerror.NewCustomError(nil, fmt.Sprintf("variant with id %d not found for product %d", *code.VariantID, id), http.StatusNotFound)
+			}
+			if err != nil {
+				return nil, customerror.NewPostgresError(err)
+			}
+		}
+		if customErr := codeInUse(tx, userID, code.Code, id, code.VariantID); customErr != nil {
+			return nil, customErr
+		}
+		err := tx.QueryRow(`
+			INSERT INTO product_barcodes (user_id, product_id, variant_id, code, kind)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`, userID, id, code.VariantID, code.Code, code.Kind).Scan(&code.ID)
+		if err != nil {
+			fmt.Printf("Repository.ReplaceBarcodes: Error inserting barcode: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		fmt.Printf("Repository.ReplaceBarcodes: Error committing transaction: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return barcodes, nil
+}
+
+// Lookup finds the product or variant a scanned code belongs to. A code
+// nothing has that the store's scales print under one of their prefixes is
+// found by its item code, with the weighed quantity read from the code.
+func (r *PostgresRepository) Lookup(userID int, code string) (*LookupResult, *customerror.CustomError) {
+	fmt.Printf("Repository.Lookup: Looking up code %q for user %d\n", code, userID) // Add log
+	code = strings.TrimSpace(code)
+	normalized, _, err := barcode.Normalize(code)
+	if err != nil {
+		normalized = code
+	}
+
+	result, err := r.findCode(userID, normalized, code)
+	if err == sql.ErrNoRows {
+		layout, customErr := barcode.LoadScaleLayout(r.DB, userID)
+		if customErr != nil {
+			fmt.Printf("Repository.Lookup: Error loading scale layout: %s\n", customErr.Original()) // Add log
+			return nil, customErr
+		}
+		if scale, ok := layout.Parse(code); ok {
+			if result, err = r.findCode(userID, scale.ItemCode, scale.ItemCode); err == nil {
+				if err := result.readScale(scale); err != nil {
+					return nil, customerror.NewCustomError(err, err.Error(), http.StatusBadRequest)
+				}
+			}
+		}
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("No product or variant has code %s", code), http.StatusNotFound)
+		}
+		fmt.Printf("Repository.Lookup: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return result, nil
+}
+
+// findCode finds the product or variant with a barcode or SKU in one query.
+// Barcodes are matched in their normalised form before SKUs, and each branch
+// is answered by a unique (user_id, code) index.
+func (r *PostgresRepository) findCode(userID int, normalized string, code string) (*LookupResult, error) {
+	query := `
+		WITH found AS (
+			SELECT 1 AS rank, product_id, variant_id, 'barcode' AS matched_by
+			FROM product_barcodes WHERE user_id = $1 AND code = $2
+			UNION ALL
+			SELECT 2, id, NULL, 'sku' FROM products WHERE user_id = $1 AND sku = $3
+			UNION ALL
+			SELECT 2, product_id, id, 'sku' FROM product_variants WHERE user_id = $1 AND sku = $3
+			ORDER BY rank
+			LIMIT 1
+		)
+		SELECT f.matched_by,
+			p.id, p.name, p.price, p.is_available, p.category_id, p.tax_class, p.sku, p.track_stock, p.stock_on_hand,
+			p.unit, p.price_rounding, p.user_id, p.created_at, p.updated_at,
+			v.id, COALESCE(v.name, ''), v.sku, COALESCE(v.price, 0), COALESCE(v.is_available, false),
+			COALESCE(v.track_stock, false), COALESCE(v.stock_on_hand, 0), COALESCE(v.position, 0), v.created_at, v.updated_at
+		FROM found f
+		JOIN products p ON p.id = f.product_id
+		LEFT JOIN product_variants v ON v.id = f.variant_id
+	`
+
+	var result LookupResult
+	var variant Variant
+	var variantID sql.NullInt64
+	var variantCreatedAt, variantUpdatedAt sql.NullTime
+	product := &result.Product
+	err := r.DB.QueryRow(query, userID, normalized, code).Scan(
+		&result.MatchedBy,
+		&product.ID,
+		&product.Name,
+		&product.Price,
+		&product.IsAvailable,
+		&product.CategoryID,
+		&product.TaxClass,
+		&product.SKU,
+		&product.TrackStock,
+		&product.StockOnHand,
+		&product.Unit,
+		&product.PriceRounding,
+		&product.UserID,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+		&variantID,
+		&variant.Name,
+		&variant.SKU,
+		&variant.Price,
+		&variant.IsAvailable,
+		&variant.TrackStock,
+		&variant.StockOnHand,
+		&variant.Position,
+		&variantCreatedAt,
+		&variantUpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if variantID.Valid {
+		variant.ID = int(variantID.Int64)
+		variant.ProductID = product.ID
+		variant.CreatedAt = variantCreatedAt.Time
+		variant.UpdatedAt = variantUpdatedAt.Time
+		result.Variant = &variant
+	}
+	return &result, nil
+}
+
+// GetLabels loads the name, price and code of each requested product or
+// variant in one query and expands them into labels in request order
+func (r *PostgresRepository) GetLabels(userID int, items []LabelItem, encode LabelEncode) ([]label.Label, *customerror.CustomError) {
+	fmt.Printf("Repository.GetLabels: Fetching %d label items for user %d\n", len(items), userID) // Add log
+	productIDs := make([]int64, len(items))
+	variantIDs := make([]int64, len(items))
+	for i, item := range items {
+		productIDs[i] = int64(item.ProductID)
+		if item.VariantID != nil {
+			variantIDs[i] = int64(*item.VariantID)
+		}
+	}
+
+	query := `
+		SELECT i.ord, p.id, p.name, p.price, p.unit, p.sku, v.id, COALESCE(v.name, ''), COALESCE(v.price, 0), v.sku, b.code, b.kind
+		FROM unnest($2::INTEGER[], $3::INTEGER[]) WITH ORDINALITY AS i(product_id, variant_id, ord)
+		JOIN products p ON p.id = i.product_id AND p.user_id = $1
+		LEFT JOIN product_variants v ON v.id = i.variant_id AND v.product_id = p.id
+		LEFT JOIN LATERAL (
+			SELECT code, kind FROM product_barcodes
+			WHERE product_id = p.id AND variant_id IS NOT DISTINCT FROM v.id
+			ORDER BY id
+			LIMIT 1
+		) b ON true
+		ORDER BY i.ord
+	`
+	rows, err := r.DB.Query(query, userID, pq.Array(productIDs), pq.Array(variantIDs))
+	if err != nil {
+		fmt.Printf("Repository.GetLabels: Database query error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	labels := []label.Label{}
+	found := make([]bool, len(items))
+	for rows.Next() {
+		var ord int
+		var productID int
+		var name, variantName string
+		var price, variantPrice money.Money
+		var unit quantity.Unit
+		var sku, variantSKU, code *string
+		var kind *barcode.Kind
+		var variantID sql.NullInt64
+		if err := rows.Scan(&ord, &productID, &name, &price, &unit, &sku, &variantID, &variantName, &variantPrice, &variantSKU, &code, &kind); err != nil {
+			fmt.Printf("Repository.GetLabels: Error scanning row: %v\n", err) // Add log
+			return nil, customerror.NewPostgresError(err)
+		}
+		item := items[ord-1]
+		if item.VariantID != nil && !variantID.Valid {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("variant with id %d not found for product %d", *item.VariantID, productID), http.StatusNotFound)
+		}
+		found[ord-1] = true
+
+		if variantID.Valid {
+			name = strings.TrimSpace(name + " " + variantName)
+			price = variantPrice
+			sku = variantSKU
+		}
+		// Prices of products sold by weight or measure are per unit, such as "Rp65.000/kg"
+		shown := price.Format()
+		if unit != "" && unit != quantity.Each {
+			shown += "/" + string(unit)
+		}
+		printed := label.Label{Name: name, Price: shown, Symbology: barcode.SymbologyCode128, Data: strconv.Itoa(productID)}
+		switch {
+		case encode == EncodeID:
+		case code != nil && (*kind == barcode.EAN13 || *kind == barcode.UPCA):
+			printed.Symbology, printed.Data = barcode.SymbologyEAN13, *code
+		case code != nil:
+			printed.Data = *code
+		case sku != nil:
+			printed.Data = *sku
+		}
+
+		copies := max(item.Copies, 1)
+		for range copies {
+			labels = append(labels, printed)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		fmt.Printf("Repository.GetLabels: Error iterating rows: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	for i, ok := range found {
+		if !ok {
+			return nil, customerror.NewCustomError(nil, fmt.Sprintf("product with id %d not found or user not authorized", items[i].ProductID), http.StatusNotFound)
+		}
+	}
+	return labels, nil
+}
@@ -1,6 +1,11 @@
 package product
 
-import "github.com/yantology/simple-pos/pkg/customerror"
+import (
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/label"
+	"github.com/yantology/simple-pos/pkg/option"
+	"github.com/yantology/simple-pos/pkg/stock"
+)
 
 // repository implements the Repository interface
 type repository struct {
@@ -38,3 +43,73 @@ func (r *repository) Delete(id int, userID int) *customerror.CustomError { // Ch
 func (r *repository) GetByCategoryID(categoryID int) ([]*Product, *customerror.CustomError) {
 	return r.database.GetByCategoryID(categoryID)
 }
+
+// Export calls the database Export method
+func (r *repository) Export(userID int, categoryID *int, write func(*ExportedProduct) error) *customerror.CustomError {
+	return r.database.Export(userID, categoryID, write)
+}
+
+// Import calls the database Import method
+func (r *repository) Import(userID int, rows []ImportRow, dryRun bool) ([]ImportRowResult, *customerror.CustomError) {
+	return r.database.Import(userID, rows, dryRun)
+}
+
+// GetStockMovements calls the database GetStockMovements method
+func (r *repository) GetStockMovements(id int, userID int, variantID *int, cursor int, limit int) (*StockMovementPage, *customerror.CustomError) {
+	return r.database.GetStockMovements(id, userID, variantID, cursor, limit)
+}
+
+// RecordStockMovement calls the database RecordStockMovement method
+func (r *repository) RecordStockMovement(id int, userID int, request *CreateStockMovement) (*stock.Movement, *customerror.CustomError) {
+	return r.database.RecordStockMovement(id, userID, request)
+}
+
+// GetOptionGroups calls the database GetOptionGroups method
+func (r *repository) GetOptionGroups(id int, userID int) ([]option.Group, *customerror.CustomError) {
+	return r.database.GetOptionGroups(id, userID)
+}
+
+// ReplaceOptionGroups calls the database ReplaceOptionGroups method
+func (r *repository) ReplaceOptionGroups(id int, userID int, groups []option.Group) ([]option.Group, *customerror.CustomError) {
+	return r.database.ReplaceOptionGroups(id, userID, groups)
+}
+
+// GetVariants calls the database GetVariants method
+func (r *repository) GetVariants(id int, userID int) ([]Variant, *customerror.CustomError) {
+	return r.database.GetVariants(id, userID)
+}
+
+// CreateVariant calls the database CreateVariant method
+func (r *repository) CreateVariant(id int, userID int, request *SaveVariant) (*Variant, *customerror.CustomError) {
+	return r.database.CreateVariant(id, userID, request)
+}
+
+// UpdateVariant calls the database UpdateVariant method
+func (r *repository) UpdateVariant(id int, variantID int, userID int, request *SaveVariant) (*Variant, *customerror.CustomError) {
+	return r.database.UpdateVariant(id, variantID, userID, request)
+}
+
+// DeleteVariant calls the database DeleteVariant method
+func (r *repository) DeleteVariant(id int, variantID int, userID int) *customerror.CustomError {
+	return r.database.DeleteVariant(id, variantID, userID)
+}
+
+// GetBarcodes calls the database GetBarcodes method
+func (r *repository) GetBarcodes(id int, userID int) ([]Barcode, *customerror.CustomError) {
+	return r.database.GetBarcodes(id, userID)
+}
+
+// ReplaceBarcodes calls the database ReplaceBarcodes method
+func (r *repository) ReplaceBarcodes(id int, userID int, barcodes []Barcode) ([]Barcode, *customerror.CustomError) {
+	return r.database.ReplaceBarcodes(id, userID, barcodes)
+}
+
+// Lookup calls the database Lookup method
+func (r *repository) Lookup(userID int, code string) (*LookupResult, *customerror.CustomError) {
+	return r.database.Lookup(userID, code)
+}
+
+// GetLabels calls the database GetLabels method
+func (r *repository) GetLabels(userID int, items []LabelItem, encode LabelEncode) ([]label.Label, *customerror.CustomError) {
+	return r.database.GetLabels(userID, items, encode)
+}
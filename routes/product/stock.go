@@ -0,0 +1,134 @@
+package product
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+	"github.com/yantology/simple-pos/pkg/stock"
+)
+
+const (
+	defaultMovementPageSize = 50
+	maxMovementPageSize     = 200
+)
+
+// @Summary List stock movements of a product
+// @Description Retrieves the stock ledger of a product, newest first, one page at a time: sales, voids, refunds, adjustments, receiving and waste, each with the signed quantity and the stock on hand after it. Products with variants keep stock per variant. Pass the returned next_cursor as cursor to fetch the following page; it is empty on the last page.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant_id query int false "Only movements of this variant"
+// @Param limit query int false "Movements per page (1-200)" default(50)
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} dto.PageResponse[[]stock.Movement] "Successfully retrieved stock movements"
+// @Failure 400 {object} dto.MessageResponse "Invalid product ID, limit or cursor"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/stock/movements [get]
+func (h *Handler) GetStockMovements(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid product ID format"})
+		return
+	}
+
+	limit := defaultMovementPageSize
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxMovementPageSize {
+			c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: fmt.Sprintf("limit must be between 1 and %d", maxMovementPageSize)})
+			return
+		}
+	}
+	var variantID *int
+	if value := c.Query("variant_id"); value != "" {
+		variant, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid variant ID format"})
+			return
+		}
+		variantID = &variant
+	}
+	cursor := 0
+	if value := c.Query("cursor"); value != "" {
+		cursor, err = strconv.Atoi(value)
+		if err != nil || cursor < 1 {
+			c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid cursor"})
+			return
+		}
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	page, customErr := h.repository.GetStockMovements(id, userID, variantID, cursor, limit)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PageResponse[[]stock.Movement]{Data: page.Movements, NextCursor: page.NextCursor})
+}
+
+// @Summary Record a stock movement
+// @Description Records stock received, wasted or corrected by hand for a product or variant that tracks stock. Receiving and waste take the number of units; an adjustment takes the signed correction. Waste and downward adjustments cannot take stock below zero unless the store allows negative stock.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param movement body CreateStockMovement true "Stock movement"
+// @Success 201 {object} dto.DataResponse[stock.Movement] "Stock movement recorded"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product or variant not found"
+// @Failure 409 {object} dto.MessageResponse "Product or variant does not track stock, or not enough stock"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/stock/movements [post]
+func (h *Handler) RecordStockMovement(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid product ID format"})
+		return
+	}
+
+	var request CreateStockMovement
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	movement, customErr := h.repository.RecordStockMovement(id, userID, &request)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DataResponse[*stock.Movement]{Data: movement})
+}
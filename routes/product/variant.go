@@ -0,0 +1,240 @@
+package product
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+	"github.com/yantology/simple-pos/pkg/option"
+)
+
+// productAndUser reads the product ID path parameter and the user ID from the
+// middleware context, answering the request itself when either is invalid
+func productAndUser(c *gin.Context) (id int, userID int, ok bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid product ID format"})
+		return 0, 0, false
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return 0, 0, false
+	}
+
+	userID, err = strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return 0, 0, false
+	}
+	return id, userID, true
+}
+
+// variantParam reads the variant ID path parameter
+func variantParam(c *gin.Context) (int, bool) {
+	variantID, err := strconv.Atoi(c.Param("variantID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid variant ID format"})
+		return 0, false
+	}
+	return variantID, true
+}
+
+// @Summary Get option groups of a product
+// @Description Retrieves the option groups of a product, such as "Size: S/M/L" or "Ice: less/normal", with each option's price delta and the group's selection rules, in display order.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} dto.DataResponse[[]option.Group] "Successfully retrieved option groups"
+// @Failure 400 {object} dto.MessageResponse "Invalid product ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/options [get]
+func (h *Handler) GetOptionGroups(c *gin.Context) {
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+
+	groups, customErr := h.repository.GetOptionGroups(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]option.Group]{Data: groups})
+}
+
+// @Summary Replace option groups of a product
+// @Description Replaces every option group of a product. Required groups need at least one choice, or min_select when higher; max_select limits the choices, with 0 allowing every option. Each chosen option adds its price delta, which may be negative, to the line's unit price. Options get new IDs; past orders keep the options they were sold with.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param groups body SaveOptionGroups true "Option groups in display order"
+// @Success 200 {object} dto.DataResponse[[]option.Group] "Option groups saved"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/options [put]
+func (h *Handler) ReplaceOptionGroups(c *gin.Context) {
+	var request SaveOptionGroups
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	groups, err := request.optionGroups()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid option groups: " + err.Error()})
+		return
+	}
+
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+
+	groups, customErr := h.repository.ReplaceOptionGroups(id, userID, groups)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]option.Group]{Data: groups})
+}
+
+// @Summary Get variants of a product
+// @Description Retrieves the sellable variants of a product, such as "Large Hot", each with its own SKU, price and stock, in display order.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {object} dto.DataResponse[[]Variant] "Successfully retrieved variants"
+// @Failure 400 {object} dto.MessageResponse "Invalid product ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/variants [get]
+func (h *Handler) GetVariants(c *gin.Context) {
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+
+	variants, customErr := h.repository.GetVariants(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]Variant]{Data: variants})
+}
+
+// @Summary Create a variant
+// @Description Adds a sellable variant to a product. Once a product has variants, order lines must name one of them and are priced and stocked by the variant.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variant body SaveVariant true "Variant details"
+// @Success 201 {object} dto.DataResponse[Variant] "Variant created"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Product not found"
+// @Failure 409 {object} dto.MessageResponse "Variant name or SKU already used"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/variants [post]
+func (h *Handler) CreateVariant(c *gin.Context) {
+	var request SaveVariant
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+
+	variant, customErr := h.repository.CreateVariant(id, userID, &request)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DataResponse[*Variant]{Data: variant})
+}
+
+// @Summary Update a variant
+// @Description Updates a variant of a product. Its stock on hand only changes through stock movements.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variantID path int true "Variant ID"
+// @Param variant body SaveVariant true "Variant details"
+// @Success 200 {object} dto.DataResponse[Variant] "Variant updated"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Variant not found"
+// @Failure 409 {object} dto.MessageResponse "Variant name or SKU already used"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/variants/{variantID} [put]
+func (h *Handler) UpdateVariant(c *gin.Context) {
+	var request SaveVariant
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+	variantID, ok := variantParam(c)
+	if !ok {
+		return
+	}
+
+	variant, customErr := h.repository.UpdateVariant(id, variantID, userID, &request)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*Variant]{Data: variant})
+}
+
+// @Summary Delete a variant
+// @Description Deletes a variant of a product together with its stock ledger. Past order lines keep the variant's name.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param variantID path int true "Variant ID"
+// @Success 200 {object} dto.MessageResponse "Variant deleted successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Variant not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /products/{id}/variants/{variantID} [delete]
+func (h *Handler) DeleteVariant(c *gin.Context) {
+	id, userID, ok := productAndUser(c)
+	if !ok {
+		return
+	}
+	variantID, ok := variantParam(c)
+	if !ok {
+		return
+	}
+
+	if customErr := h.repository.DeleteVariant(id, variantID, userID); customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Variant deleted successfully"})
+}
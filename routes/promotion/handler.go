@@ -0,0 +1,236 @@
+package promotion
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+)
+
+// Handler holds the dependencies for the promotion handlers
+type Handler struct {
+	repository Repository
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(repository Repository) *Handler {
+	return &Handler{
+		repository: repository,
+	}
+}
+
+// RegisterRoutes sets up all the routes for promotion management
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetAllPromotions)
+	router.GET("/:id", h.GetPromotionByID)
+	router.POST("", h.CreatePromotion)
+	router.PUT("/:id", h.UpdatePromotion)
+	router.DELETE("/:id", h.DeletePromotion)
+}
+
+// @Summary Get all promotions
+// @Description Retrieves all promotions of the authenticated user.
+// @Tags promotions
+// @Produce json
+// @Success 200 {object} dto.DataResponse[[]Promotion] "Successfully retrieved promotions"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /promotions [get]
+func (h *Handler) GetAllPromotions(c *gin.Context) {
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	promotions, customErr := h.repository.GetAll(userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]Promotion]{Data: promotions})
+}
+
+// @Summary Get promotion by ID
+// @Description Retrieves a specific promotion of the authenticated user.
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} dto.DataResponse[Promotion] "Successfully retrieved promotion"
+// @Failure 400 {object} dto.MessageResponse "Invalid promotion ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Promotion not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /promotions/{id} [get]
+func (h *Handler) GetPromotionByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid promotion ID format"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	promotion, customErr := h.repository.GetByID(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*Promotion]{Data: promotion})
+}
+
+// @Summary Create a new promotion
+// @Description Creates a percentage, fixed or buy-x-get-y promotion on the whole order, on categories or on products, optionally limited by minimum spend, dates, weekdays and a daily time window.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param promotion body CreatePromotion true "Promotion details"
+// @Success 201 {object} dto.DataResponse[Promotion] "Promotion created successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /promotions [post]
+func (h *Handler) CreatePromotion(c *gin.Context) {
+	var request CreatePromotion
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	if _, err := request.Rule(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid promotion: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	promotion, customErr := h.repository.Create(&request, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DataResponse[*Promotion]{Data: promotion})
+}
+
+// @Summary Update an existing promotion
+// @Description Updates a promotion of the authenticated user. Orders already created keep the discounts they were given.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Param promotion body UpdatePromotion true "Updated promotion details"
+// @Success 200 {object} dto.DataResponse[Promotion] "Promotion updated successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data or ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Promotion not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /promotions/{id} [put]
+func (h *Handler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid promotion ID format"})
+		return
+	}
+
+	var request UpdatePromotion
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	if _, err := request.Rule(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid promotion: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	promotion, customErr := h.repository.Update(id, userID, &request)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*Promotion]{Data: promotion})
+}
+
+// @Summary Delete a promotion
+// @Description Deletes a promotion of the authenticated user.
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} dto.MessageResponse "Promotion deleted successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid promotion ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Promotion not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /promotions/{id} [delete]
+func (h *Handler) DeletePromotion(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid promotion ID format"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	if customErr := h.repository.Delete(id, userID); customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{Message: "Promotion deleted successfully"})
+}
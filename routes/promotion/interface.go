@@ -0,0 +1,12 @@
+package promotion
+
+import "github.com/yantology/simple-pos/pkg/customerror"
+
+// Repository defines the data access methods for promotions
+type Repository interface {
+	GetAll(userID int) ([]Promotion, *customerror.CustomError)
+	GetByID(id int, userID int) (*Promotion, *customerror.CustomError)
+	Create(promotion *CreatePromotion, userID int) (*Promotion, *customerror.CustomError)
+	Update(id int, userID int, promotion *UpdatePromotion) (*Promotion, *customerror.CustomError)
+	Delete(id int, userID int) *customerror.CustomError
+}
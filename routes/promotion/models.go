@@ -0,0 +1,102 @@
+package promotion
+
+import (
+	"errors"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/promo"
+)
+
+var errStartEndTime = errors.New("start_time and end_time must be set together")
+
+// Promotion represents an automatic discount rule evaluated during order creation
+// @Description Promotion model
+type Promotion struct {
+	ID          int         `json:"id" example:"1"`
+	Name        string      `json:"name" example:"Happy hour coffee"`
+	Type        promo.Type  `json:"type" example:"percentage"`
+	Scope       promo.Scope `json:"scope" example:"category"`
+	Percent     float64     `json:"percent" example:"20"`
+	Amount      money.Money `json:"amount"`
+	BuyQuantity int         `json:"buy_quantity" example:"0"`
+	GetQuantity int         `json:"get_quantity" example:"0"`
+	MinSpend    money.Money `json:"min_spend"`
+	ProductIDs  []int       `json:"product_ids"`
+	CategoryIDs []int       `json:"category_ids" example:"1"`
+	StartsAt    *time.Time  `json:"starts_at" example:"2025-05-01T00:00:00Z"`
+	EndsAt      *time.Time  `json:"ends_at" example:"2025-05-31T23:59:59Z"`
+	DaysOfWeek  []int       `json:"days_of_week" example:"1,2,3,4,5"`
+	StartTime   *string     `json:"start_time" example:"15:00"`
+	EndTime     *string     `json:"end_time" example:"17:00"`
+	IsActive    bool        `json:"is_active" example:"true"`
+	UserID      int         `json:"user_id" example:"1"`
+	CreatedAt   time.Time   `json:"created_at" example:"2025-04-25T15:04:05Z07:00"`
+	UpdatedAt   time.Time   `json:"updated_at" example:"2025-04-25T15:04:05Z07:00"`
+}
+
+// CreatePromotion defines the structure for creating or updating a promotion.
+// Percent is used by percentage promotions, Amount by fixed ones (per unit for
+// category and product scope, per order for order scope) and BuyQuantity and
+// GetQuantity by buy_x_get_y. DaysOfWeek uses 0 for Sunday through 6 for Saturday.
+// @Description Create promotion request model
+type CreatePromotion struct {
+	Name        string      `json:"name" binding:"required" example:"Happy hour coffee"`
+	Type        promo.Type  `json:"type" binding:"required,oneof=percentage fixed buy_x_get_y" example:"percentage"`
+	Scope       promo.Scope `json:"scope" binding:"required,oneof=order category product" example:"category"`
+	Percent     float64     `json:"percent" example:"20"`
+	Amount      money.Money `json:"amount" binding:"money"`
+	BuyQuantity int         `json:"buy_quantity" example:"0"`
+	GetQuantity int         `json:"get_quantity" example:"0"`
+	MinSpend    money.Money `json:"min_spend" binding:"money"`
+	ProductIDs  []int       `json:"product_ids"`
+	CategoryIDs []int       `json:"category_ids" example:"1"`
+	StartsAt    *time.Time  `json:"starts_at" example:"2025-05-01T00:00:00Z"`
+	EndsAt      *time.Time  `json:"ends_at" example:"2025-05-31T23:59:59Z"`
+	DaysOfWeek  []int       `json:"days_of_week" binding:"omitempty,dive,min=0,max=6" example:"1,2,3,4,5"`
+	StartTime   *string     `json:"start_time" example:"15:00"`
+	EndTime     *string     `json:"end_time" example:"17:00"`
+	IsActive    bool        `json:"is_active" example:"true"`
+}
+
+// UpdatePromotion defines the structure for updating a promotion
+// @Description Update promotion request model
+type UpdatePromotion = CreatePromotion
+
+// Rule converts the request into an evaluation rule so it can be validated
+func (p *CreatePromotion) Rule() (promo.Promotion, error) {
+	rule := promo.Promotion{
+		Name:        p.Name,
+		Type:        p.Type,
+		Scope:       p.Scope,
+		Percent:     p.Percent,
+		Amount:      p.Amount.Amount,
+		BuyQuantity: p.BuyQuantity,
+		GetQuantity: p.GetQuantity,
+		MinSpend:    p.MinSpend.Amount,
+		ProductIDs:  p.ProductIDs,
+		CategoryIDs: p.CategoryIDs,
+		StartsAt:    p.StartsAt,
+		EndsAt:      p.EndsAt,
+	}
+	for _, day := range p.DaysOfWeek {
+		rule.DaysOfWeek = append(rule.DaysOfWeek, time.Weekday(day))
+	}
+
+	if (p.StartTime == nil) != (p.EndTime == nil) {
+		return rule, errStartEndTime
+	}
+	if p.StartTime != nil {
+		start, err := promo.ParseClock(*p.StartTime)
+		if err != nil {
+			return rule, err
+		}
+		end, err := promo.ParseClock(*p.EndTime)
+		if err != nil {
+			return rule, err
+		}
+		rule.Window = &promo.TimeWindow{Start: start, End: end}
+	}
+
+	return rule, rule.Validate()
+}
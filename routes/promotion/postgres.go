@@ -0,0 +1,216 @@
+package promotion
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
+
+// PostgresRepository implements the Repository interface using PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository instance
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &PostgresRepository{db: db}
+}
+
+const promotionColumns = `
+	id, name, type, scope, percent, amount, buy_quantity, get_quantity, min_spend,
+	product_ids, category_ids, starts_at, ends_at, days_of_week,
+	to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+	is_active, user_id, created_at, updated_at`
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanPromotion reads a promotion selected with promotionColumns
+func scanPromotion(row scanner) (*Promotion, error) {
+	var promotion Promotion
+	var productIDs, categoryIDs, daysOfWeek pq.Int64Array
+	var startsAt, endsAt sql.NullTime
+	var startTime, endTime sql.NullString
+
+	err := row.Scan(
+		&promotion.ID,
+		&promotion.Name,
+		&promotion.Type,
+		&promotion.Scope,
+		&promotion.Percent,
+		&promotion.Amount,
+		&promotion.BuyQuantity,
+		&promotion.GetQuantity,
+		&promotion.MinSpend,
+		&productIDs,
+		&categoryIDs,
+		&startsAt,
+		&endsAt,
+		&daysOfWeek,
+		&startTime,
+		&endTime,
+		&promotion.IsActive,
+		&promotion.UserID,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion.ProductIDs = toInts(productIDs)
+	promotion.CategoryIDs = toInts(categoryIDs)
+	promotion.DaysOfWeek = toInts(daysOfWeek)
+	if startsAt.Valid {
+		promotion.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		promotion.EndsAt = &endsAt.Time
+	}
+	if startTime.Valid {
+		promotion.StartTime = &startTime.String
+	}
+	if endTime.Valid {
+		promotion.EndTime = &endTime.String
+	}
+	return &promotion, nil
+}
+
+// GetAll retrieves all promotions of a user
+func (r *PostgresRepository) GetAll(userID int) ([]Promotion, *customerror.CustomError) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE user_id = $1 ORDER BY id`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	promotions := []Promotion{}
+	for rows.Next() {
+		promotion, err := scanPromotion(rows)
+		if err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		promotions = append(promotions, *promotion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return promotions, nil
+}
+
+// GetByID retrieves a promotion by its ID and user ID
+func (r *PostgresRepository) GetByID(id int, userID int) (*Promotion, *customerror.CustomError) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1 AND user_id = $2`
+	promotion, err := scanPromotion(r.db.QueryRow(query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(err, fmt.Sprintf("Promotion with ID %d not found or user not authorized", id), http.StatusNotFound)
+		}
+		return nil, customerror.NewPostgresError(err)
+	}
+	return promotion, nil
+}
+
+// Create stores a new promotion for the user
+func (r *PostgresRepository) Create(data *CreatePromotion, userID int) (*Promotion, *customerror.CustomError) {
+	query := `
+		INSERT INTO promotions (
+			name, type, scope, percent, amount, buy_quantity, get_quantity, min_spend,
+			product_ids, category_ids, starts_at, ends_at, days_of_week, start_time, end_time,
+			is_active, user_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING ` + promotionColumns
+
+	promotion, err := scanPromotion(r.db.QueryRow(query, append(promotionArgs(data), userID)...))
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return promotion, nil
+}
+
+// Update modifies an existing promotion, ensuring the user owns it
+func (r *PostgresRepository) Update(id int, userID int, data *UpdatePromotion) (*Promotion, *customerror.CustomError) {
+	query := `
+		UPDATE promotions
+		SET name = $1, type = $2, scope = $3, percent = $4, amount = $5, buy_quantity = $6,
+			get_quantity = $7, min_spend = $8, product_ids = $9, category_ids = $10, starts_at = $11,
+			ends_at = $12, days_of_week = $13, start_time = $14, end_time = $15, is_active = $16
+		WHERE id = $17 AND user_id = $18
+		RETURNING ` + promotionColumns
+
+	promotion, err := scanPromotion(r.db.QueryRow(query, append(promotionArgs(data), id, userID)...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(nil, "Promotion not found or user not authorized to update", http.StatusNotFound)
+		}
+		return nil, customerror.NewPostgresError(err)
+	}
+	return promotion, nil
+}
+
+// Delete removes a promotion, ensuring the user owns it. Orders keep their
+// applied discounts because order_discounts only references the promotion.
+func (r *PostgresRepository) Delete(id int, userID int) *customerror.CustomError {
+	result, err := r.db.Exec(`DELETE FROM promotions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return customerror.NewPostgresError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return customerror.NewCustomError(err, fmt.Sprintf("Error getting rows affected: %v", err), http.StatusInternalServerError)
+	}
+
+	if rowsAffected == 0 {
+		return customerror.NewCustomError(nil, "Promotion not found or user not authorized to delete", http.StatusNotFound)
+	}
+
+	return nil
+}
+
+// promotionArgs returns the column values of a promotion request in insert order
+func promotionArgs(data *CreatePromotion) []any {
+	return []any{
+		data.Name,
+		data.Type,
+		data.Scope,
+		data.Percent,
+		data.Amount,
+		data.BuyQuantity,
+		data.GetQuantity,
+		data.MinSpend,
+		pq.Array(toInt64s(data.ProductIDs)),
+		pq.Array(toInt64s(data.CategoryIDs)),
+		data.StartsAt,
+		data.EndsAt,
+		pq.Array(toInt64s(data.DaysOfWeek)),
+		data.StartTime,
+		data.EndTime,
+		data.IsActive,
+	}
+}
+
+func toInts(values pq.Int64Array) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
+}
+
+func toInt64s(values []int) []int64 {
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i] = int64(v)
+	}
+	return ints
+}
@@ -0,0 +1,40 @@
+package promotion
+
+import "github.com/yantology/simple-pos/pkg/customerror"
+
+// repository implements the Repository interface
+type repository struct {
+	database Repository
+}
+
+// NewRepository creates a new repository instance
+func NewRepository(db Repository) Repository {
+	return &repository{
+		database: db,
+	}
+}
+
+// GetAll calls the database GetAll method
+func (r *repository) GetAll(userID int) ([]Promotion, *customerror.CustomError) {
+	return r.database.GetAll(userID)
+}
+
+// GetByID calls the database GetByID method
+func (r *repository) GetByID(id int, userID int) (*Promotion, *customerror.CustomError) {
+	return r.database.GetByID(id, userID)
+}
+
+// Create calls the database Create method
+func (r *repository) Create(promotion *CreatePromotion, userID int) (*Promotion, *customerror.CustomError) {
+	return r.database.Create(promotion, userID)
+}
+
+// Update calls the database Update method
+func (r *repository) Update(id int, userID int, promotion *UpdatePromotion) (*Promotion, *customerror.CustomError) {
+	return r.database.Update(id, userID, promotion)
+}
+
+// Delete calls the database Delete method
+func (r *repository) Delete(id int, userID int) *customerror.CustomError {
+	return r.database.Delete(id, userID)
+}
@@ -0,0 +1,211 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+)
+
+const (
+	defaultPeriodDays = 30
+	maxPeriodDays     = 366
+	defaultTopLimit   = 10
+	maxTopLimit       = 100
+)
+
+// Period is a range of whole business days. From and To are inclusive.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// bounds returns the start of From and the start of the day after To
+func (p Period) bounds() (time.Time, time.Time) {
+	return p.From, p.To.AddDate(0, 0, 1)
+}
+
+// Days returns the number of days in the period
+func (p Period) Days() int {
+	return int(p.To.Sub(p.From).Hours()/24) + 1
+}
+
+// Previous returns the period of the same length that ends the day before p
+func (p Period) Previous() Period {
+	to := p.From.AddDate(0, 0, -1)
+	return Period{From: to.AddDate(0, 0, 1-p.Days()), To: to}
+}
+
+// parsePeriod reads the from and to dates of an analytics query. Without
+// dates the last 30 days up to today are used.
+func parsePeriod(query url.Values) (Period, error) {
+	period := Period{To: today()}
+	if value := query.Get("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return Period{}, errors.New("to must be a date such as 2026-10-17")
+		}
+		period.To = to
+	}
+	period.From = period.To.AddDate(0, 0, 1-defaultPeriodDays)
+	if value := query.Get("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return Period{}, errors.New("from must be a date such as 2026-10-17")
+		}
+		period.From = from
+	}
+	if period.To.Before(period.From) {
+		return Period{}, errors.New("to must not be before from")
+	}
+	if period.Days() > maxPeriodDays {
+		return Period{}, fmt.Errorf("period must not be longer than %d days", maxPeriodDays)
+	}
+	return period, nil
+}
+
+// ProductRanking is what top products are ranked by
+type ProductRanking string
+
+const (
+	RankByQuantity ProductRanking = "quantity"
+	RankByRevenue  ProductRanking = "revenue"
+)
+
+// parseTopProducts reads the ranking and number of products to return
+func parseTopProducts(query url.Values) (ProductRanking, int, error) {
+	by := RankByQuantity
+	if value := query.Get("by"); value != "" {
+		by = ProductRanking(value)
+		if by != RankByQuantity && by != RankByRevenue {
+			return "", 0, fmt.Errorf("by must be %s or %s", RankByQuantity, RankByRevenue)
+		}
+	}
+	limit := defaultTopLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxTopLimit {
+			return "", 0, fmt.Errorf("limit must be between 1 and %d", maxTopLimit)
+		}
+	}
+	return by, limit, nil
+}
+
+// ProductSales is the net quantity and revenue of one product over a period.
+// Revenue is the line value after discounts and refunds. ProductID is nil
+// for products that were deleted since.
+type ProductSales struct {
+	Rank      int               `json:"rank" example:"1"`
+	ProductID *int              `json:"product_id" example:"1"`
+	Name      string            `json:"name" example:"Cafe latte"`
+	Category  string            `json:"category" example:"Coffee"`
+	Quantity  quantity.Quantity `json:"quantity" example:"214"`
+	Revenue   money.Money       `json:"revenue"`
+}
+
+// HeatmapCell is the sales of one hour of one weekday over a period.
+// Weekday runs from 1 (Monday) to 7 (Sunday).
+type HeatmapCell struct {
+	Weekday    int         `json:"weekday" example:"5"`
+	Hour       int         `json:"hour" example:"12"`
+	OrderCount int         `json:"order_count" example:"37"`
+	Sales      money.Money `json:"sales"`
+}
+
+// SalesHeatmap is the sales of a period by hour of day and weekday. Cells
+// holds every weekday and hour, including those without sales.
+// @Description Sales heatmap model
+type SalesHeatmap struct {
+	From      string         `json:"from" example:"2026-09-18"`
+	To        string         `json:"to" example:"2026-10-17"`
+	Cells     []HeatmapCell  `json:"cells"`
+	ByHour    []HourlySales  `json:"by_hour"`
+	ByWeekday []WeekdaySales `json:"by_weekday"`
+}
+
+// HourlySales is the sales of one hour of the day over a period
+type HourlySales struct {
+	Hour       int         `json:"hour" example:"12"`
+	OrderCount int         `json:"order_count" example:"180"`
+	Sales      money.Money `json:"sales"`
+}
+
+// WeekdaySales is the sales of one weekday over a period
+type WeekdaySales struct {
+	Weekday    int         `json:"weekday" example:"5"`
+	OrderCount int         `json:"order_count" example:"240"`
+	Sales      money.Money `json:"sales"`
+}
+
+// CategoryShare is one category's part of the revenue of a period. Share is
+// a percentage of the period's revenue.
+type CategoryShare struct {
+	CategoryID *int              `json:"category_id" example:"1"`
+	Category   string            `json:"category" example:"Coffee"`
+	Quantity   quantity.Quantity `json:"quantity" example:"540"`
+	Revenue    money.Money       `json:"revenue"`
+	Share      float64           `json:"share" example:"41.25"`
+}
+
+// CategoryMix is the revenue of a period split by category
+// @Description Category mix model
+type CategoryMix struct {
+	From       string          `json:"from" example:"2026-09-18"`
+	To         string          `json:"to" example:"2026-10-17"`
+	Revenue    money.Money     `json:"revenue"`
+	Categories []CategoryShare `json:"categories"`
+}
+
+// PeriodSummary is the headline figures of a period. Figures follow the
+// daily report: orders count when paid and refunds when given.
+type PeriodSummary struct {
+	From          string            `json:"from" example:"2026-09-18"`
+	To            string            `json:"to" example:"2026-10-17"`
+	OrderCount    int               `json:"order_count" example:"1260"`
+	ItemsSold     quantity.Quantity `json:"items_sold" example:"3480"`
+	NetSales      money.Money       `json:"net_sales"`
+	Total         money.Money       `json:"total"`
+	AverageBasket money.Money       `json:"average_basket"`
+}
+
+// PeriodChange is the change of each figure from the previous period in
+// percent. A change is null when the previous figure was zero.
+type PeriodChange struct {
+	OrderCount    *float64 `json:"order_count" example:"12.5"`
+	ItemsSold     *float64 `json:"items_sold" example:"8.2"`
+	NetSales      *float64 `json:"net_sales" example:"-3.4"`
+	Total         *float64 `json:"total" example:"-3.1"`
+	AverageBasket *float64 `json:"average_basket" example:"-14.1"`
+}
+
+// PeriodComparison compares a period with the period of the same length
+// right before it
+// @Description Period comparison model
+type PeriodComparison struct {
+	Current  PeriodSummary `json:"current"`
+	Previous PeriodSummary `json:"previous"`
+	Change   PeriodChange  `json:"change"`
+}
+
+// percentChange returns the change from previous to current in percent,
+// rounded to two decimals, or nil when previous is zero
+func percentChange(current int64, previous int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := roundPercent(float64(current-previous) / float64(previous) * 100)
+	return &change
+}
+
+// roundPercent rounds a percentage to two decimals
+func roundPercent(value float64) float64 {
+	if value < 0 {
+		return -roundPercent(-value)
+	}
+	return float64(int64(value*100+0.5)) / 100
+}
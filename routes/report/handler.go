@@ -0,0 +1,372 @@
+package report
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+)
+
+// Handler holds the dependencies for the report handlers
+type Handler struct {
+	repository Repository
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(repository Repository) *Handler {
+	return &Handler{
+		repository: repository,
+	}
+}
+
+// RegisterRoutes sets up all the routes for sales reports
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/daily", h.GetDaily)
+	router.POST("/daily/close", h.CloseDay)
+	router.GET("/z-reports", h.GetZReports)
+	router.GET("/z-reports/:id", h.GetZReportByID)
+	router.GET("/analytics/top-products", h.GetTopProducts)
+	router.GET("/analytics/heatmap", h.GetSalesHeatmap)
+	router.GET("/analytics/category-mix", h.GetCategoryMix)
+	router.GET("/analytics/comparison", h.ComparePeriods)
+}
+
+// today returns the current business day. Orders are stamped with the
+// server's local time, so the day is read from that clock too.
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// @Summary Get daily sales summary
+// @Description Summarises the sales of a business day: gross sales, discounts, refunds, net sales, service charge, tax, rounding, order count and average basket, with totals per payment method and per category. Orders count on the day they were paid and refunds on the day they were given. The summary is live; close the day to freeze it into a Z report.
+// @Tags reports
+// @Produce json
+// @Param date query string false "Business day as YYYY-MM-DD, today when omitted"
+// @Success 200 {object} dto.DataResponse[DailyReport] "Successfully retrieved daily summary"
+// @Failure 400 {object} dto.MessageResponse "Invalid date"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/daily [get]
+func (h *Handler) GetDaily(c *gin.Context) {
+	date := today()
+	if value := c.Query("date"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	report, customErr := h.repository.GetDaily(userID, date)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*DailyReport]{Data: report})
+}
+
+// @Summary Close a business day
+// @Description Closes a business day into the next numbered Z report. The report's figures are frozen at closing and cannot be changed; a day can only be closed once and days in the future cannot be closed.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param request body CloseDay true "Business day to close"
+// @Success 201 {object} dto.DataResponse[ZReport] "Day closed successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 409 {object} dto.MessageResponse "Business day is already closed"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/daily/close [post]
+func (h *Handler) CloseDay(c *gin.Context) {
+	var request CloseDay
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	date, err := time.Parse("2006-01-02", request.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid date, expected YYYY-MM-DD"})
+		return
+	}
+	if date.After(today()) {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Cannot close a business day in the future"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	report, customErr := h.repository.CloseDay(userID, date)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.DataResponse[*ZReport]{Data: report})
+}
+
+// @Summary Get Z reports
+// @Description Retrieves the Z reports of the authenticated user, latest business day first.
+// @Tags reports
+// @Produce json
+// @Success 200 {object} dto.DataResponse[[]ZReport] "Successfully retrieved Z reports"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/z-reports [get]
+func (h *Handler) GetZReports(c *gin.Context) {
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	reports, customErr := h.repository.GetZReports(userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]ZReport]{Data: reports})
+}
+
+// @Summary Get Z report by ID
+// @Description Retrieves a specific Z report of the authenticated user.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Z report ID"
+// @Success 200 {object} dto.DataResponse[ZReport] "Successfully retrieved Z report"
+// @Failure 400 {object} dto.MessageResponse "Invalid Z report ID format"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 404 {object} dto.MessageResponse "Z report not found"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/z-reports/{id} [get]
+func (h *Handler) GetZReportByID(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid Z report ID format"})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	report, customErr := h.repository.GetZReportByID(id, userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*ZReport]{Data: report})
+}
+
+// @Summary Get top products
+// @Description Ranks the products sold in a period by net quantity or by net revenue after discounts and refunds. Orders count on the day they were paid.
+// @Tags reports
+// @Produce json
+// @Param from query string false "First day as YYYY-MM-DD, 29 days before to when omitted"
+// @Param to query string false "Last day as YYYY-MM-DD, today when omitted"
+// @Param by query string false "Rank by quantity or revenue" Enums(quantity, revenue) default(quantity)
+// @Param limit query int false "Number of products, 1 to 100" default(10)
+// @Success 200 {object} dto.DataResponse[[]ProductSales] "Successfully retrieved analytics"
+// @Failure 400 {object} dto.MessageResponse "Invalid query"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/analytics/top-products [get]
+func (h *Handler) GetTopProducts(c *gin.Context) {
+	period, err := parsePeriod(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid query: " + err.Error()})
+		return
+	}
+	by, limit, err := parseTopProducts(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid query: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	result, customErr := h.repository.GetTopProducts(userID, period, by, limit)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[[]ProductSales]{Data: result})
+}
+
+// @Summary Get sales heatmap
+// @Description Sums the orders paid in a period by weekday and hour of day, with totals per hour and per weekday. Weekdays run from 1 (Monday) to 7 (Sunday) and every weekday and hour is listed.
+// @Tags reports
+// @Produce json
+// @Param from query string false "First day as YYYY-MM-DD, 29 days before to when omitted"
+// @Param to query string false "Last day as YYYY-MM-DD, today when omitted"
+// @Success 200 {object} dto.DataResponse[SalesHeatmap] "Successfully retrieved analytics"
+// @Failure 400 {object} dto.MessageResponse "Invalid query"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/analytics/heatmap [get]
+func (h *Handler) GetSalesHeatmap(c *gin.Context) {
+	period, err := parsePeriod(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid query: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	result, customErr := h.repository.GetSalesHeatmap(userID, period)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*SalesHeatmap]{Data: result})
+}
+
+// @Summary Get category mix
+// @Description Splits the net line revenue of a period by category, with each category's share of the total in percent.
+// @Tags reports
+// @Produce json
+// @Param from query string false "First day as YYYY-MM-DD, 29 days before to when omitted"
+// @Param to query string false "Last day as YYYY-MM-DD, today when omitted"
+// @Success 200 {object} dto.DataResponse[CategoryMix] "Successfully retrieved analytics"
+// @Failure 400 {object} dto.MessageResponse "Invalid query"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/analytics/category-mix [get]
+func (h *Handler) GetCategoryMix(c *gin.Context) {
+	period, err := parsePeriod(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid query: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	result, customErr := h.repository.GetCategoryMix(userID, period)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*CategoryMix]{Data: result})
+}
+
+// @Summary Compare with previous period
+// @Description Compares order count, items sold, net sales, total and average basket of a period with the period of the same length right before it. Changes are in percent and null when the previous figure was zero.
+// @Tags reports
+// @Produce json
+// @Param from query string false "First day as YYYY-MM-DD, 29 days before to when omitted"
+// @Param to query string false "Last day as YYYY-MM-DD, today when omitted"
+// @Success 200 {object} dto.DataResponse[PeriodComparison] "Successfully retrieved analytics"
+// @Failure 400 {object} dto.MessageResponse "Invalid query"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /reports/analytics/comparison [get]
+func (h *Handler) ComparePeriods(c *gin.Context) {
+	period, err := parsePeriod(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid query: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	result, customErr := h.repository.ComparePeriods(userID, period)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*PeriodComparison]{Data: result})
+}
@@ -0,0 +1,19 @@
+package report
+
+import (
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
+
+// Repository defines the data access methods for sales reports
+type Repository interface {
+	GetDaily(userID int, date time.Time) (*DailyReport, *customerror.CustomError)
+	CloseDay(userID int, date time.Time) (*ZReport, *customerror.CustomError)
+	GetZReports(userID int) ([]ZReport, *customerror.CustomError)
+	GetZReportByID(id int, userID int) (*ZReport, *customerror.CustomError)
+	GetTopProducts(userID int, period Period, by ProductRanking, limit int) ([]ProductSales, *customerror.CustomError)
+	GetSalesHeatmap(userID int, period Period) (*SalesHeatmap, *customerror.CustomError)
+	GetCategoryMix(userID int, period Period) (*CategoryMix, *customerror.CustomError)
+	ComparePeriods(userID int, period Period) (*PeriodComparison, *customerror.CustomError)
+}
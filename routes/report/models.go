@@ -0,0 +1,77 @@
+package report
+
+import (
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/routes/order"
+)
+
+// DailyReport summarises the sales of one business day. Only paid,
+// partially refunded and refunded orders count; open, held and voided
+// orders do not. Refunds count on the day they were given.
+// @Description Daily sales summary model
+type DailyReport struct {
+	Date string `json:"date" example:"2026-10-17"`
+	// GrossSales is the value of the lines sold before discounts
+	GrossSales money.Money `json:"gross_sales"`
+	Discounts  money.Money `json:"discounts"`
+	// Refunds is the net line value refunded, without the service charge
+	// and tax given back with it
+	Refunds money.Money `json:"refunds"`
+	// NetSales is gross sales minus discounts and refunds
+	NetSales money.Money `json:"net_sales"`
+	// ServiceCharge and Tax are what was charged less what was refunded
+	ServiceCharge money.Money `json:"service_charge"`
+	Tax           money.Money `json:"tax"`
+	Rounding      money.Money `json:"rounding"`
+	// Total is the sum of the grand totals charged to customers
+	Total      money.Money `json:"total"`
+	OrderCount int         `json:"order_count" example:"42"`
+	// AverageBasket is the average grand total per order
+	AverageBasket  money.Money          `json:"average_basket"`
+	PaymentMethods []PaymentMethodTotal `json:"payment_methods"`
+	Categories     []CategoryTotal      `json:"categories"`
+}
+
+// PaymentMethodTotal is the money taken and refunded with one payment method.
+// Net is what should be in the drawer or settlement for the method.
+type PaymentMethodTotal struct {
+	Method   order.PaymentMethod `json:"method" example:"cash"`
+	Count    int                 `json:"count" example:"30"`
+	Amount   money.Money         `json:"amount"`
+	Refunded money.Money         `json:"refunded"`
+	Net      money.Money         `json:"net"`
+}
+
+// CategoryTotal is the sales of one category. CategoryID is nil for lines
+// sold without a category.
+type CategoryTotal struct {
+	CategoryID *int              `json:"category_id" example:"1"`
+	Category   string            `json:"category" example:"Coffee"`
+	Quantity   quantity.Quantity `json:"quantity" example:"57"`
+	GrossSales money.Money       `json:"gross_sales"`
+	Discounts  money.Money       `json:"discounts"`
+	Refunds    money.Money       `json:"refunds"`
+	NetSales   money.Money       `json:"net_sales"`
+}
+
+// ZReport is a closed business day. Its figures are frozen when the day is
+// closed and never change afterwards, even if later refunds touch its orders.
+// @Description End-of-day Z report model
+type ZReport struct {
+	ID int `json:"id" example:"1"`
+	// Number counts the Z reports of a user, starting at 1
+	Number       int         `json:"number" example:"128"`
+	BusinessDate string      `json:"business_date" example:"2026-10-17"`
+	Report       DailyReport `json:"report"`
+	ClosedBy     int         `json:"closed_by" example:"1"`
+	ClosedAt     time.Time   `json:"closed_at" example:"2026-10-17T22:05:00Z"`
+}
+
+// CloseDay is the request to close a business day
+// @Description Close day request model
+type CloseDay struct {
+	Date string `json:"date" binding:"required,datetime=2006-01-02" example:"2026-10-17"`
+}
@@ -0,0 +1,600 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/quantity"
+	"github.com/yantology/simple-pos/routes/order"
+)
+
+// PostgresRepository implements the Repository interface using PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository instance
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &PostgresRepository{db: db}
+}
+
+const zReportColumns = `id, number, TO_CHAR(business_date, 'YYYY-MM-DD'), report, closed_by, closed_at`
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanZReport reads a Z report selected with zReportColumns
+func scanZReport(row scanner) (*ZReport, error) {
+	var report ZReport
+	var data []byte
+	if err := row.Scan(&report.ID, &report.Number, &report.BusinessDate, &data, &report.ClosedBy, &report.ClosedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &report.Report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// saleStatuses returns the order statuses that count as sales as a query argument
+func saleStatuses() any {
+	statuses := make([]string, len(order.SaleStatuses))
+	for i, status := range order.SaleStatuses {
+		statuses[i] = string(status)
+	}
+	return pq.Array(statuses)
+}
+
+// GetDaily computes the sales summary of a business day from a consistent snapshot
+func (r *PostgresRepository) GetDaily(userID int, date time.Time) (*DailyReport, *customerror.CustomError) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	report, err := dailyReport(tx, userID, date)
+	if err != nil {
+		fmt.Printf("PostgresRepository.GetDaily: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	return report, nil
+}
+
+// dailyReport sums the orders paid, payments taken and refunds given on a day
+func dailyReport(tx *sql.Tx, userID int, date time.Time) (*DailyReport, error) {
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	report := &DailyReport{Date: from.Format("2006-01-02"), PaymentMethods: []PaymentMethodTotal{}, Categories: []CategoryTotal{}}
+
+	err := tx.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(subtotal), 0), COALESCE(SUM(discount_total), 0), COALESCE(SUM(service_charge), 0),
+			COALESCE(SUM(tax_total), 0), COALESCE(SUM(rounding), 0), COALESCE(SUM(total), 0)
+		FROM orders
+		WHERE user_id = $1 AND status = ANY($2) AND paid_at >= $3 AND paid_at < $4
+	`, userID, saleStatuses(), from, to).Scan(&report.OrderCount, &report.GrossSales, &report.Discounts, &report.ServiceCharge,
+		&report.Tax, &report.Rounding, &report.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the net line value of refunds comes off net sales; the service
+	// charge and tax given back come off their own totals
+	var refundedServiceCharge, refundedTax money.Money
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(ri.net_amount), 0), COALESCE(SUM(ri.service_charge), 0), COALESCE(SUM(ri.tax_amount), 0)
+		FROM refund_items ri
+		JOIN refunds r ON r.id = ri.refund_id
+		JOIN orders o ON o.id = r.order_id
+		WHERE o.user_id = $1 AND r.created_at >= $2 AND r.created_at < $3
+	`, userID, from, to).Scan(&report.Refunds, &refundedServiceCharge, &refundedTax)
+	if err != nil {
+		return nil, err
+	}
+
+	report.NetSales = report.GrossSales.Sub(report.Discounts).Sub(report.Refunds)
+	report.ServiceCharge = report.ServiceCharge.Sub(refundedServiceCharge)
+	report.Tax = report.Tax.Sub(refundedTax)
+	if report.OrderCount > 0 {
+		count := int64(report.OrderCount)
+		report.AverageBasket = money.New((report.Total.Amount+count/2)/count, report.Total.Currency)
+	}
+
+	if report.PaymentMethods, err = paymentMethodTotals(tx, userID, from, to); err != nil {
+		return nil, err
+	}
+	if report.Categories, err = categoryTotals(tx, userID, from, to); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// paymentMethodTotals sums the payments taken and refunds given per method
+func paymentMethodTotals(tx *sql.Tx, userID int, from time.Time, to time.Time) ([]PaymentMethodTotal, error) {
+	totals := map[order.PaymentMethod]*PaymentMethodTotal{}
+	total := func(method order.PaymentMethod) *PaymentMethodTotal {
+		if totals[method] == nil {
+			totals[method] = &PaymentMethodTotal{Method: method}
+		}
+		return totals[method]
+	}
+
+	rows, err := tx.Query(`
+		SELECT p.method, COUNT(*), SUM(p.amount)
+		FROM payments p
+		JOIN orders o ON o.id = p.order_id
+		WHERE o.user_id = $1 AND o.status = ANY($2) AND p.created_at >= $3 AND p.created_at < $4
+		GROUP BY p.method
+	`, userID, saleStatuses(), from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var method order.PaymentMethod
+		var count int
+		var amount money.Money
+		if err := rows.Scan(&method, &count, &amount); err != nil {
+			return nil, err
+		}
+		total(method).Count, total(method).Amount = count, amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(`
+		SELECT r.method, SUM(r.amount)
+		FROM refunds r
+		JOIN orders o ON o.id = r.order_id
+		WHERE o.user_id = $1 AND r.created_at >= $2 AND r.created_at < $3
+		GROUP BY r.method
+	`, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var method order.PaymentMethod
+		var refunded money.Money
+		if err := rows.Scan(&method, &refunded); err != nil {
+			return nil, err
+		}
+		total(method).Refunded = refunded
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]PaymentMethodTotal, 0, len(totals))
+	for _, t := range totals {
+		t.Net = t.Amount.Sub(t.Refunded)
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Method < result[j].Method })
+	return result, nil
+}
+
+// categoryTotals sums the lines sold and refunded per category. Lines keep
+// the category name they were sold under.
+func categoryTotals(tx *sql.Tx, userID int, from time.Time, to time.Time) ([]CategoryTotal, error) {
+	type key struct {
+		id   int
+		name string
+	}
+	totals := map[key]*CategoryTotal{}
+	total := func(id *int, name string) *CategoryTotal {
+		k := key{name: name}
+		if id != nil {
+			k.id = *id
+		}
+		if totals[k] == nil {
+			totals[k] = &CategoryTotal{CategoryID: id, Category: name}
+		}
+		return totals[k]
+	}
+
+	rows, err := tx.Query(`
+		SELECT oi.category_id, oi.category, SUM(oi.quantity), SUM(oi.total_price), SUM(oi.discount_amount)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.user_id = $1 AND o.status = ANY($2) AND o.paid_at >= $3 AND o.paid_at < $4
+		GROUP BY oi.category_id, oi.category
+	`, userID, saleStatuses(), from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id sql.NullInt64
+		var name string
+		var sold quantity.Quantity
+		var gross, discounts money.Money
+		if err := rows.Scan(&id, &name, &sold, &gross, &discounts); err != nil {
+			return nil, err
+		}
+		t := total(nullableInt(id), name)
+		t.Quantity, t.GrossSales, t.Discounts = sold, gross, discounts
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(`
+		SELECT oi.category_id, oi.category, SUM(ri.net_amount)
+		FROM refund_items ri
+		JOIN refunds r ON r.id = ri.refund_id
+		JOIN order_items oi ON oi.id = ri.order_item_id
+		JOIN orders o ON o.id = r.order_id
+		WHERE o.user_id = $1 AND r.created_at >= $2 AND r.created_at < $3
+		GROUP BY oi.category_id, oi.category
+	`, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id sql.NullInt64
+		var name string
+		var refunds money.Money
+		if err := rows.Scan(&id, &name, &refunds); err != nil {
+			return nil, err
+		}
+		total(nullableInt(id), name).Refunds = refunds
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]CategoryTotal, 0, len(totals))
+	for _, t := range totals {
+		t.NetSales = t.GrossSales.Sub(t.Discounts).Sub(t.Refunds)
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].NetSales.Amount != result[j].NetSales.Amount {
+			return result[i].NetSales.Amount > result[j].NetSales.Amount
+		}
+		return result[i].Category < result[j].Category
+	})
+	return result, nil
+}
+
+// nullableInt converts a nullable integer column to a pointer
+func nullableInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	id := int(value.Int64)
+	return &id
+}
+
+// CloseDay freezes the summary of a business day into the next numbered Z
+// report. A day can only be closed once.
+func (r *PostgresRepository) CloseDay(userID int, date time.Time) (*ZReport, *customerror.CustomError) {
+	ctx := context.Background()
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer conn.Close()
+
+	// Closes of the same user are numbered one after another. The lock is
+	// taken before the snapshot starts, so a close that waited for it sees
+	// the one that went before.
+	lockKey := fmt.Sprintf("z_report:%d", userID)
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext($1))`, lockKey); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, lockKey)
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	businessDate := date.Format("2006-01-02")
+	var closed bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM z_reports WHERE user_id = $1 AND business_date = $2)`, userID, businessDate).Scan(&closed); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	if closed {
+		return nil, customerror.NewCustomError(nil, fmt.Sprintf("Business day %s is already closed", businessDate), http.StatusConflict)
+	}
+
+	summary, err := dailyReport(tx, userID, date)
+	if err != nil {
+		fmt.Printf("PostgresRepository.CloseDay: Error computing report: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return nil, customerror.NewCustomError(err, "Failed to encode report", http.StatusInternalServerError)
+	}
+
+	query := `
+		INSERT INTO z_reports (user_id, number, business_date, report, closed_by)
+		VALUES ($1, (SELECT COALESCE(MAX(number), 0) + 1 FROM z_reports WHERE user_id = $1), $2, $3, $1)
+		RETURNING ` + zReportColumns
+	report, err := scanZReport(tx.QueryRow(query, userID, businessDate, data))
+	if err != nil {
+		fmt.Printf("PostgresRepository.CloseDay: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return report, nil
+}
+
+// GetZReports retrieves the user's Z reports, latest business day first
+func (r *PostgresRepository) GetZReports(userID int) ([]ZReport, *customerror.CustomError) {
+	query := `SELECT ` + zReportColumns + ` FROM z_reports WHERE user_id = $1 ORDER BY business_date DESC`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	reports := []ZReport{}
+	for rows.Next() {
+		report, err := scanZReport(rows)
+		if err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		reports = append(reports, *report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return reports, nil
+}
+
+// GetZReportByID retrieves one of the user's Z reports
+func (r *PostgresRepository) GetZReportByID(id int, userID int) (*ZReport, *customerror.CustomError) {
+	query := `SELECT ` + zReportColumns + ` FROM z_reports WHERE id = $1 AND user_id = $2`
+	report, err := scanZReport(r.db.QueryRow(query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerror.NewCustomError(err, "Z report not found", http.StatusNotFound)
+		}
+		return nil, customerror.NewPostgresError(err)
+	}
+	return report, nil
+}
+
+// lineRevenue is the value of an order line after discounts and the net
+// value of its refunds, without service charge or tax
+const lineRevenue = `(oi.total_price - oi.discount_amount - COALESCE((SELECT SUM(ri.net_amount) FROM refund_items ri WHERE ri.order_item_id = oi.id), 0))`
+
+// lineQuantity is the quantity of an order line that was not returned
+const lineQuantity = `(oi.quantity - COALESCE((SELECT SUM(ri.quantity) FROM refund_items ri WHERE ri.order_item_id = oi.id), 0))`
+
+// GetTopProducts ranks the products sold in a period by net quantity or revenue
+func (r *PostgresRepository) GetTopProducts(userID int, period Period, by ProductRanking, limit int) ([]ProductSales, *customerror.CustomError) {
+	from, to := period.bounds()
+	orderBy := "quantity DESC, revenue DESC"
+	if by == RankByRevenue {
+		orderBy = "revenue DESC, quantity DESC"
+	}
+
+	// Deleted products keep the name they were sold under
+	query := `
+		SELECT oi.product_id, COALESCE(p.name, MIN(oi.name)), COALESCE(c.name, MIN(oi.category)),
+			SUM(` + lineQuantity + `) AS quantity, SUM(` + lineRevenue + `) AS revenue
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		LEFT JOIN products p ON p.id = oi.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE o.user_id = $1 AND o.status = ANY($2) AND o.paid_at >= $3 AND o.paid_at < $4
+		GROUP BY oi.product_id, p.name, c.name, CASE WHEN oi.product_id IS NULL THEN oi.name END
+		HAVING SUM(` + lineQuantity + `) > 0
+		ORDER BY ` + orderBy + `, 2
+		LIMIT $5`
+	rows, err := r.db.Query(query, userID, saleStatuses(), from, to, limit)
+	if err != nil {
+		fmt.Printf("PostgresRepository.GetTopProducts: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	products := []ProductSales{}
+	for rows.Next() {
+		var product ProductSales
+		var id sql.NullInt64
+		if err := rows.Scan(&id, &product.Name, &product.Category, &product.Quantity, &product.Revenue); err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		product.ProductID = nullableInt(id)
+		product.Rank = len(products) + 1
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return products, nil
+}
+
+// GetSalesHeatmap sums the orders paid in a period by weekday and hour
+func (r *PostgresRepository) GetSalesHeatmap(userID int, period Period) (*SalesHeatmap, *customerror.CustomError) {
+	from, to := period.bounds()
+	heatmap := &SalesHeatmap{
+		From:      period.From.Format("2006-01-02"),
+		To:        period.To.Format("2006-01-02"),
+		Cells:     make([]HeatmapCell, 0, 7*24),
+		ByHour:    make([]HourlySales, 24),
+		ByWeekday: make([]WeekdaySales, 7),
+	}
+	for weekday := 1; weekday <= 7; weekday++ {
+		heatmap.ByWeekday[weekday-1].Weekday = weekday
+		for hour := 0; hour < 24; hour++ {
+			heatmap.Cells = append(heatmap.Cells, HeatmapCell{Weekday: weekday, Hour: hour, Sales: money.FromMinor(0)})
+		}
+	}
+	for hour := 0; hour < 24; hour++ {
+		heatmap.ByHour[hour] = HourlySales{Hour: hour, Sales: money.FromMinor(0)}
+	}
+	for i := range heatmap.ByWeekday {
+		heatmap.ByWeekday[i].Sales = money.FromMinor(0)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT EXTRACT(ISODOW FROM paid_at)::int, EXTRACT(HOUR FROM paid_at)::int, COUNT(*), SUM(total)
+		FROM orders
+		WHERE user_id = $1 AND status = ANY($2) AND paid_at >= $3 AND paid_at < $4
+		GROUP BY 1, 2
+	`, userID, saleStatuses(), from, to)
+	if err != nil {
+		fmt.Printf("PostgresRepository.GetSalesHeatmap: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var weekday, hour, count int
+		var sales money.Money
+		if err := rows.Scan(&weekday, &hour, &count, &sales); err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		cell := &heatmap.Cells[(weekday-1)*24+hour]
+		cell.OrderCount, cell.Sales = count, sales
+		heatmap.ByHour[hour].OrderCount += count
+		heatmap.ByHour[hour].Sales = heatmap.ByHour[hour].Sales.Add(sales)
+		heatmap.ByWeekday[weekday-1].OrderCount += count
+		heatmap.ByWeekday[weekday-1].Sales = heatmap.ByWeekday[weekday-1].Sales.Add(sales)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return heatmap, nil
+}
+
+// GetCategoryMix splits the net line revenue of a period by category. Lines
+// are grouped under the category's current name.
+func (r *PostgresRepository) GetCategoryMix(userID int, period Period) (*CategoryMix, *customerror.CustomError) {
+	from, to := period.bounds()
+	rows, err := r.db.Query(`
+		SELECT oi.category_id, COALESCE(c.name, MIN(oi.category)), SUM(`+lineQuantity+`), SUM(`+lineRevenue+`) AS revenue
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		LEFT JOIN categories c ON c.id = oi.category_id AND c.user_id = o.user_id
+		WHERE o.user_id = $1 AND o.status = ANY($2) AND o.paid_at >= $3 AND o.paid_at < $4
+		GROUP BY oi.category_id, c.name, CASE WHEN c.name IS NULL THEN oi.category END
+		ORDER BY revenue DESC, 2
+	`, userID, saleStatuses(), from, to)
+	if err != nil {
+		fmt.Printf("PostgresRepository.GetCategoryMix: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer rows.Close()
+
+	mix := &CategoryMix{
+		From:       period.From.Format("2006-01-02"),
+		To:         period.To.Format("2006-01-02"),
+		Revenue:    money.FromMinor(0),
+		Categories: []CategoryShare{},
+	}
+	for rows.Next() {
+		var category CategoryShare
+		var id sql.NullInt64
+		if err := rows.Scan(&id, &category.Category, &category.Quantity, &category.Revenue); err != nil {
+			return nil, customerror.NewPostgresError(err)
+		}
+		category.CategoryID = nullableInt(id)
+		mix.Revenue = mix.Revenue.Add(category.Revenue)
+		mix.Categories = append(mix.Categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	if mix.Revenue.IsPositive() {
+		for i := range mix.Categories {
+			mix.Categories[i].Share = roundPercent(float64(mix.Categories[i].Revenue.Amount) / float64(mix.Revenue.Amount) * 100)
+		}
+	}
+	return mix, nil
+}
+
+// ComparePeriods compares a period with the period of the same length right
+// before it, from a consistent snapshot
+func (r *PostgresRepository) ComparePeriods(userID int, period Period) (*PeriodComparison, *customerror.CustomError) {
+	tx, err := r.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	defer tx.Rollback()
+
+	current, err := periodSummary(tx, userID, period)
+	if err != nil {
+		fmt.Printf("PostgresRepository.ComparePeriods: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+	previous, err := periodSummary(tx, userID, period.Previous())
+	if err != nil {
+		fmt.Printf("PostgresRepository.ComparePeriods: Database error: %v\n", err) // Add log
+		return nil, customerror.NewPostgresError(err)
+	}
+
+	return &PeriodComparison{
+		Current:  *current,
+		Previous: *previous,
+		Change: PeriodChange{
+			OrderCount:    percentChange(int64(current.OrderCount), int64(previous.OrderCount)),
+			ItemsSold:     percentChange(int64(current.ItemsSold), int64(previous.ItemsSold)),
+			NetSales:      percentChange(current.NetSales.Amount, previous.NetSales.Amount),
+			Total:         percentChange(current.Total.Amount, previous.Total.Amount),
+			AverageBasket: percentChange(current.AverageBasket.Amount, previous.AverageBasket.Amount),
+		},
+	}, nil
+}
+
+// periodSummary computes the headline figures of a period
+func periodSummary(tx *sql.Tx, userID int, period Period) (*PeriodSummary, error) {
+	from, to := period.bounds()
+	summary := &PeriodSummary{From: period.From.Format("2006-01-02"), To: period.To.Format("2006-01-02")}
+
+	var sales, refunds money.Money
+	err := tx.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(subtotal - discount_total), 0), COALESCE(SUM(total), 0),
+			COALESCE((SELECT SUM(`+lineQuantity+`) FROM order_items oi
+				JOIN orders s ON s.id = oi.order_id
+				WHERE s.user_id = $1 AND s.status = ANY($2) AND s.paid_at >= $3 AND s.paid_at < $4), 0)
+		FROM orders
+		WHERE user_id = $1 AND status = ANY($2) AND paid_at >= $3 AND paid_at < $4
+	`, userID, saleStatuses(), from, to).Scan(&summary.OrderCount, &sales, &summary.Total, &summary.ItemsSold)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(ri.net_amount), 0)
+		FROM refund_items ri
+		JOIN refunds r ON r.id = ri.refund_id
+		JOIN orders o ON o.id = r.order_id
+		WHERE o.user_id = $1 AND r.created_at >= $2 AND r.created_at < $3
+	`, userID, from, to).Scan(&refunds)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.NetSales = sales.Sub(refunds)
+	summary.AverageBasket = money.New(0, summary.Total.Currency)
+	if summary.OrderCount > 0 {
+		count := int64(summary.OrderCount)
+		summary.AverageBasket = money.New((summary.Total.Amount+count/2)/count, summary.Total.Currency)
+	}
+	return summary, nil
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/customerror"
+)
+
+// repository implements the Repository interface
+type repository struct {
+	database Repository
+}
+
+// NewRepository creates a new repository instance
+func NewRepository(db Repository) Repository {
+	return &repository{
+		database: db,
+	}
+}
+
+// GetDaily calls the database GetDaily method
+func (r *repository) GetDaily(userID int, date time.Time) (*DailyReport, *customerror.CustomError) {
+	return r.database.GetDaily(userID, date)
+}
+
+// CloseDay calls the database CloseDay method
+func (r *repository) CloseDay(userID int, date time.Time) (*ZReport, *customerror.CustomError) {
+	return r.database.CloseDay(userID, date)
+}
+
+// GetZReports calls the database GetZReports method
+func (r *repository) GetZReports(userID int) ([]ZReport, *customerror.CustomError) {
+	return r.database.GetZReports(userID)
+}
+
+// GetZReportByID calls the database GetZReportByID method
+func (r *repository) GetZReportByID(id int, userID int) (*ZReport, *customerror.CustomError) {
+	return r.database.GetZReportByID(id, userID)
+}
+
+// GetTopProducts calls the database GetTopProducts method
+func (r *repository) GetTopProducts(userID int, period Period, by ProductRanking, limit int) ([]ProductSales, *customerror.CustomError) {
+	return r.database.GetTopProducts(userID, period, by, limit)
+}
+
+// GetSalesHeatmap calls the database GetSalesHeatmap method
+func (r *repository) GetSalesHeatmap(userID int, period Period) (*SalesHeatmap, *customerror.CustomError) {
+	return r.database.GetSalesHeatmap(userID, period)
+}
+
+// GetCategoryMix calls the database GetCategoryMix method
+func (r *repository) GetCategoryMix(userID int, period Period) (*CategoryMix, *customerror.CustomError) {
+	return r.database.GetCategoryMix(userID, period)
+}
+
+// ComparePeriods calls the database ComparePeriods method
+func (r *repository) ComparePeriods(userID int, period Period) (*PeriodComparison, *customerror.CustomError) {
+	return r.database.ComparePeriods(userID, period)
+}
@@ -0,0 +1,112 @@
+package setting
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yantology/simple-pos/pkg/dto"
+	"github.com/yantology/simple-pos/pkg/ordernumber"
+)
+
+// Handler holds the dependencies for the settings handlers
+type Handler struct {
+	repository Repository
+}
+
+// NewHandler creates a new Handler instance
+func NewHandler(repository Repository) *Handler {
+	return &Handler{
+		repository: repository,
+	}
+}
+
+// RegisterRoutes sets up all the routes for store settings
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", h.GetSettings)
+	router.PUT("", h.UpdateSettings)
+}
+
+// @Summary Get store settings
+// @Description Retrieves the tax, service charge, rounding and receipt settings of the authenticated user. Defaults are returned when nothing was saved yet.
+// @Tags settings
+// @Produce json
+// @Success 200 {object} dto.DataResponse[Settings] "Successfully retrieved settings"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /settings [get]
+func (h *Handler) GetSettings(c *gin.Context) {
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	settings, customErr := h.repository.Get(userID)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*Settings]{Data: settings})
+}
+
+// @Summary Update store settings
+// @Description Sets the PPN rate, tax-inclusive or tax-exclusive pricing, service charge percentage and grand total rounding used for new orders, plus the store details printed on receipts and the outlet code and format of order numbers such as {outlet}-{date}-{seq:4}, which must contain the outlet, the day and the sequence, whether tracked products may be sold beyond their stock on hand, and the EAN-13 prefixes under which the store's scales print weights or prices. Existing orders keep the tax settings they were priced with.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param settings body UpdateSettings true "Store settings"
+// @Success 200 {object} dto.DataResponse[Settings] "Settings updated successfully"
+// @Failure 400 {object} dto.MessageResponse "Invalid request data"
+// @Failure 401 {object} dto.MessageResponse "Unauthorized: User ID not found in context"
+// @Failure 500 {object} dto.MessageResponse "Internal Server Error"
+// @Router /settings [put]
+func (h *Handler) UpdateSettings(c *gin.Context) {
+	var request UpdateSettings
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid request data: " + err.Error()})
+		return
+	}
+	if err := request.Tax().Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid settings: " + err.Error()})
+		return
+	}
+	_, format := request.OrderNumbering()
+	if err := ordernumber.Validate(format); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid settings: " + err.Error()})
+		return
+	}
+	if err := request.ScaleLayout().Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, dto.MessageResponse{Message: "Invalid settings: " + err.Error()})
+		return
+	}
+
+	// Get userID from middleware context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.MessageResponse{Message: "Unauthorized: User ID not found in context"})
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDVal.(string)) // Assert userID as int
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.MessageResponse{Message: "Internal Server Error: User ID in context is not an integer"})
+		return
+	}
+
+	settings, customErr := h.repository.Update(userID, &request)
+	if customErr != nil {
+		c.JSON(customErr.Code(), dto.MessageResponse{Message: customErr.Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.DataResponse[*Settings]{Data: settings})
+}
@@ -0,0 +1,9 @@
+package setting
+
+import "github.com/yantology/simple-pos/pkg/customerror"
+
+// Repository defines the data access methods for store settings
+type Repository interface {
+	Get(userID int) (*Settings, *customerror.CustomError)
+	Update(userID int, settings *UpdateSettings) (*Settings, *customerror.CustomError)
+}
@@ -0,0 +1,105 @@
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yantology/simple-pos/pkg/barcode"
+	"github.com/yantology/simple-pos/pkg/money"
+	"github.com/yantology/simple-pos/pkg/ordernumber"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+// Settings holds the store configuration of a user
+// @Description Store settings model
+type Settings struct {
+	// TaxRate is the PPN rate in percent
+	TaxRate float64 `json:"tax_rate" example:"11"`
+	// TaxInclusive means product prices already include tax
+	TaxInclusive      bool             `json:"tax_inclusive" example:"false"`
+	ServiceChargeRate float64          `json:"service_charge_rate" example:"5"`
+	RoundingMode      tax.RoundingMode `json:"rounding_mode" example:"nearest"`
+	RoundingUnit      money.Money      `json:"rounding_unit"`
+	// StoreName, StoreAddress, StorePhone and TaxID (NPWP) head every
+	// receipt; ReceiptFooter is printed at the bottom
+	StoreName     string `json:"store_name" example:"Kopi Kita Sudirman"`
+	StoreAddress  string `json:"store_address" example:"Jl. Jend. Sudirman No. 1, Jakarta"`
+	StorePhone    string `json:"store_phone" example:"021-5550123"`
+	TaxID         string `json:"tax_id" example:"01.234.567.8-901.000"`
+	ReceiptFooter string `json:"receipt_footer" example:"Terima kasih atas kunjungan Anda"`
+	// OutletCode and OrderNumberFormat build order numbers; see pkg/ordernumber
+	OutletCode        string `json:"outlet_code" example:"OUT1"`
+	OrderNumberFormat string `json:"order_number_format" example:"{outlet}-{date}-{seq:4}"`
+	// AllowNegativeStock lets tracked products be sold beyond their stock on hand
+	AllowNegativeStock bool `json:"allow_negative_stock" example:"false"`
+	// ScaleWeightPrefixes and ScalePricePrefixes are the EAN-13 prefixes, 20
+	// to 29, under which the store's scales print the weight or the price
+	ScaleWeightPrefixes []string   `json:"scale_weight_prefixes" example:"20,21,22,23,24"`
+	ScalePricePrefixes  []string   `json:"scale_price_prefixes" example:"25,26,27,28,29"`
+	UserID              int        `json:"user_id" example:"1"`
+	CreatedAt           *time.Time `json:"created_at,omitempty" example:"2025-04-25T15:04:05Z07:00"`
+	UpdatedAt           *time.Time `json:"updated_at,omitempty" example:"2025-04-25T15:04:05Z07:00"`
+}
+
+// UpdateSettings defines the structure for updating store settings
+// @Description Update store settings request model
+type UpdateSettings struct {
+	TaxRate            float64          `json:"tax_rate" binding:"min=0,max=100" example:"11"`
+	TaxInclusive       bool             `json:"tax_inclusive" example:"false"`
+	ServiceChargeRate  float64          `json:"service_charge_rate" binding:"min=0,max=100" example:"5"`
+	RoundingMode       tax.RoundingMode `json:"rounding_mode" binding:"omitempty,oneof=none nearest up down" example:"nearest"`
+	RoundingUnit       money.Money      `json:"rounding_unit" binding:"money,min=0"`
+	StoreName          string           `json:"store_name" binding:"max=255" example:"Kopi Kita Sudirman"`
+	StoreAddress       string           `json:"store_address" example:"Jl. Jend. Sudirman No. 1, Jakarta"`
+	StorePhone         string           `json:"store_phone" binding:"max=32" example:"021-5550123"`
+	TaxID              string           `json:"tax_id" binding:"max=32" example:"01.234.567.8-901.000"`
+	ReceiptFooter      string           `json:"receipt_footer" example:"Terima kasih atas kunjungan Anda"`
+	OutletCode         string           `json:"outlet_code" binding:"omitempty,max=16,printascii" example:"OUT1"`
+	OrderNumberFormat  string           `json:"order_number_format" binding:"max=48" example:"{outlet}-{date}-{seq:4}"`
+	AllowNegativeStock bool             `json:"allow_negative_stock" example:"false"`
+	// Omitted scale prefixes fall back to 20-24 for weight and 25-29 for
+	// price; an empty list turns that kind of scale barcode off
+	ScaleWeightPrefixes []string `json:"scale_weight_prefixes" binding:"omitempty,max=10" example:"20,21,22,23,24"`
+	ScalePricePrefixes  []string `json:"scale_price_prefixes" binding:"omitempty,max=10" example:"25,26,27,28,29"`
+}
+
+// OrderNumbering returns the outlet code and order number format, falling
+// back to the defaults when they are left empty
+func (s *UpdateSettings) OrderNumbering() (outlet string, format string) {
+	outlet, format = strings.TrimSpace(s.OutletCode), strings.TrimSpace(s.OrderNumberFormat)
+	if outlet == "" {
+		outlet = ordernumber.DefaultOutlet
+	}
+	if format == "" {
+		format = ordernumber.DefaultFormat
+	}
+	return outlet, format
+}
+
+// ScaleLayout returns the scale prefixes, falling back to the default of
+// each kind when it is omitted
+func (s *UpdateSettings) ScaleLayout() barcode.ScaleLayout {
+	layout := barcode.DefaultScaleLayout
+	if s.ScaleWeightPrefixes != nil {
+		layout.WeightPrefixes = s.ScaleWeightPrefixes
+	}
+	if s.ScalePricePrefixes != nil {
+		layout.PricePrefixes = s.ScalePricePrefixes
+	}
+	return layout
+}
+
+// Tax returns the tax settings used to price orders
+func (s *UpdateSettings) Tax() tax.Settings {
+	mode := s.RoundingMode
+	if mode == "" {
+		mode = tax.RoundNone
+	}
+	return tax.Settings{
+		Rate:              s.TaxRate,
+		Inclusive:         s.TaxInclusive,
+		ServiceChargeRate: s.ServiceChargeRate,
+		RoundingMode:      mode,
+		RoundingUnit:      s.RoundingUnit.Amount,
+	}
+}
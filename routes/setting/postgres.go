@@ -0,0 +1,123 @@
+package setting
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/yantology/simple-pos/pkg/barcode"
+	"github.com/yantology/simple-pos/pkg/customerror"
+	"github.com/yantology/simple-pos/pkg/ordernumber"
+	"github.com/yantology/simple-pos/pkg/tax"
+)
+
+// PostgresRepository implements the Repository interface using PostgreSQL
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRepository creates a new PostgresRepository instance
+func NewPostgresRepository(db *sql.DB) Repository {
+	return &PostgresRepository{db: db}
+}
+
+const settingsColumns = `tax_rate, tax_inclusive, service_charge_rate, rounding_mode, rounding_unit,
+	store_name, store_address, store_phone, tax_id, receipt_footer, outlet_code, order_number_format,
+	allow_negative_stock, scale_weight_prefixes, scale_price_prefixes, user_id, created_at, updated_at`
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSettings reads settings selected with settingsColumns
+func scanSettings(row scanner) (*Settings, error) {
+	var settings Settings
+	var createdAt, updatedAt sql.NullTime
+	var weightPrefixes, pricePrefixes pq.StringArray
+	err := row.Scan(
+		&settings.TaxRate,
+		&settings.TaxInclusive,
+		&settings.ServiceChargeRate,
+		&settings.RoundingMode,
+		&settings.RoundingUnit,
+		&settings.StoreName,
+		&settings.StoreAddress,
+		&settings.StorePhone,
+		&settings.TaxID,
+		&settings.ReceiptFooter,
+		&settings.OutletCode,
+		&settings.OrderNumberFormat,
+		&settings.AllowNegativeStock,
+		&weightPrefixes,
+		&pricePrefixes,
+		&settings.UserID,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	settings.ScaleWeightPrefixes, settings.ScalePricePrefixes = weightPrefixes, pricePrefixes
+	if createdAt.Valid {
+		settings.CreatedAt = &createdAt.Time
+	}
+	if updatedAt.Valid {
+		settings.UpdatedAt = &updatedAt.Time
+	}
+	return &settings, nil
+}
+
+// Get retrieves the user's settings. Users who never saved settings get the
+// defaults: no tax, no service charge, no rounding, the default order
+// numbering, no sales beyond the stock on hand and the default scale
+// prefixes.
+func (r *PostgresRepository) Get(userID int) (*Settings, *customerror.CustomError) {
+	query := `SELECT ` + settingsColumns + ` FROM store_settings WHERE user_id = $1`
+	settings, err := scanSettings(r.db.QueryRow(query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &Settings{
+				RoundingMode:        tax.RoundNone,
+				OutletCode:          ordernumber.DefaultOutlet,
+				OrderNumberFormat:   ordernumber.DefaultFormat,
+				ScaleWeightPrefixes: barcode.DefaultScaleLayout.WeightPrefixes,
+				ScalePricePrefixes:  barcode.DefaultScaleLayout.PricePrefixes,
+				UserID:              userID,
+			}, nil
+		}
+		return nil, customerror.NewPostgresError(err)
+	}
+	return settings, nil
+}
+
+// Update creates or replaces the user's settings
+func (r *PostgresRepository) Update(userID int, data *UpdateSettings) (*Settings, *customerror.CustomError) {
+	rule := data.Tax()
+	outlet, format := data.OrderNumbering()
+	scales := data.ScaleLayout()
+	query := `
+		INSERT INTO store_settings (
+			user_id, tax_rate, tax_inclusive, service_charge_rate, rounding_mode, rounding_unit,
+			store_name, store_address, store_phone, tax_id, receipt_footer, outlet_code, order_number_format,
+			allow_negative_stock, scale_weight_prefixes, scale_price_prefixes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (user_id) DO UPDATE
+		SET tax_rate = EXCLUDED.tax_rate, tax_inclusive = EXCLUDED.tax_inclusive,
+			service_charge_rate = EXCLUDED.service_charge_rate, rounding_mode = EXCLUDED.rounding_mode,
+			rounding_unit = EXCLUDED.rounding_unit, store_name = EXCLUDED.store_name,
+			store_address = EXCLUDED.store_address, store_phone = EXCLUDED.store_phone,
+			tax_id = EXCLUDED.tax_id, receipt_footer = EXCLUDED.receipt_footer,
+			outlet_code = EXCLUDED.outlet_code, order_number_format = EXCLUDED.order_number_format,
+			allow_negative_stock = EXCLUDED.allow_negative_stock,
+			scale_weight_prefixes = EXCLUDED.scale_weight_prefixes, scale_price_prefixes = EXCLUDED.scale_price_prefixes
+		RETURNING ` + settingsColumns
+
+	settings, err := scanSettings(r.db.QueryRow(query, userID, rule.Rate, rule.Inclusive, rule.ServiceChargeRate, rule.RoundingMode, rule.RoundingUnit,
+		data.StoreName, data.StoreAddress, data.StorePhone, data.TaxID, data.ReceiptFooter, outlet, format,
+		data.AllowNegativeStock, pq.Array(scales.WeightPrefixes), pq.Array(scales.PricePrefixes)))
+	if err != nil {
+		return nil, customerror.NewPostgresError(err)
+	}
+	return settings, nil
+}
@@ -0,0 +1,25 @@
+package setting
+
+import "github.com/yantology/simple-pos/pkg/customerror"
+
+// repository implements the Repository interface
+type repository struct {
+	database Repository
+}
+
+// NewRepository creates a new repository instance
+func NewRepository(db Repository) Repository {
+	return &repository{
+		database: db,
+	}
+}
+
+// Get calls the database Get method
+func (r *repository) Get(userID int) (*Settings, *customerror.CustomError) {
+	return r.database.Get(userID)
+}
+
+// Update calls the database Update method
+func (r *repository) Update(userID int, settings *UpdateSettings) (*Settings, *customerror.CustomError) {
+	return r.database.Update(userID, settings)
+}